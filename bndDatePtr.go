@@ -32,7 +32,7 @@ func (bnd *bndDatePtr) bind(value *Date, position namedPos, stmt *Stmt) error {
 	if value != nil {
 		bnd.ocidate[0] = value.Date
 	}
-	//bnd.stmt.logF(_drv.Cfg().Log.Stmt.Bind, "bind val=%#v null?=%t datep=%#v (%v)\n", bnd.value, bnd.nullp.IsNull(), bnd.datep, bnd.datep.Get())
+	//bnd.stmt.logF(bnd.stmt.logCfg().Stmt.Bind, "bind val=%#v null?=%t datep=%#v (%v)\n", bnd.value, bnd.nullp.IsNull(), bnd.datep, bnd.datep.Get())
 	ph, phLen, phFree := position.CString()
 	if ph != nil {
 		defer phFree()
@@ -60,7 +60,7 @@ func (bnd *bndDatePtr) bind(value *Date, position namedPos, stmt *Stmt) error {
 }
 
 func (bnd *bndDatePtr) setPtr() (err error) {
-	bnd.stmt.logF(_drv.Cfg().Log.Stmt.Bind, "setPtr val=%#v nullp=%#v datep=%#v (%v)\n", bnd.value, bnd.nullp, bnd.ocidate[0], bnd.ocidate[0].Get())
+	bnd.stmt.logF(bnd.stmt.logCfg().Stmt.Bind, "setPtr val=%#v nullp=%#v datep=%#v (%v)\n", bnd.value, bnd.nullp, bnd.ocidate[0], bnd.ocidate[0].Get())
 
 	if bnd.value == nil {
 		return nil
@@ -99,7 +99,7 @@ func (bnd *bndFloat64Slice) bind(values *[]float64, position namedPos, stmt *Stm
 			bnd.nullInds[i] = 0
 		}
 	}
-	bnd.stmt.logF(_drv.Cfg().Log.Stmt.Bind,
+	bnd.stmt.logF(bnd.stmt.logCfg().Stmt.Bind,
 		"%p pos=%v cap=%d len=%d curlen=%d curlenp=%p", bnd, position, cap(bnd.ociNumbers), len(bnd.ociNumbers), bnd.curlen, curlenp)
 	ph, phLen, phFree := position.CString()
 	if ph != nil {
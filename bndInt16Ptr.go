@@ -22,7 +22,7 @@ type bndInt16Ptr struct {
 }
 
 func (bnd *bndInt16Ptr) bind(value *int16, valueIsNull *bool, position namedPos, stmt *Stmt) error {
-	//bnd.stmt.logF(_drv.Cfg().Log.Stmt.Bind, "Int16Ptr.bind(%d) value=%#v => number=%#v", position, value, bnd.ociNumber[0])
+	//bnd.stmt.logF(bnd.stmt.logCfg().Stmt.Bind, "Int16Ptr.bind(%d) value=%#v => number=%#v", position, value, bnd.ociNumber[0])
 	bnd.stmt = stmt
 	bnd.value = value
 	bnd.valueIsNull = valueIsNull
@@ -31,7 +31,7 @@ func (bnd *bndInt16Ptr) bind(value *int16, valueIsNull *bool, position namedPos,
 		if err := bnd.stmt.ses.srv.env.OCINumberFromInt(&bnd.ociNumber[0], int64(*value), byteWidth16); err != nil {
 			return err
 		}
-		bnd.stmt.logF(_drv.Cfg().Log.Stmt.Bind,
+		bnd.stmt.logF(bnd.stmt.logCfg().Stmt.Bind,
 			"Int16Ptr.bind(%v) value=%#v => number=%#v", position, value, bnd.ociNumber[0])
 	}
 	ph, phLen, phFree := position.CString()
@@ -22,7 +22,7 @@ type bndInt64Ptr struct {
 }
 
 func (bnd *bndInt64Ptr) bind(value *int64, valueIsNull *bool, position namedPos, stmt *Stmt) error {
-	//bnd.stmt.logF(_drv.Cfg().Log.Stmt.Bind, "Int64Ptr.bind(%d) value=%#v => number=%#v", position, value, bnd.ociNumber[0])
+	//bnd.stmt.logF(bnd.stmt.logCfg().Stmt.Bind, "Int64Ptr.bind(%d) value=%#v => number=%#v", position, value, bnd.ociNumber[0])
 	bnd.stmt = stmt
 	bnd.value = value
 	bnd.valueIsNull = valueIsNull
@@ -31,7 +31,7 @@ func (bnd *bndInt64Ptr) bind(value *int64, valueIsNull *bool, position namedPos,
 		if err := bnd.stmt.ses.srv.env.OCINumberFromInt(&bnd.ociNumber[0], intSixtyFour(*value), byteWidth64); err != nil {
 			return err
 		}
-		bnd.stmt.logF(_drv.Cfg().Log.Stmt.Bind,
+		bnd.stmt.logF(bnd.stmt.logCfg().Stmt.Bind,
 			"Int64Ptr.bind(%v) value=%#v => number=%#v", position, value, bnd.ociNumber[0])
 	}
 	ph, phLen, phFree := position.CString()
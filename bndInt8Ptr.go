@@ -22,7 +22,7 @@ type bndInt8Ptr struct {
 }
 
 func (bnd *bndInt8Ptr) bind(value *int8, valueIsNull *bool, position namedPos, stmt *Stmt) error {
-	//bnd.stmt.logF(_drv.Cfg().Log.Stmt.Bind, "Int8Ptr.bind(%d) value=%#v => number=%#v", position, value, bnd.ociNumber[0])
+	//bnd.stmt.logF(bnd.stmt.logCfg().Stmt.Bind, "Int8Ptr.bind(%d) value=%#v => number=%#v", position, value, bnd.ociNumber[0])
 	bnd.stmt = stmt
 	bnd.value = value
 	bnd.valueIsNull = valueIsNull
@@ -31,7 +31,7 @@ func (bnd *bndInt8Ptr) bind(value *int8, valueIsNull *bool, position namedPos, s
 		if err := bnd.stmt.ses.srv.env.OCINumberFromInt(&bnd.ociNumber[0], int64(*value), byteWidth8); err != nil {
 			return err
 		}
-		bnd.stmt.logF(_drv.Cfg().Log.Stmt.Bind,
+		bnd.stmt.logF(bnd.stmt.logCfg().Stmt.Bind,
 			"Int8Ptr.bind(%v) value=%#v => number=%#v", position, value, bnd.ociNumber[0])
 	}
 	ph, phLen, phFree := position.CString()
@@ -20,6 +20,8 @@ type bndLob struct {
 	ocibnd *C.OCIBind
 	sqlt   C.ub2
 	lobLocatorp
+	// external is true when the locator is owned by a LobReadWriter.
+	external bool
 }
 
 // bindReader binds an io.Reader: reads from rdr, and writes to a temprary LOB,
@@ -60,6 +62,25 @@ func (bnd *bndLob) bindReader(rdr io.Reader, position namedPos, lobBufferSize in
 	return nil
 }
 
+// bindLocator binds the LOB locator of the LobReadWriter, as is.
+// The locator stays owned by the LobReadWriter.
+func (bnd *bndLob) bindLocator(lrw *LobReadWriter, position namedPos, stmt *Stmt) error {
+	bnd.stmt = stmt
+	bnd.sqlt = C.SQLT_BLOB
+	if lrw.isClob {
+		bnd.sqlt = C.SQLT_CLOB
+	}
+	lrw.Lock()
+	lob := lrw.ociLobLocator
+	lrw.Unlock()
+	if lob == nil {
+		return er("LobReadWriter is closed.")
+	}
+	bnd.external = true
+	*(bnd.lobLocatorp.Pointer()) = lob
+	return bnd.bindByPos(position)
+}
+
 func (bnd *bndLob) setPtr() error {
 	return nil
 }
@@ -78,15 +99,21 @@ func (bnd *bndLob) close() (err error) {
 	}()
 
 	// no need to clear bnd.buf
-	// free temporary lob
-	C.OCILobFreeTemporary(
-		bnd.stmt.ses.ocisvcctx,      //OCISvcCtx          *svchp,
-		bnd.stmt.ses.srv.env.ocierr, //OCIError           *errhp,
-		bnd.lobLocatorp.Value())     //OCILobLocator      *locp,
-	// free lob locator handle
-	C.OCIDescriptorFree(
-		unsafe.Pointer(bnd.lobLocatorp.Pointer()), //void     *descp,
-		C.OCI_DTYPE_LOB)                           //ub4      type );
+	if bnd.external {
+		// the LobReadWriter owns the locator
+		bnd.external = false
+		*(bnd.lobLocatorp.Pointer()) = nil
+	} else {
+		// free temporary lob
+		C.OCILobFreeTemporary(
+			bnd.stmt.ses.ocisvcctx,      //OCISvcCtx          *svchp,
+			bnd.stmt.ses.srv.env.ocierr, //OCIError           *errhp,
+			bnd.lobLocatorp.Value())     //OCILobLocator      *locp,
+		// free lob locator handle
+		C.OCIDescriptorFree(
+			unsafe.Pointer(bnd.lobLocatorp.Pointer()), //void     *descp,
+			C.OCI_DTYPE_LOB)                           //ub4      type );
+	}
 	stmt := bnd.stmt
 	bnd.stmt = nil
 	bnd.ocibnd = nil
@@ -226,11 +253,17 @@ func writeLob(ociLobLocator *C.OCILobLocator, stmt *Stmt, r io.Reader, lobBuffer
 }
 
 func allocTempLob(stmt *Stmt) (ociLobLocator *C.OCILobLocator, finish func(), err error) {
+	return createTempLob(stmt.ses, C.OCI_TEMP_BLOB)
+}
+
+// createTempLob creates a temporary LOB of lobType (OCI_TEMP_BLOB or OCI_TEMP_CLOB)
+// for the duration of the session.
+func createTempLob(ses *Ses, lobType C.ub1) (ociLobLocator *C.OCILobLocator, finish func(), err error) {
 	locatorp := (**C.OCILobLocator)(C.malloc(C.sof_LobLocatorp))
 	defer C.free(unsafe.Pointer(locatorp))
 	// Allocate lob locator handle
 	r := C.OCIDescriptorAlloc(
-		unsafe.Pointer(stmt.ses.srv.env.ocienv),     //CONST dvoid   *parenth,
+		unsafe.Pointer(ses.srv.env.ocienv),          //CONST dvoid   *parenth,
 		(*unsafe.Pointer)(unsafe.Pointer(locatorp)), //dvoid         **descpp,
 		C.OCI_DTYPE_LOB,                             //ub4           type,
 		0,                                           //size_t        xtramem_sz,
@@ -239,34 +272,34 @@ func allocTempLob(stmt *Stmt) (ociLobLocator *C.OCILobLocator, finish func(), er
 		ociLobLocator = *locatorp
 	}
 	if r == C.OCI_ERROR {
-		return nil, nil, stmt.ses.srv.env.ociError()
+		return nil, nil, ses.srv.env.ociError()
 	} else if r == C.OCI_INVALID_HANDLE {
 		return nil, nil, errNew("unable to allocate oci lob handle during bind")
 	}
 
 	// Create temporary lob
 	r = C.OCILobCreateTemporary(
-		stmt.ses.ocisvcctx,      //OCISvcCtx          *svchp,
-		stmt.ses.srv.env.ocierr, //OCIError           *errhp,
-		ociLobLocator,           //OCILobLocator      *locp,
-		C.OCI_DEFAULT,           //ub2                csid,
-		C.SQLCS_IMPLICIT,        //ub1                csfrm,
-		C.OCI_TEMP_BLOB,         //ub1                lobtype,
-		C.TRUE,                  //boolean            cache,
-		C.OCI_DURATION_SESSION)  //OCIDuration        duration);
+		ses.ocisvcctx,          //OCISvcCtx          *svchp,
+		ses.srv.env.ocierr,     //OCIError           *errhp,
+		ociLobLocator,          //OCILobLocator      *locp,
+		C.OCI_DEFAULT,          //ub2                csid,
+		C.SQLCS_IMPLICIT,       //ub1                csfrm,
+		lobType,                //ub1                lobtype,
+		C.TRUE,                 //boolean            cache,
+		C.OCI_DURATION_SESSION) //OCIDuration        duration);
 	if r == C.OCI_ERROR {
 		// free lob locator handle
 		C.OCIDescriptorFree(
 			unsafe.Pointer(ociLobLocator), //void     *descp,
 			C.OCI_DTYPE_LOB)               //ub4      type );
-		return nil, nil, stmt.ses.srv.env.ociError()
+		return nil, nil, ses.srv.env.ociError()
 	}
 
 	return ociLobLocator, func() {
 		C.OCILobFreeTemporary(
-			stmt.ses.ocisvcctx,      //OCISvcCtx          *svchp,
-			stmt.ses.srv.env.ocierr, //OCIError           *errhp,
-			ociLobLocator)           //OCILobLocator      *locp,
+			ses.ocisvcctx,      //OCISvcCtx          *svchp,
+			ses.srv.env.ocierr, //OCIError           *errhp,
+			ociLobLocator)      //OCILobLocator      *locp,
 		// free lob locator handle
 		C.OCIDescriptorFree(
 			unsafe.Pointer(ociLobLocator), //void     *descp,
@@ -62,7 +62,8 @@ func (bnd *bndLobPtr) setPtr() error {
 	lr := &lobReader{
 		ses:           bnd.stmt.ses,
 		ociLobLocator: bnd.lobLocatorp.Value(),
-		piece:         C.OCI_FIRST_PIECE,
+		opened:        true,
+		isClob:        bnd.sqlt == C.SQLT_CLOB,
 		Length:        lobLength,
 	}
 	bnd.value.Reader, bnd.value.Closer = lr, lr
@@ -74,7 +74,7 @@ func (bnd *bndStringPtr) bind(value *string, valueIsNull *bool, position namedPo
 		}
 		bnd.alen[0] = C.ACTUAL_LENGTH_TYPE(len(*value))
 	}
-	bnd.stmt.logF(_drv.Cfg().Log.Stmt.Bind,
+	bnd.stmt.logF(bnd.stmt.logCfg().Stmt.Bind,
 		"%p pos=%v cap=%d len=%d alen=%d bufSize=%d", bnd, position, cap(bnd.buf), len(bnd.buf), bnd.alen[0], stringPtrBufferSize)
 	ph, phLen, phFree := position.CString()
 	if ph != nil {
@@ -109,7 +109,7 @@ func (bnd *bndStringPtr) setPtr() error {
 	if bnd.value == nil {
 		return nil
 	}
-	bnd.stmt.logF(_drv.Cfg().Log.Stmt.Bind,
+	bnd.stmt.logF(bnd.stmt.logCfg().Stmt.Bind,
 		"StringPtr.setPtr isNull=%t alen=%d", bnd.nullp.IsNull(), bnd.alen[0])
 
 	if !bnd.nullp.IsNull() {
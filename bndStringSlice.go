@@ -82,7 +82,7 @@ func (bnd *bndStringSlice) bind(values *[]string, position namedPos, stmt *Stmt,
 		copy(bnd.bytes[m*bnd.maxLen:], str)
 		bnd.alen[m] = C.ACTUAL_LENGTH_TYPE(len(str))
 	}
-	bnd.stmt.logF(_drv.Cfg().Log.Stmt.Bind,
+	bnd.stmt.logF(bnd.stmt.logCfg().Stmt.Bind,
 		"%p pos=%v cap=%d len=%d curlen=%d curlenp=%p maxlen=%d iterations=%d alen=%v isAssoc=%t",
 		bnd, position, cap(bnd.bytes), len(bnd.bytes), bnd.curlen, curlenp, bnd.maxLen, iterations, bnd.alen, isAssocArray)
 	ph, phLen, phFree := position.CString()
@@ -124,7 +124,7 @@ func (bnd *bndStringSlice) bind(values *[]string, position namedPos, stmt *Stmt,
 
 func (bnd *bndStringSlice) setPtr() error {
 	if !bnd.IsAssocArr() {
-		bnd.stmt.logF(_drv.Cfg().Log.Stmt.Bind, "isAssoc=false")
+		bnd.stmt.logF(bnd.stmt.logCfg().Stmt.Bind, "isAssoc=false")
 		return nil
 	}
 	n := int(bnd.curlen)
@@ -137,7 +137,7 @@ func (bnd *bndStringSlice) setPtr() error {
 	if bnd.values != nil {
 		*bnd.values = (*bnd.values)[:n]
 	}
-	bnd.stmt.logF(_drv.Cfg().Log.Stmt.Bind,
+	bnd.stmt.logF(bnd.stmt.logCfg().Stmt.Bind,
 		"StringSlice.setPtr n=%d alen=%v nulls=%v bytes=%s", n, bnd.alen, bnd.nullInds, bnd.bytes)
 	for i, length := range bnd.alen[:n] {
 		if bnd.nullInds[i] <= C.sb2(-1) {
@@ -147,7 +147,7 @@ func (bnd *bndStringSlice) setPtr() error {
 			continue
 		}
 		(*bnd.strings)[i] = string(bnd.bytes[i*bnd.maxLen : i*bnd.maxLen+int(length)])
-		bnd.stmt.logF(_drv.Cfg().Log.Stmt.Bind,
+		bnd.stmt.logF(bnd.stmt.logCfg().Stmt.Bind,
 			"StringSlice.setPtr[%d]=%s", i, (*bnd.strings)[i])
 		if bnd.values != nil {
 			(*bnd.values)[i].IsNull = false
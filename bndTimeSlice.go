@@ -97,7 +97,7 @@ func (bnd *bndTimeSlice) bind(values []time.Time, position namedPos, stmt *Stmt,
 		}
 	}
 
-	bnd.stmt.logF(_drv.Cfg().Log.Stmt.Bind,
+	bnd.stmt.logF(bnd.stmt.logCfg().Stmt.Bind,
 		"%p pos=%v cap=%d len=%d curlen=%d curlenp=%p value_sz=%d alen=%v",
 		bnd, position, cap(bnd.ociDateTimes), len(bnd.ociDateTimes), bnd.curlen, curlenp,
 		valueSz, bnd.alen)
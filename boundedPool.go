@@ -0,0 +1,516 @@
+// Copyright 2014 Rana Ian. All rights reserved.
+// Use of this source code is governed by The MIT License
+// found in the accompanying LICENSE file.
+
+package ora
+
+import (
+	"container/list"
+	"context"
+	"io"
+	"sync"
+	"time"
+)
+
+// ErrPoolClosed is returned by BoundedPool.Get when the pool is closed.
+var ErrPoolClosed = errNew("pool is closed")
+
+// BoundedPoolCfg configures a BoundedPool.
+type BoundedPoolCfg struct {
+	// MaxActive is the maximum number of open sessions, idle or in use.
+	// When it is reached, Get waits for a session to be returned.
+	//
+	// If MaxActive <= 0, then DefaultPoolSize is used.
+	MaxActive int
+
+	// MaxIdle is the maximum number of idle sessions kept open,
+	// sessions returned above it are closed.
+	//
+	// If MaxIdle <= 0 or greater than MaxActive, then MaxActive is used.
+	MaxIdle int
+
+	// MinIdle is the number of idle sessions opened by NewBoundedPool,
+	// and kept open by the evictor.
+	MinIdle int
+
+	// PoolPolicy configures the validation, expiry and reuse order of the idle sessions.
+	PoolPolicy
+}
+
+// PoolStats is a snapshot of the state and the counters of a pool.
+//
+// For Pool, SrvPool and SesPool, which do not limit the open sessions,
+// MaxActive, Waiting, WaitCount and Timeouts are zero, and WaitDuration
+// is the total time spent in Get, opening the new sessions.
+type PoolStats struct {
+	MaxActive    int           // limit of the open sessions
+	Active       int           // number of open sessions, idle or in use
+	Idle         int           // number of idle sessions
+	InUse        int           // number of sessions handed out by Get, and not returned yet
+	Waiting      int           // number of Get calls waiting for a session
+	Hits         uint64        // number of Get calls served by an idle session
+	Misses       uint64        // number of Get calls which had to open a new session
+	Creations    uint64        // number of sessions opened by the pool
+	Evictions    uint64        // number of sessions closed by the pool: evicted, expired, broken or above the idle limit
+	WaitCount    uint64        // total number of Get calls which had to wait
+	WaitDuration time.Duration // total time spent waiting in Get
+	Timeouts     uint64        // number of waiting Get calls given up as their context is done
+	Target       string        // connect target of the last new connection, for a Pool with failover
+}
+
+// NewBoundedPool returns a new bounded session pool with default config.
+func NewBoundedPool(dsn string, cfg BoundedPoolCfg) (*BoundedPool, error) {
+	env, err := OpenEnv()
+	if err != nil {
+		return nil, err
+	}
+	d, err := ParseDSN(dsn)
+	if err != nil {
+		env.Close()
+		return nil, err
+	}
+	return env.NewBoundedPool(d.SrvCfg(NewStmtCfg()), d.SesCfg(), cfg)
+}
+
+// NewBoundedPool returns a session pool which limits the number of open sessions
+// to cfg.MaxActive, and opens cfg.MinIdle sessions in advance.
+//
+// As with NewPool, each session has its own connection (Srv).
+// The idle sessions above cfg.MinIdle are evicted every minute.
+func (env *Env) NewBoundedPool(srvCfg SrvCfg, sesCfg SesCfg, cfg BoundedPoolCfg) (*BoundedPool, error) {
+	if srvCfg.IsZero() {
+		panic("srvCfg shall not be empty")
+	}
+	if sesCfg.IsZero() {
+		sesCfg = NewSesCfg()
+		sesCfg.StmtCfg = Cfg().StmtCfg
+	}
+	p := &BoundedPool{
+		pool: newBoundedPool(func() (io.Closer, error) {
+			srv, err := env.OpenSrv(srvCfg)
+			if err != nil {
+				return nil, err
+			}
+			ses, err := srv.OpenSes(sesCfg)
+			if err != nil {
+				srv.Close()
+				return nil, err
+			}
+			return pooledSes{Ses: ses, srv: srv}, nil
+		}, cfg),
+	}
+	p.pool.validate = func(c io.Closer) error { return c.(pooledSes).Ses.Ping() }
+	p.poolEvictor = &poolEvictor{Evict: p.pool.evict}
+	if err := p.Prefill(p.pool.minIdle); err != nil {
+		p.Close()
+		return nil, err
+	}
+	p.SetEvictDuration(DefaultEvictDuration)
+	return p, nil
+}
+
+// BoundedPool is a session pool which limits the number of open sessions.
+//
+// When all the sessions are in use, Get waits for one to be returned
+// by Ses.Close, serving the waiting calls in FIFO order.
+type BoundedPool struct {
+	pool *boundedPool
+
+	*poolEvictor
+}
+
+// Get returns an idle session, or opens a new one if the pool is not full.
+// Otherwise Get waits for a session to be returned, till ctx is done.
+//
+// Idle sessions are validated, and expired as the BoundedPoolCfg.PoolPolicy says.
+//
+// Closing the returned session puts it back to the pool.
+func (p *BoundedPool) Get(ctx context.Context) (*Ses, error) {
+	for {
+		c, err := p.pool.get(ctx)
+		if err != nil {
+			return nil, err
+		}
+		ps := c.(pooledSes)
+		if !ps.Ses.IsOpen() {
+			p.pool.discard(ps)
+			continue
+		}
+		ps.Ses.Lock()
+		ps.Ses.insteadClose = func(ses *Ses) error { return p.put(ps) }
+		ps.Ses.Unlock()
+		return ps.Ses, nil
+	}
+}
+
+// put is the Ses.Close of the sessions handed out by Get.
+func (p *BoundedPool) put(ps pooledSes) error {
+	ps.Ses.Lock()
+	ps.Ses.insteadClose = nil
+	ps.Ses.Unlock()
+	if !ps.Ses.IsOpen() || ps.Ses.resetClientInfo() != nil {
+		return p.pool.discard(ps)
+	}
+	return p.pool.put(ps)
+}
+
+// Prefill opens new sessions until n sessions are idle, or the pool is full.
+func (p *BoundedPool) Prefill(n int) error {
+	return p.pool.prefill(n)
+}
+
+// Stats returns a snapshot of the pool state.
+func (p *BoundedPool) Stats() PoolStats {
+	return p.pool.stats()
+}
+
+// Close all idle sessions, and the sessions in use when they are returned.
+// The waiting Get calls return ErrPoolClosed.
+func (p *BoundedPool) Close() error {
+	return p.pool.close()
+}
+
+// pooledSes is a session of a BoundedPool, with its own connection.
+type pooledSes struct {
+	*Ses
+	srv *Srv
+}
+
+// Close the session and its connection.
+func (ps pooledSes) Close() error {
+	ps.Ses.Lock()
+	ps.Ses.insteadClose = nil
+	ps.Ses.Unlock()
+	err := ps.Ses.Close()
+	if err2 := ps.srv.Close(); err2 != nil && err == nil {
+		err = err2
+	}
+	return err
+}
+
+// boundedPool is the core of BoundedPool: it limits the number of open
+// io.Closers, and hands them out to the waiting get calls in FIFO order.
+//
+// It knows nothing about sessions, so it can be tested without a database.
+type boundedPool struct {
+	open                        func() (io.Closer, error)
+	validate                    func(io.Closer) error // checks the idle elements on get, if the policy says
+	maxActive, maxIdle, minIdle int
+	policy                      PoolPolicy
+
+	mu           sync.Mutex
+	closed       bool
+	active       int        // number of elements open or being opened, idle or in use
+	idle         []idleElem // the most recently returned is the last
+	waiters      list.List  // of chan io.Closer, the front is served first
+	hits         uint64
+	misses       uint64
+	creations    uint64
+	evictions    uint64
+	waitCount    uint64
+	waitDuration time.Duration
+	timeouts     uint64
+}
+
+func newBoundedPool(open func() (io.Closer, error), cfg BoundedPoolCfg) *boundedPool {
+	p := &boundedPool{open: open, maxActive: cfg.MaxActive, maxIdle: cfg.MaxIdle, minIdle: cfg.MinIdle, policy: cfg.PoolPolicy}
+	if p.maxActive <= 0 {
+		p.maxActive = DefaultPoolSize
+	}
+	if p.maxIdle <= 0 || p.maxIdle > p.maxActive {
+		p.maxIdle = p.maxActive
+	}
+	if p.minIdle > p.maxIdle {
+		p.minIdle = p.maxIdle
+	}
+	return p
+}
+
+// get returns an idle element, or opens a new one if the pool is not full,
+// or waits for one to be handed over by put, or for a free slot.
+//
+// A waiter receives the handed over element on its channel,
+// or nil for a free slot, in which it opens a new element.
+// The channel is closed when the pool is closed.
+func (p *boundedPool) get(ctx context.Context) (io.Closer, error) {
+	for {
+		c, err := p.getIdle()
+		if c != nil || err != nil {
+			return c, err
+		}
+		if c, err = p.getNew(ctx); c != nil || err != nil {
+			return c, err
+		}
+	}
+}
+
+// getIdle returns an idle element, as the policy says.
+// Returns nil if no element is idle.
+func (p *boundedPool) getIdle() (io.Closer, error) {
+	for {
+		now := time.Now()
+		p.mu.Lock()
+		if p.closed {
+			p.mu.Unlock()
+			return nil, ErrPoolClosed
+		}
+		n := len(p.idle)
+		if n == 0 {
+			p.mu.Unlock()
+			return nil, nil
+		}
+		var elem idleElem
+		if p.policy.LIFO {
+			elem = p.idle[n-1]
+			p.idle[n-1] = idleElem{}
+			p.idle = p.idle[:n-1]
+		} else {
+			elem = p.idle[0]
+			p.idle = p.idle[:copy(p.idle, p.idle[1:])]
+			p.idle[:n][n-1] = idleElem{}
+		}
+		p.mu.Unlock()
+		if p.policy.expired(elem.Closer, elem.since, now) ||
+			p.validate != nil && p.policy.mustValidate(elem.since, now) && p.validate(elem.Closer) != nil {
+			p.discard(elem.Closer)
+			continue
+		}
+		p.mu.Lock()
+		p.hits++
+		p.mu.Unlock()
+		return elem.Closer, nil
+	}
+}
+
+// getNew opens a new element if the pool is not full,
+// or waits for one to be handed over by put, or for a free slot.
+// Returns nil, without error, if an element has been put back to the idle ones meanwhile.
+func (p *boundedPool) getNew(ctx context.Context) (io.Closer, error) {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return nil, ErrPoolClosed
+	}
+	if len(p.idle) != 0 {
+		p.mu.Unlock()
+		return nil, nil
+	}
+	if p.active < p.maxActive {
+		p.active++
+		p.misses++
+		p.mu.Unlock()
+		return p.openSlot()
+	}
+	ch := make(chan io.Closer, 1)
+	elem := p.waiters.PushBack(ch)
+	p.waitCount++
+	p.mu.Unlock()
+
+	start := time.Now()
+	select {
+	case c, ok := <-ch:
+		p.mu.Lock()
+		p.waitDuration += time.Since(start)
+		if ok && c == nil {
+			p.misses++
+		} else if ok {
+			p.hits++
+		}
+		p.mu.Unlock()
+		if !ok {
+			return nil, ErrPoolClosed
+		}
+		if c == nil {
+			return p.openSlot()
+		}
+		return c, nil
+
+	case <-ctx.Done():
+	}
+
+	p.mu.Lock()
+	p.waitDuration += time.Since(start)
+	p.timeouts++
+	var discard io.Closer
+	// handing over happens under mu, so ch is either filled (or closed) already,
+	// or still waiting in the queue.
+	select {
+	case c, ok := <-ch:
+		if ok && c == nil {
+			p.releaseSlotLocked()
+		} else if ok && !p.putLocked(c) {
+			discard = c
+		}
+	default:
+		p.waiters.Remove(elem)
+	}
+	p.mu.Unlock()
+	if discard != nil {
+		p.discard(discard)
+	}
+	return nil, ctx.Err()
+}
+
+// openSlot opens a new element in an already reserved slot,
+// and releases the slot on error.
+func (p *boundedPool) openSlot() (io.Closer, error) {
+	c, err := p.open()
+	p.mu.Lock()
+	if err != nil {
+		p.releaseSlotLocked()
+		p.mu.Unlock()
+		return nil, err
+	}
+	p.creations++
+	p.mu.Unlock()
+	return c, nil
+}
+
+// releaseSlotLocked frees the slot of a closed element:
+// the first waiter may open a new element in it.
+func (p *boundedPool) releaseSlotLocked() {
+	if front := p.waiters.Front(); front != nil {
+		p.waiters.Remove(front).(chan io.Closer) <- nil
+		return
+	}
+	p.active--
+}
+
+// put hands c over to the first waiter, or keeps it idle,
+// or closes it if the pool is closed or has enough idle elements.
+func (p *boundedPool) put(c io.Closer) error {
+	p.mu.Lock()
+	kept := p.putLocked(c)
+	p.mu.Unlock()
+	if kept {
+		return nil
+	}
+	return p.discard(c)
+}
+
+// putLocked hands c over to the first waiter, or keeps it idle.
+// Returns false if c has to be discarded.
+func (p *boundedPool) putLocked(c io.Closer) bool {
+	now := time.Now()
+	if p.closed || p.policy.expired(c, now, now) {
+		return false
+	}
+	if front := p.waiters.Front(); front != nil {
+		p.waiters.Remove(front).(chan io.Closer) <- c
+		return true
+	}
+	if len(p.idle) >= p.maxIdle {
+		return false
+	}
+	p.idle = append(p.idle, idleElem{Closer: c, since: now})
+	return true
+}
+
+// discard closes c, then frees its slot.
+func (p *boundedPool) discard(c io.Closer) error {
+	err := c.Close()
+	p.mu.Lock()
+	p.evictions++
+	p.releaseSlotLocked()
+	p.mu.Unlock()
+	return err
+}
+
+// prefill opens new elements until n are idle, or the pool is full.
+func (p *boundedPool) prefill(n int) error {
+	if n > p.maxIdle {
+		n = p.maxIdle
+	}
+	for {
+		p.mu.Lock()
+		if p.closed || len(p.idle) >= n || p.active >= p.maxActive {
+			p.mu.Unlock()
+			return nil
+		}
+		p.active++
+		p.mu.Unlock()
+		c, err := p.openSlot()
+		if err != nil {
+			return err
+		}
+		if err = p.put(c); err != nil {
+			return err
+		}
+	}
+}
+
+// evict closes the idle elements expired by the policy. Without IdleTimeout,
+// it closes half of the idle elements above minIdle, the least recently used first.
+// Then it opens new ones up to minIdle.
+func (p *boundedPool) evict(time.Duration) {
+	now := time.Now()
+	p.mu.Lock()
+	var old []io.Closer
+	keep := p.idle[:0]
+	for _, elem := range p.idle {
+		if p.policy.expired(elem.Closer, elem.since, now) {
+			old = append(old, elem.Closer)
+		} else {
+			keep = append(keep, elem)
+		}
+	}
+	if n := (len(keep) - p.minIdle + 1) / 2; p.policy.IdleTimeout <= 0 && n > 0 {
+		for _, elem := range keep[:n] {
+			old = append(old, elem.Closer)
+		}
+		keep = keep[:copy(keep, keep[n:])]
+	}
+	for i := len(keep); i < len(p.idle); i++ {
+		p.idle[i] = idleElem{}
+	}
+	p.idle = keep
+	p.mu.Unlock()
+	for _, c := range old {
+		p.discard(c)
+	}
+	p.prefill(p.minIdle)
+}
+
+func (p *boundedPool) stats() PoolStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return PoolStats{
+		MaxActive:    p.maxActive,
+		Active:       p.active,
+		Idle:         len(p.idle),
+		InUse:        p.active - len(p.idle),
+		Waiting:      p.waiters.Len(),
+		Hits:         p.hits,
+		Misses:       p.misses,
+		Creations:    p.creations,
+		Evictions:    p.evictions,
+		WaitCount:    p.waitCount,
+		WaitDuration: p.waitDuration,
+		Timeouts:     p.timeouts,
+	}
+}
+
+// close closes the idle elements, and the waiters' channels.
+// The elements in use are closed when put back.
+func (p *boundedPool) close() error {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return nil
+	}
+	p.closed = true
+	idle := p.idle
+	p.idle = nil
+	for e := p.waiters.Front(); e != nil; e = e.Next() {
+		close(e.Value.(chan io.Closer))
+	}
+	p.waiters.Init()
+	p.mu.Unlock()
+	var err error
+	for _, elem := range idle {
+		if closeErr := p.discard(elem.Closer); closeErr != nil && err == nil {
+			err = closeErr
+		}
+	}
+	return err
+}
@@ -0,0 +1,460 @@
+// Copyright 2014 Rana Ian. All rights reserved.
+// Use of this source code is governed by The MIT License
+// found in the accompanying LICENSE file.
+
+package ora
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type fakeCloser struct {
+	id     int
+	closed int32
+}
+
+func (f *fakeCloser) Close() error {
+	atomic.AddInt32(&f.closed, 1)
+	return nil
+}
+
+type fakeOpener struct {
+	mu     sync.Mutex
+	opened []*fakeCloser
+	err    error
+}
+
+func (o *fakeOpener) open() (io.Closer, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.err != nil {
+		return nil, o.err
+	}
+	f := &fakeCloser{id: len(o.opened)}
+	o.opened = append(o.opened, f)
+	return f, nil
+}
+
+func (o *fakeOpener) numOpened() int {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return len(o.opened)
+}
+
+func waitForWaiters(t *testing.T, p *boundedPool, n int) {
+	for i := 0; i < 100; i++ {
+		if p.stats().Waiting == n {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("got %d waiters, wanted %d", p.stats().Waiting, n)
+}
+
+func TestBoundedPoolMaxActive(t *testing.T) {
+	var o fakeOpener
+	p := newBoundedPool(o.open, BoundedPoolCfg{MaxActive: 2})
+	ctx := context.Background()
+	a, err := p.get(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = p.get(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx2, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	_, err = p.get(ctx2)
+	cancel()
+	if err != context.DeadlineExceeded {
+		t.Errorf("got %v, wanted %v", err, context.DeadlineExceeded)
+	}
+	st := p.stats()
+	if st.Active != 2 || st.InUse != 2 || st.Waiting != 0 || st.WaitCount != 1 || st.Timeouts != 1 {
+		t.Errorf("got %+v", st)
+	}
+
+	if err = p.put(a); err != nil {
+		t.Fatal(err)
+	}
+	if b, err := p.get(ctx); err != nil {
+		t.Fatal(err)
+	} else if b != a {
+		t.Errorf("got %v, wanted the idle %v", b, a)
+	}
+	if n := o.numOpened(); n != 2 {
+		t.Errorf("opened %d, wanted 2", n)
+	}
+	if st = p.stats(); st.Hits != 1 || st.Misses != 2 || st.Creations != 2 || st.Evictions != 0 {
+		t.Errorf("counters: got %+v", st)
+	}
+}
+
+func TestBoundedPoolFIFO(t *testing.T) {
+	var o fakeOpener
+	p := newBoundedPool(o.open, BoundedPoolCfg{MaxActive: 1})
+	ctx := context.Background()
+	c, err := p.get(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const n = 3
+	order := make(chan int, n)
+	for i := 0; i < n; i++ {
+		go func(i int) {
+			c, err := p.get(ctx)
+			if err != nil {
+				t.Error(i, err)
+				order <- -1
+				return
+			}
+			order <- i
+			p.put(c)
+		}(i)
+		waitForWaiters(t, p, i+1)
+	}
+	p.put(c)
+	for i := 0; i < n; i++ {
+		if got := <-order; got != i {
+			t.Errorf("%d. got waiter %d", i, got)
+		}
+	}
+	if n := o.numOpened(); n != 1 {
+		t.Errorf("opened %d, wanted 1", n)
+	}
+}
+
+func TestBoundedPoolDiscard(t *testing.T) {
+	var o fakeOpener
+	p := newBoundedPool(o.open, BoundedPoolCfg{MaxActive: 1})
+	ctx := context.Background()
+	c, err := p.get(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan io.Closer)
+	go func() {
+		c, err := p.get(ctx)
+		if err != nil {
+			t.Error(err)
+		}
+		done <- c
+	}()
+	waitForWaiters(t, p, 1)
+
+	// the waiter opens a new one in the freed slot
+	p.discard(c)
+	if c2 := <-done; c2 == nil || c2 == c {
+		t.Errorf("got %v, wanted a new one", c2)
+	}
+	if atomic.LoadInt32(&c.(*fakeCloser).closed) != 1 {
+		t.Errorf("discarded element is not closed")
+	}
+	if st := p.stats(); st.Active != 1 {
+		t.Errorf("got %+v", st)
+	}
+}
+
+func TestBoundedPoolOpenError(t *testing.T) {
+	o := fakeOpener{err: errors.New("fake")}
+	p := newBoundedPool(o.open, BoundedPoolCfg{MaxActive: 1})
+	if _, err := p.get(context.Background()); err != o.err {
+		t.Errorf("got %v, wanted %v", err, o.err)
+	}
+	if st := p.stats(); st.Active != 0 {
+		t.Errorf("slot is not released: %+v", st)
+	}
+}
+
+func TestBoundedPoolPrefill(t *testing.T) {
+	var o fakeOpener
+	p := newBoundedPool(o.open, BoundedPoolCfg{MaxActive: 4, MaxIdle: 3, MinIdle: 2})
+	if err := p.prefill(10); err != nil {
+		t.Fatal(err)
+	}
+	if st := p.stats(); st.Idle != 3 || st.Active != 3 {
+		t.Errorf("got %+v, wanted 3 idle", st)
+	}
+
+	p.evict(0) // closes 1 of the 1 above MinIdle
+	if st := p.stats(); st.Idle != 2 || st.Active != 2 {
+		t.Errorf("got %+v, wanted 2 idle", st)
+	}
+	if c := o.opened[0]; atomic.LoadInt32(&c.closed) != 1 {
+		t.Errorf("the least recently used is not evicted")
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err := p.get(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	p.evict(0) // reopens MinIdle
+	if st := p.stats(); st.Idle != 2 || st.Active != 4 {
+		t.Errorf("got %+v, wanted 2 idle", st)
+	}
+}
+
+func TestBoundedPoolClose(t *testing.T) {
+	var o fakeOpener
+	p := newBoundedPool(o.open, BoundedPoolCfg{MaxActive: 2})
+	ctx := context.Background()
+	a, _ := p.get(ctx)
+	b, _ := p.get(ctx)
+	p.put(b)
+	if _, err := p.get(ctx); err != nil {
+		t.Fatal(err)
+	}
+	errc := make(chan error)
+	go func() {
+		_, err := p.get(ctx)
+		errc <- err
+	}()
+	waitForWaiters(t, p, 1)
+
+	if err := p.close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-errc; err != ErrPoolClosed {
+		t.Errorf("waiter got %v, wanted %v", err, ErrPoolClosed)
+	}
+	p.put(a)
+	if atomic.LoadInt32(&a.(*fakeCloser).closed) != 1 {
+		t.Errorf("element put back to a closed pool is not closed")
+	}
+	if _, err := p.get(ctx); err != ErrPoolClosed {
+		t.Errorf("got %v, wanted %v", err, ErrPoolClosed)
+	}
+}
+
+// fakeAged is a fakeCloser with an opening time, for MaxLifetime.
+type fakeAged struct {
+	fakeCloser
+	openedAt time.Time
+}
+
+func (f *fakeAged) opened() time.Time { return f.openedAt }
+
+func TestBoundedPoolPolicy(t *testing.T) {
+	var o fakeOpener
+	p := newBoundedPool(o.open, BoundedPoolCfg{MaxActive: 3,
+		PoolPolicy: PoolPolicy{ValidateAfter: 20 * time.Millisecond, IdleTimeout: time.Hour, LIFO: true}})
+	broken := int32(-1)
+	p.validate = func(c io.Closer) error {
+		if c.(*fakeCloser).id == int(atomic.LoadInt32(&broken)) {
+			return errors.New("broken")
+		}
+		return nil
+	}
+	ctx := context.Background()
+	a, _ := p.get(ctx)
+	b, _ := p.get(ctx)
+	p.put(a)
+	p.put(b)
+	if c, _ := p.get(ctx); c != b {
+		t.Errorf("LIFO: got %v, wanted %v", c, b)
+	} else {
+		p.put(c)
+	}
+
+	// a is broken, but only validated after ValidateAfter
+	atomic.StoreInt32(&broken, int32(a.(*fakeCloser).id))
+	time.Sleep(30 * time.Millisecond)
+	c, _ := p.get(ctx) // b is fine
+	d, _ := p.get(ctx) // a is broken, so a new one is opened
+	if c != b || d == a {
+		t.Errorf("got %v and %v, wanted %v and a new one", c, d, b)
+	}
+	if atomic.LoadInt32(&a.(*fakeCloser).closed) != 1 {
+		t.Errorf("broken element is not closed")
+	}
+	if st := p.stats(); st.Active != 2 || st.Idle != 0 {
+		t.Errorf("got %+v", st)
+	}
+
+	// idle timeout
+	p.policy.IdleTimeout = 10 * time.Millisecond
+	p.put(c)
+	time.Sleep(20 * time.Millisecond)
+	p.evict(0)
+	if st := p.stats(); st.Active != 1 || st.Idle != 0 {
+		t.Errorf("idle timeout: got %+v", st)
+	}
+
+	// lifetime
+	p.policy.MaxLifetime = time.Minute
+	old := &fakeAged{openedAt: time.Now().Add(-2 * time.Minute)}
+	p.mu.Lock()
+	p.active++
+	p.mu.Unlock()
+	p.put(old)
+	if atomic.LoadInt32(&old.closed) != 1 {
+		t.Errorf("element over its lifetime is not closed on put")
+	}
+}
+
+func TestIdlePoolPolicy(t *testing.T) {
+	p := newIdlePool(2)
+	a, b, c := &fakeCloser{id: 1}, &fakeCloser{id: 2}, &fakeCloser{id: 3}
+	p.Put(a)
+	p.Put(b)
+	p.Put(c) // the pool is full
+	if atomic.LoadInt32(&c.closed) != 1 {
+		t.Errorf("element above size is not closed")
+	}
+	if x, _ := p.Get(); x != a {
+		t.Errorf("FIFO: got %v, wanted %v", x, a)
+	}
+	p.Put(a)
+	p.SetPolicy(PoolPolicy{LIFO: true})
+	if x, _ := p.Get(); x != a {
+		t.Errorf("LIFO: got %v, wanted %v", x, a)
+	}
+
+	p.SetPolicy(PoolPolicy{IdleTimeout: 10 * time.Millisecond, MaxLifetime: time.Minute})
+	time.Sleep(20 * time.Millisecond)
+	p.Put(a)
+	p.Evict(0) // b is timed out, a is not
+	if atomic.LoadInt32(&b.closed) != 1 || atomic.LoadInt32(&a.closed) != 0 {
+		t.Errorf("idle timeout: got closed %d and %d, wanted 1 and 0", b.closed, a.closed)
+	}
+	time.Sleep(20 * time.Millisecond)
+	if x, _ := p.Get(); x != nil {
+		t.Errorf("got timed out %v", x)
+	}
+	if atomic.LoadInt32(&a.closed) != 1 {
+		t.Errorf("timed out element is not closed on get")
+	}
+
+	old := &fakeAged{openedAt: time.Now().Add(-2 * time.Minute)}
+	p.Put(old)
+	if atomic.LoadInt32(&old.closed) != 1 {
+		t.Errorf("element over its lifetime is not closed on put")
+	}
+}
+
+func TestPoolSlowValidation(t *testing.T) {
+	p := &Pool{ses: newIdlePool(1), srv: newIdlePool(1)}
+	p.newSes = func() (*Ses, error) { return &Ses{}, nil }
+	validating, release := make(chan struct{}), make(chan struct{})
+	p.usable = func(ses *Ses, since time.Time) bool {
+		close(validating)
+		<-release
+		return true
+	}
+	idle := &Ses{}
+	p.ses.Put(sesSrvPB{Ses: idle, p: p.srv})
+
+	got := make(chan *Ses, 1)
+	go func() {
+		ses, _ := p.Get()
+		got <- ses
+	}()
+	<-validating
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := p.Get()
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Get is blocked by the validation of an idle session")
+	}
+	close(release)
+	if ses := <-got; ses != idle {
+		t.Errorf("got %p, wanted the idle session %p", ses, idle)
+	}
+}
+
+func TestPoolPutInUse(t *testing.T) {
+	p := &Pool{ses: newIdlePool(1), srv: newIdlePool(1)}
+	p.newSes = func() (*Ses, error) { return &Ses{}, nil }
+	ses, err := p.Get()
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.Put(&Ses{}) // not from this pool
+	if st := p.Stats(); st.InUse != 1 {
+		t.Errorf("got %d in use after a foreign Put, wanted 1", st.InUse)
+	}
+	p.Put(ses)
+	p.Put(ses)
+	if st := p.Stats(); st.InUse != 0 {
+		t.Errorf("got %d in use, wanted 0", st.InUse)
+	}
+}
+
+func TestSrvSesPoolPutInUse(t *testing.T) {
+	sp := &SrvPool{srv: newIdlePool(1)}
+	sp.srv.Put(&Srv{})
+	srv, err := sp.Get()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sp.Put(&Srv{}) // not from this pool
+	if st := sp.Stats(); st.InUse != 1 {
+		t.Errorf("SrvPool: got %d in use after a foreign Put, wanted 1", st.InUse)
+	}
+	sp.Put(srv)
+	sp.Put(srv)
+	if st := sp.Stats(); st.InUse != 0 {
+		t.Errorf("SrvPool: got %d in use, wanted 0", st.InUse)
+	}
+
+	// Get pings the idle sessions, so hand one out as Get does
+	p := &SesPool{ses: newIdlePool(1)}
+	p.counters.got(time.Now(), true, true)
+	ses := p.handOut(&Ses{})
+	p.Put(&Ses{}) // not from this pool
+	if st := p.Stats(); st.InUse != 1 {
+		t.Errorf("SesPool: got %d in use after a foreign Put, wanted 1", st.InUse)
+	}
+	p.Put(ses)
+	p.Put(ses)
+	if st := p.Stats(); st.InUse != 0 {
+		t.Errorf("SesPool: got %d in use, wanted 0", st.InUse)
+	}
+}
+
+func TestPoolSlowConnect(t *testing.T) {
+	p := &Pool{ses: newIdlePool(1), srv: newIdlePool(1)}
+	connecting, release := make(chan struct{}), make(chan struct{})
+	var calls int32
+	p.newSes = func() (*Ses, error) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			// a connect timing out against a dead target
+			close(connecting)
+			<-release
+		}
+		return &Ses{}, nil
+	}
+	go p.Get()
+	<-connecting
+	defer close(release)
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := p.Get()
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Get is blocked by the connect of another Get")
+	}
+}
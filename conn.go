@@ -5,9 +5,11 @@
 package ora
 
 import (
+	"container/list"
 	"context"
 	"database/sql/driver"
 	"fmt"
+	"sync/atomic"
 
 	"golang.org/x/sync/errgroup"
 )
@@ -59,9 +61,22 @@ type Con struct {
 	env *Env
 	ses *Ses
 
+	// sesReset are the session reset hooks of the Connector.
+	sesReset []func(*Ses) error
+
+	// router is the Router of the connection: its sessions are of the Router's pools.
+	router *Router
+	// primary is the session of the primary pool, while ses is the replica
+	// session of a read-only transaction.
+	primary *Ses
+
 	sysNamer
 }
 
+// Ses returns the session of the connection,
+// as for sql.Conn.Raw, to use the Ses methods.
+func (con *Con) Ses() *Ses { return con.ses }
+
 // checkIsOpen validates that the connection is open.
 func (con *Con) checkIsOpen() error {
 	if !con.IsOpen() {
@@ -91,7 +106,7 @@ func (con *Con) Close() (err error) {
 // close ends a session and disconnects from an Oracle server.
 // does not remove Con from Ses.openCons
 func (con *Con) close() (err error) {
-	con.log(_drv.Cfg().Log.Con.Close)
+	con.log(con.logCfg().Con.Close)
 	if err = con.checkIsOpen(); err != nil {
 		return err
 	}
@@ -101,9 +116,17 @@ func (con *Con) close() (err error) {
 		}
 		con.env = nil
 		con.ses = nil
+		con.sesReset = nil
+		con.router, con.primary = nil, nil
 		_drv.conPool.Put(con)
 	}()
 
+	// The sessions of a Router go back to its pools.
+	if con.router != nil {
+		con.endRoute()
+		return con.ses.Close()
+	}
+
 	// Close the session, and its srv, too!
 	if ses := con.ses; ses != nil {
 		srv := ses.srv
@@ -120,29 +143,31 @@ func (con *Con) close() (err error) {
 //
 // Begin is a member of the driver.Conn interface.
 func (con *Con) Begin() (driver.Tx, error) {
-	con.log(_drv.Cfg().Log.Con.Begin)
+	con.log(con.logCfg().Con.Begin)
 	if err := con.checkIsOpen(); err != nil {
 		return nil, err
 	}
+	con.unroute()
 	tx, err := con.ses.StartTx()
 	if err != nil {
-		return nil, maybeBadConn(err)
+		return nil, con.ses.markBadConn(err)
 	}
 	return tx, nil
 }
 
 // Ping makes a round-trip call to an Oracle server to confirm that the connection is active.
 func (con *Con) Ping(ctx context.Context) error {
-	con.log(_drv.Cfg().Log.Con.Ping)
+	con.log(con.logCfg().Con.Ping)
 	if err := con.checkIsOpen(); err != nil {
 		return err
 	}
 	if err := ctx.Err(); err != nil {
 		return err
 	}
+	con.unroute()
 	grp, ctx := errgroup.WithContext(ctx)
 	grp.Go(func() error {
-		return maybeBadConn(con.ses.Ping())
+		return con.ses.markBadConn(con.ses.Ping())
 	})
 	if err := ctx.Err(); err != nil {
 		if isCanceled(err) {
@@ -153,6 +178,68 @@ func (con *Con) Ping(ctx context.Context) error {
 	return grp.Wait()
 }
 
+// IsValid reports whether the connection may be reused:
+// it is open and has not seen an ORA-03113 class error.
+//
+// IsValid is a member of the driver.Validator interface.
+func (con *Con) IsValid() bool {
+	return con.IsOpen() && con.ses != nil && atomic.LoadInt32(&con.ses.badConn) == 0
+}
+
+// ResetSession is called by database/sql before reusing the connection.
+// It switches back from the replica session of a read-only transaction of a Router,
+// rolls back the open transactions, closes the Stmts not prepared by database/sql,
+// and the Rsets left open, resets the ClientInfo set by WithClientInfo to SesCfg.ClientInfo,
+// then calls the session reset hooks of the Connector.
+//
+// Returns driver.ErrBadConn if the connection is not valid or cannot be reset,
+// so database/sql discards it.
+//
+// ResetSession is a member of the driver.SessionResetter interface.
+func (con *Con) ResetSession(ctx context.Context) error {
+	con.endRoute()
+	if !con.IsValid() {
+		return driver.ErrBadConn
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	ses := con.ses
+	errs := _drv.listPool.Get().(*list.List)
+	defer func() {
+		errs.Init()
+		_drv.listPool.Put(errs)
+	}()
+	for _, tx := range ses.openTxs.all() {
+		if err := tx.Rollback(); err != nil {
+			errs.PushBack(errE(err))
+		}
+	}
+	for _, stmt := range ses.openStmts.all() {
+		stmt.RLock()
+		isDrv, openRsets := stmt.isDrv, stmt.openRsets
+		stmt.RUnlock()
+		if isDrv {
+			openRsets.closeAll(errs)
+		} else if err := stmt.Close(); err != nil {
+			errs.PushBack(errE(err))
+		}
+	}
+	if err := ses.resetClientInfo(); err != nil {
+		errs.PushBack(errE(err))
+	}
+	for _, reset := range con.sesReset {
+		if err := reset(ses); err != nil {
+			errs.PushBack(errE(err))
+		}
+	}
+	if multiErr := newMultiErrL(errs); multiErr != nil {
+		con.log(true, "ResetSession: ", *multiErr)
+		return driver.ErrBadConn
+	}
+	return nil
+}
+
 // sysName returns a string representing the Con.
 func (con *Con) sysName() string {
 	if con == nil {
@@ -163,13 +250,22 @@ func (con *Con) sysName() string {
 	})
 }
 
+// logCfg returns the LogDrvCfg of the Env of the Con.
+func (con *Con) logCfg() LogDrvCfg {
+	if con == nil {
+		return _drv.Cfg().Log
+	}
+	return con.env.logCfg()
+}
+
 // log writes a message with an Con system name and caller info.
 func (con *Con) log(enabled bool, v ...interface{}) {
 	if enabled {
+		Log := con.logCfg()
 		if len(v) == 0 {
-			_drv.Cfg().Log.Logger.Infof("%v %v", con.sysName(), callInfo(1))
+			Log.Logger.Infof("%v %v", con.sysName(), callInfo(1))
 		} else {
-			_drv.Cfg().Log.Logger.Infof("%v %v %v", con.sysName(), callInfo(1), fmt.Sprint(v...))
+			Log.Logger.Infof("%v %v %v", con.sysName(), callInfo(1), fmt.Sprint(v...))
 		}
 	}
 }
@@ -197,3 +293,13 @@ func maybeBadConn(err error) error {
 	}
 	return err
 }
+
+// markBadConn returns maybeBadConn(err), and marks the session as broken
+// on an ORA-03113 class error, for Con.IsValid.
+func (ses *Ses) markBadConn(err error) error {
+	err = maybeBadConn(err)
+	if err == driver.ErrBadConn && ses != nil {
+		atomic.StoreInt32(&ses.badConn, 1)
+	}
+	return err
+}
@@ -0,0 +1,12 @@
+// +build go1.15
+
+// Copyright 2014 Rana Ian. All rights reserved.
+// Use of this source code is governed by The MIT License
+// found in the accompanying LICENSE file.
+
+package ora
+
+import "database/sql/driver"
+
+// Ensure that Con implements the go1.15 driver.Validator interface.
+var _ = driver.Validator((*Con)(nil))
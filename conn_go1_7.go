@@ -12,13 +12,16 @@ import "database/sql/driver"
 //
 // Prepare is a member of the driver.Conn interface.
 func (con *Con) Prepare(query string) (driver.Stmt, error) {
-	con.log(_drv.Cfg().Log.Con.Prepare)
+	con.log(con.logCfg().Con.Prepare)
 	if err := con.checkIsOpen(); err != nil {
 		return nil, err
 	}
 	stmt, err := con.ses.Prep(query)
 	if err != nil {
-		return nil, maybeBadConn(err)
+		return nil, con.ses.markBadConn(err)
 	}
-	return &DrvStmt{stmt: stmt}, err
+	stmt.Lock()
+	stmt.isDrv = true
+	stmt.Unlock()
+	return &DrvStmt{stmt: stmt, con: con}, err
 }
@@ -42,15 +42,19 @@ func (con *Con) Prepare(query string) (driver.Stmt, error) {
 // context is for the preparation of the statement,
 // it must not store the context within the statement itself.
 func (con *Con) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
-	con.log(_drv.Cfg().Log.Con.Prepare)
+	con.log(con.logCfg().Con.Prepare)
 	if err := con.checkIsOpen(); err != nil {
 		return nil, err
 	}
+	con.unroute()
 	stmt, err := con.ses.Prep(query)
 	if err != nil {
-		return nil, maybeBadConn(err)
+		return nil, con.ses.markBadConn(err)
 	}
-	return &DrvStmt{stmt: stmt}, err
+	stmt.Lock()
+	stmt.isDrv = true
+	stmt.Unlock()
+	return &DrvStmt{stmt: stmt, con: con}, err
 }
 
 // BeginTx starts and returns a new transaction.
@@ -80,10 +84,14 @@ func (con *Con) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx,
 	default:
 		return nil, fmt.Errorf("Isolation level %v not supported.", level)
 	}
-	con.log(_drv.Cfg().Log.Con.Begin)
+	con.log(con.logCfg().Con.Begin)
 	if err := con.checkIsOpen(); err != nil {
 		return nil, err
 	}
+	con.unroute()
+	if opts.ReadOnly && con.router != nil {
+		con.route()
+	}
 	var tx *Tx
 	done := make(chan error, 1)
 	go func() {
@@ -95,6 +103,9 @@ func (con *Con) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx,
 	var err error
 	select {
 	case err = <-done:
+		if err != nil {
+			con.unroute()
+		}
 		return tx, err
 	case <-ctx.Done():
 		// select again to avoid race condition if both are done
@@ -107,7 +118,7 @@ func (con *Con) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx,
 			}
 		}
 	}
-	return nil, maybeBadConn(err)
+	return nil, con.ses.markBadConn(err)
 }
 
 // vim: set fileencoding=utf-8 noet:
@@ -0,0 +1,161 @@
+// +build go1.10
+
+// Copyright 2014 Rana Ian. All rights reserved.
+// Use of this source code is governed by The MIT License
+// found in the accompanying LICENSE file.
+
+package ora
+
+import (
+	"context"
+	"database/sql/driver"
+	"sync"
+)
+
+var (
+	// Ensure that Drv, Connector and Con implement the go1.10 interfaces.
+	_ = driver.DriverContext((*Drv)(nil))
+	_ = driver.Connector((*Connector)(nil))
+	_ = driver.SessionResetter((*Con)(nil))
+)
+
+// Connector is a driver.Connector with its own SrvCfg, SesCfg and DrvCfg,
+// for use with sql.OpenDB:
+//
+//	db := sql.OpenDB(ora.NewConnector(srvCfg, sesCfg, ora.WithDrvCfg(drvCfg)))
+//
+// A Connector opens its connections in its own Env,
+// with the Log of its DrvCfg (the Logger and the flags of what is logged),
+// and its StmtCfg, unless srvCfg.StmtCfg is set.
+// Close closes that Env.
+type Connector struct {
+	srvCfg   SrvCfg
+	sesCfg   SesCfg
+	cfg      DrvCfg
+	sesInit  []func(*Ses) error
+	sesReset []func(*Ses) error
+
+	mu  sync.Mutex
+	env *Env
+}
+
+// ConnectorOption is an option of NewConnector.
+type ConnectorOption func(*Connector)
+
+// WithDrvCfg sets the DrvCfg of the Connector, instead of the default NewDrvCfg.
+//
+// BINARY_FLOAT columns are returned as float64 anyway, as SetCfg does:
+// float32 is not a driver.Value, database/sql cannot scan it.
+func WithDrvCfg(cfg DrvCfg) ConnectorOption {
+	return func(c *Connector) {
+		cfg.RsetCfg.binaryFloat = F64
+		c.cfg = cfg
+	}
+}
+
+// WithLogger sets the Logger of the DrvCfg of the Connector.
+func WithLogger(lgr Logger) ConnectorOption {
+	return func(c *Connector) { c.cfg.Log.Logger = lgr }
+}
+
+// WithSesInit adds a hook called on each new session of the Connector,
+// before the connection is handed to database/sql.
+// An error closes the connection, and is returned by Connect.
+func WithSesInit(init func(*Ses) error) ConnectorOption {
+	return func(c *Connector) { c.sesInit = append(c.sesInit, init) }
+}
+
+// WithSesReset adds a hook called by Con.ResetSession, before database/sql reuses
+// the connection, as to re-apply the initial session state.
+// An error discards the connection.
+func WithSesReset(reset func(*Ses) error) ConnectorOption {
+	return func(c *Connector) { c.sesReset = append(c.sesReset, reset) }
+}
+
+// NewConnector returns a driver.Connector (a *Connector),
+// which connects with srvCfg and sesCfg, for use with sql.OpenDB.
+//
+// Without WithDrvCfg, the Connector has the NewDrvCfg defaults,
+// it is not affected by SetCfg.
+func NewConnector(srvCfg SrvCfg, sesCfg SesCfg, opts ...ConnectorOption) driver.Connector {
+	c := &Connector{srvCfg: srvCfg, sesCfg: sesCfg, cfg: NewDrvCfg()}
+	c.cfg.RsetCfg.binaryFloat = F64
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// OpenConnector returns a Connector for the data source name,
+// with the DrvCfg of the driver at the time of the call, and its own Env.
+//
+// OpenConnector is a member of the driver.DriverContext interface.
+func (drv *Drv) OpenConnector(name string) (driver.Connector, error) {
+	d, err := ParseDSN(name)
+	if err != nil {
+		return nil, err
+	}
+	c := &Connector{cfg: drv.Cfg()}
+	c.cfg.RsetCfg.binaryFloat = F64
+	c.srvCfg, c.sesCfg = d.SrvCfg(c.cfg.StmtCfg), d.SesCfg()
+	return c, nil
+}
+
+// Connect opens a connection, and calls the session init hooks on it.
+//
+// Connect is a member of the driver.Connector interface.
+func (c *Connector) Connect(ctx context.Context) (driver.Conn, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	env, err := c.openEnv()
+	if err != nil {
+		return nil, err
+	}
+	con, err := env.openCon(c.srvCfg, c.sesCfg)
+	if err != nil {
+		return nil, maybeBadConn(err)
+	}
+	for _, init := range c.sesInit {
+		if err = init(con.ses); err != nil {
+			con.Close()
+			return nil, err
+		}
+	}
+	con.sesReset = c.sesReset
+	return con, nil
+}
+
+// Driver returns the ora driver.
+//
+// Driver is a member of the driver.Connector interface.
+func (c *Connector) Driver() driver.Driver { return _drv }
+
+// Close closes the Env of the Connector, with all its connections.
+func (c *Connector) Close() error {
+	c.mu.Lock()
+	env := c.env
+	c.env = nil
+	c.mu.Unlock()
+	if env == nil {
+		return nil
+	}
+	return env.Close()
+}
+
+// openEnv returns the Env of the Connector, opening it on first use.
+func (c *Connector) openEnv() (*Env, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.env != nil {
+		return c.env, nil
+	}
+	env, err := OpenEnv()
+	if err != nil {
+		return nil, err
+	}
+	env.SetCfg(c.cfg.StmtCfg)
+	env.logc.Store(c.cfg.Log)
+	c.env = env
+	return env, nil
+}
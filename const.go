@@ -244,7 +244,7 @@ const (
 
 	defIdxLob
 	defIdxRaw
-	defIdxLongRaw
+	defIdxLong
 
 	defIdxIntervalYM
 	defIdxIntervalDS
@@ -25,3 +25,80 @@ func ctxStmtCfg(ctx context.Context) (StmtCfg, bool) {
 func WithStmtCfg(ctx context.Context, cfg StmtCfg) context.Context {
 	return context.WithValue(ctx, stmtCfgKey, cfg)
 }
+
+// ClientInfo are the end-to-end tracing attributes of a session, visible in V$SESSION.
+// They are sent to the server piggybacked on the next round trip.
+type ClientInfo struct {
+	ClientIdentifier string // CLIENT_IDENTIFIER, at most 64 bytes
+	ClientInfo       string // CLIENT_INFO, at most 64 bytes
+	Module           string // MODULE, at most 48 bytes
+	Action           string // ACTION, at most 32 bytes
+	DBOp             string // the database operation name (DBOP), at most 30 bytes
+}
+
+// merge returns ci with the non-empty fields of other set.
+func (ci ClientInfo) merge(other ClientInfo) ClientInfo {
+	for _, f := range []struct{ dst, src *string }{
+		{&ci.ClientIdentifier, &other.ClientIdentifier},
+		{&ci.ClientInfo, &other.ClientInfo},
+		{&ci.Module, &other.Module},
+		{&ci.Action, &other.Action},
+		{&ci.DBOp, &other.DBOp},
+	} {
+		if *f.src != "" {
+			*f.dst = *f.src
+		}
+	}
+	return ci
+}
+
+const clientInfoKey = "clientInfo"
+
+// ctxClientInfo returns the ClientInfo from the context, and
+// whether it exist at all.
+func ctxClientInfo(ctx context.Context) (ClientInfo, bool) {
+	ci, ok := ctx.Value(clientInfoKey).(ClientInfo)
+	return ci, ok
+}
+
+// WithClientInfo returns a new context, with the non-empty fields of ci
+// set over the ClientInfo of ctx.
+//
+// The statements executed with the context set the ClientInfo on their session
+// before the call; the statements executed without one, and the sessions put back
+// to a pool (or database/sql connections before reuse) are reset
+// to the SesCfg.ClientInfo.
+func WithClientInfo(ctx context.Context, ci ClientInfo) context.Context {
+	prev, _ := ctxClientInfo(ctx)
+	return context.WithValue(ctx, clientInfoKey, prev.merge(ci))
+}
+
+// WithClientIdentifier returns a new context with the CLIENT_IDENTIFIER set,
+// as the end user on whose behalf the statements are executed.
+func WithClientIdentifier(ctx context.Context, clientIdentifier string) context.Context {
+	return WithClientInfo(ctx, ClientInfo{ClientIdentifier: clientIdentifier})
+}
+
+// WithModuleAction returns a new context with the MODULE and ACTION set.
+func WithModuleAction(ctx context.Context, module, action string) context.Context {
+	return WithClientInfo(ctx, ClientInfo{Module: module, Action: action})
+}
+
+// WithDBOp returns a new context with the database operation name set.
+func WithDBOp(ctx context.Context, dbOp string) context.Context {
+	return WithClientInfo(ctx, ClientInfo{DBOp: dbOp})
+}
+
+const readOnlyKey = "readOnly"
+
+// ctxReadOnly reports whether the work of ctx is marked read-only by WithReadOnly.
+func ctxReadOnly(ctx context.Context) bool {
+	readOnly, _ := ctx.Value(readOnlyKey).(bool)
+	return readOnly
+}
+
+// WithReadOnly returns a new context, marking its work as read-only,
+// for Router.GetContext to route it to a replica.
+func WithReadOnly(ctx context.Context) context.Context {
+	return context.WithValue(ctx, readOnlyKey, true)
+}
@@ -11,18 +11,23 @@ package ora
 */
 import "C"
 import (
+	"context"
+	"io"
+	"sync"
 	"unsafe"
 )
 
 type defBfile struct {
 	ociDef
+	gct            GoColumnType
 	directoryAlias [30]byte
 	filename       [255]byte
 	lobs           []*C.OCILobLocator
 }
 
-func (def *defBfile) define(position int, rset *Rset) error {
+func (def *defBfile) define(position int, gct GoColumnType, rset *Rset) error {
 	def.rset = rset
+	def.gct = gct
 	if def.lobs != nil {
 		C.free(unsafe.Pointer(&def.lobs[0]))
 	}
@@ -34,6 +39,9 @@ func (def *defBfile) value(offset int) (value interface{}, err error) {
 	var bfileValue Bfile
 	bfileValue.IsNull = def.nullInds[offset] < 0
 	if bfileValue.IsNull {
+		if def.gct == L {
+			return (*BfileReader)(nil), nil
+		}
 		return bfileValue, nil
 	}
 	// Get directory alias and filename
@@ -52,9 +60,25 @@ func (def *defBfile) value(offset int) (value interface{}, err error) {
 	}
 	bfileValue.DirectoryAlias = string(def.directoryAlias[:int(dLength)])
 	bfileValue.Filename = string(def.filename[:int(fLength)])
+	if def.gct == L {
+		return def.Reader(offset, bfileValue), nil
+	}
 	return bfileValue, err
 }
 
+// Reader returns a BfileReader for the underlying BFILE locator.
+// Also dissociates this def from the locator!
+func (def *defBfile) Reader(offset int, bfile Bfile) *BfileReader {
+	br := &BfileReader{
+		Bfile:         bfile,
+		ses:           def.rset.stmt.ses,
+		ociLobLocator: def.lobs[offset],
+	}
+	def.lobs[offset] = nil // don't use it anywhere else
+	def.allocated[offset] = false
+	return br
+}
+
 func (def *defBfile) alloc() error {
 	// Allocate lob locator handle
 	// For a LOB define, the buffer pointer must be a pointer to a LOB locator of type OCILobLocator, allocated by the OCIDescriptorAlloc() call.
@@ -116,3 +140,196 @@ func (def *defBfile) close() (err error) {
 	rset.putDef(defIdxBfile, def)
 	return nil
 }
+
+var _ = io.ReadCloser((*BfileReader)(nil))
+var _ = io.ReaderAt((*BfileReader)(nil))
+
+// BfileReader reads the contents of a BFILE: a file in a database directory,
+// on the database server.
+//
+// Get one by SELECTing a BFILE column with the L GoColumnType,
+// or with Ses.OpenBfile. It must be closed after use.
+type BfileReader struct {
+	sync.Mutex
+	Bfile
+	ses           *Ses
+	ociLobLocator *C.OCILobLocator
+	opened        bool
+	off, length   C.oraub8
+}
+
+// OpenBfile returns a BfileReader for the file named by the Bfile.
+//
+// The file is not opened till the first Read: use Exists to check it.
+func (ses *Ses) OpenBfile(bfile Bfile) (*BfileReader, error) {
+	if err := ses.checkClosed(); err != nil {
+		return nil, errE(err)
+	}
+	if bfile.IsNull {
+		return nil, errNew("Bfile is null")
+	}
+	if bfile.DirectoryAlias == "" {
+		return nil, errNew("DirectoryAlias must be specified")
+	}
+	if bfile.Filename == "" {
+		return nil, errNew("Filename must be specified")
+	}
+	env := ses.srv.env
+	var lob *C.OCILobLocator
+	r := C.OCIDescriptorAlloc(
+		unsafe.Pointer(env.ocienv),              //CONST dvoid   *parenth,
+		(*unsafe.Pointer)(unsafe.Pointer(&lob)), //dvoid         **descpp,
+		C.OCI_DTYPE_FILE,                        //ub4           type,
+		0,                                       //size_t        xtramem_sz,
+		nil)                                     //dvoid         **usrmempp);
+	if r == C.OCI_ERROR {
+		return nil, env.ociError()
+	} else if r == C.OCI_INVALID_HANDLE {
+		return nil, errNew("unable to allocate oci lob handle")
+	}
+	cDirectoryAlias := C.CString(bfile.DirectoryAlias)
+	defer C.free(unsafe.Pointer(cDirectoryAlias))
+	cFilename := C.CString(bfile.Filename)
+	defer C.free(unsafe.Pointer(cFilename))
+	if C.OCILobFileSetName(
+		env.ocienv, //OCIEnv             *envhp,
+		env.ocierr, //OCIError           *errhp,
+		&lob,       //OCILobLocator      **filepp,
+		(*C.OraText)(unsafe.Pointer(cDirectoryAlias)), //const OraText      *dir_alias,
+		C.ub2(len(bfile.DirectoryAlias)),              //ub2                d_length,
+		(*C.OraText)(unsafe.Pointer(cFilename)),       //const OraText      *filename,
+		C.ub2(len(bfile.Filename)),                    //ub2                f_length );
+	) == C.OCI_ERROR {
+		err := env.ociError()
+		C.OCIDescriptorFree(unsafe.Pointer(lob), C.OCI_DTYPE_FILE)
+		return nil, err
+	}
+	return &BfileReader{Bfile: bfile, ses: ses, ociLobLocator: lob}, nil
+}
+
+// Exists reports whether the file exists on the server.
+func (br *BfileReader) Exists() (bool, error) {
+	br.Lock()
+	defer br.Unlock()
+	if br.ociLobLocator == nil {
+		return false, er("BfileReader is closed.")
+	}
+	var flag C.boolean
+	if C.OCILobFileExists(
+		br.ses.ocisvcctx,      //OCISvcCtx          *svchp,
+		br.ses.srv.env.ocierr, //OCIError           *errhp,
+		br.ociLobLocator,      //OCILobLocator      *filep,
+		&flag,                 //boolean            *flag );
+	) == C.OCI_ERROR {
+		return false, br.ses.srv.env.ociError("OCILobFileExists")
+	}
+	return flag == C.TRUE, nil
+}
+
+// Size returns the size of the file, in bytes.
+func (br *BfileReader) Size() (int64, error) {
+	br.Lock()
+	defer br.Unlock()
+	if br.ociLobLocator == nil {
+		return 0, er("BfileReader is closed.")
+	}
+	length, err := br.size()
+	return int64(length), err
+}
+
+// size must be called with br locked.
+func (br *BfileReader) size() (C.oraub8, error) {
+	var length C.oraub8
+	if C.OCILobGetLength2(
+		br.ses.ocisvcctx,      //OCISvcCtx          *svchp,
+		br.ses.srv.env.ocierr, //OCIError           *errhp,
+		br.ociLobLocator,      //OCILobLocator      *locp,
+		&length,               //oraub8 *lenp)
+	) == C.OCI_ERROR {
+		return 0, br.ses.srv.env.ociError("OCILobGetLength2")
+	}
+	return length, nil
+}
+
+// open the file, to read it.
+//
+// Must be called with br locked.
+func (br *BfileReader) open() error {
+	if br.opened {
+		return nil
+	}
+	if br.ociLobLocator == nil {
+		return er("BfileReader is closed.")
+	}
+	if C.OCILobFileOpen(
+		br.ses.ocisvcctx,      //OCISvcCtx          *svchp,
+		br.ses.srv.env.ocierr, //OCIError           *errhp,
+		br.ociLobLocator,      //OCILobLocator      *filep,
+		C.OCI_FILE_READONLY,   //ub1                mode );
+	) == C.OCI_ERROR {
+		return br.ses.srv.env.ociError("OCILobFileOpen")
+	}
+	br.opened = true
+	length, err := br.size()
+	br.length = length
+	return err
+}
+
+// Read the next chunk of the file into p.
+// Opens the file at the first call.
+func (br *BfileReader) Read(p []byte) (int, error) {
+	br.Lock()
+	defer br.Unlock()
+	if br.ociLobLocator == nil {
+		return 0, io.EOF
+	}
+	if err := br.open(); err != nil {
+		return 0, err
+	}
+	if br.off >= br.length {
+		return 0, io.EOF
+	}
+	n, amt, err := lobRead(context.Background(), br.ses, br.ociLobLocator, 0, p, br.off)
+	br.off += amt
+	return n, err
+}
+
+// ReadAt reads len(p) bytes into p, starting at off.
+// ReadAt does not change the position of Read.
+func (br *BfileReader) ReadAt(p []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, errF("negative offset %d", off)
+	}
+	br.Lock()
+	defer br.Unlock()
+	if err := br.open(); err != nil {
+		return 0, err
+	}
+	return lobReadAt(context.Background(), br.ses, br.ociLobLocator, 0, p, C.oraub8(off), br.length)
+}
+
+// Close the file, and free the locator.
+func (br *BfileReader) Close() error {
+	if br == nil {
+		return nil
+	}
+	br.Lock()
+	lob, ses, opened := br.ociLobLocator, br.ses, br.opened
+	br.ociLobLocator, br.ses, br.opened = nil, nil, false
+	br.Unlock()
+	if lob == nil || ses == nil {
+		return nil
+	}
+	var err error
+	if opened && C.OCILobFileClose(
+		ses.ocisvcctx,      //OCISvcCtx          *svchp,
+		ses.srv.env.ocierr, //OCIError           *errhp,
+		lob,                //OCILobLocator      *filep );
+	) == C.OCI_ERROR {
+		err = ses.srv.env.ociError("OCILobFileClose")
+	}
+	C.OCIDescriptorFree(
+		unsafe.Pointer(lob), //void     *descp,
+		C.OCI_DTYPE_FILE)    //ub4      type );
+	return err
+}
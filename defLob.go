@@ -12,10 +12,14 @@ package ora
 import "C"
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"io"
+	"math"
+	"sort"
 	"sync"
 	"sync/atomic"
+	"unicode/utf8"
 	"unsafe"
 )
 
@@ -69,7 +73,7 @@ func (def *defLob) Bytes(offset int) (value []byte, err error) {
 	var buf bytes.Buffer
 
 	n, err := r.Read(arr[:])
-	lr.ses.logF(_drv.Cfg().Log.Ses.Prep, "Bytes-1(%p) amt=%d err=%v\n", lr, n, err)
+	lr.ses.logF(lr.ses.logCfg().Ses.Prep, "Bytes-1(%p) amt=%d err=%v\n", lr, n, err)
 	length := lr.Length
 	if length == 0 {
 		if err == io.EOF {
@@ -83,7 +87,9 @@ func (def *defLob) Bytes(offset int) (value []byte, err error) {
 		return buf.Bytes(), err
 	}
 	if def.sqlt == C.SQLT_CLOB {
-		length *= 4
+		// the length is in UTF-16 code units, each at most 3 bytes in UTF-8
+		// (a surrogate pair is 4 bytes).
+		length *= 3
 	}
 	buf.Grow(int(length))
 	buf.Write(arr[:n])
@@ -109,7 +115,8 @@ func (def *defLob) Reader(offset int) io.ReadCloser {
 	lr := &lobReader{
 		ses:           def.rset.stmt.ses,
 		ociLobLocator: def.lobs[offset],
-		piece:         C.OCI_FIRST_PIECE,
+		isClob:        def.sqlt == C.SQLT_CLOB,
+		ctx:           def.rset.ctx,
 	}
 	//def.rset.RUnlock()
 	def.lobs[offset] = nil // don't use it anywhere else
@@ -161,7 +168,7 @@ func (def *defLob) value(offset int) (result interface{}, err error) {
 			return (*Lob)(nil), nil
 		}
 		r := def.Reader(offset)
-		return &Lob{Reader: r}, nil
+		return &Lob{Reader: r, C: def.sqlt == C.SQLT_CLOB}, nil
 	}
 }
 
@@ -227,14 +234,46 @@ func (def *defLob) close() (err error) {
 
 var _ = io.Reader((*lobReader)(nil))
 var _ = io.WriterTo((*lobReader)(nil))
+var _ = io.ReaderAt((*lobReader)(nil))
+var _ = io.Seeker((*lobReader)(nil))
 
+// lobReader reads a LOB locator.
+//
+// Offsets (ReadAt, Seek) are in bytes, for CLOBs in bytes of the UTF-8 text:
+// Oracle addresses CLOBs in characters, so the reader keeps the character
+// offsets of the byte offsets it has read through (marks), and reads from
+// the nearest one when seeking. Seeking to the end of a CLOB reads all of it.
+//
+// The reader closes the LOB when Read reaches the end or fails - unless
+// ReadAt or Seek has been called, as then the reader must be closed explicitly.
 type lobReader struct {
 	sync.Mutex
 	ses           *Ses
 	ociLobLocator *C.OCILobLocator
-	piece, csfrm  C.ub1
-	off           C.oraub8
-	opened        bool
+	// off is the offset of the next OCILobRead2 (in characters for CLOBs),
+	// offByte is the same in bytes.
+	off     C.oraub8
+	offByte int64
+	// pos is the byte offset of the next Read.
+	pos    int64
+	opened bool
+	isClob bool
+	// csfrm is the character set form of a CLOB, see charsetForm.
+	csfrm C.ub1
+	// pend holds the bytes read before offByte, not returned yet:
+	// the rest of a character which did not fit into p, or of a chunk read by seek.
+	pend    []byte
+	pendBuf []byte
+	small   [utf8.UTFMax]byte
+	// marks are the known offsets of a CLOB, ascending, lobChunkSize bytes apart.
+	marks []lobMark
+	// size is the size of the LOB in bytes, if sizeKnown.
+	size      int64
+	sizeKnown bool
+	// seekable is set by ReadAt and Seek: do not close at EOF.
+	seekable bool
+	// ctx is the context of the fetch of the LOB, observed by Read and ReadAt.
+	ctx context.Context
 
 	// Length is the underlying LOB's length.
 	// It is 0 before the first Read call!
@@ -257,78 +296,301 @@ func (lr *lobReader) Close() error {
 	return lobClose(ses, lob)
 }
 
+// open the LOB, to obtain its length.
+//
+// Must be called with lr locked.
+func (lr *lobReader) open() error {
+	if lr.opened {
+		return nil
+	}
+	if lr.ociLobLocator == nil {
+		return io.EOF
+	}
+	lr.opened = true
+	// Open the lob to obtain length; round-trip to database
+	//Log.Infof("Reader OCILobOpen %p", def.ociLobLocator)
+	var err error
+	lr.Length, err = lobOpen(lr.ses, lr.ociLobLocator, C.OCI_LOB_READONLY)
+	return err
+}
+
 // Read into p, the next chunk.
 // Will open the LOB at the first call.
 func (lr *lobReader) Read(p []byte) (n int, err error) {
 	if lr == nil {
 		return 0, io.EOF
 	}
-	defer func() {
-		if err != nil {
-			lr.Close()
-		}
-	}()
+	return lr.ReadContext(lr.fetchContext(), p)
+}
+
+// ReadContext reads into p as Read does, and interrupts the read as ctx is done.
+func (lr *lobReader) ReadContext(ctx context.Context, p []byte) (n int, err error) {
+	if lr == nil {
+		return 0, io.EOF
+	}
 	lr.Lock()
-	ociLobLocator, ses := lr.ociLobLocator, lr.ses
-	opened := lr.opened
+	n, err = lr.read(ctx, p)
+	closeIt := err != nil && !lr.seekable
 	lr.Unlock()
+	if closeIt {
+		lr.Close()
+	}
+	return n, err
+}
+
+// fetchContext returns the context of the fetch of the LOB.
+func (lr *lobReader) fetchContext() context.Context {
+	if lr.ctx != nil {
+		return lr.ctx
+	}
+	return context.Background()
+}
 
-	if ociLobLocator == nil {
+func (lr *lobReader) read(ctx context.Context, p []byte) (n int, err error) {
+	if err = lr.seek(ctx, lr.pos); err != nil {
+		return 0, err
+	}
+	n, err = lr.readNext(ctx, p)
+	lr.pos += int64(n)
+	return n, err
+}
+
+// readNext reads into p from offByte-len(pend), the pending bytes first.
+//
+// Must be called with lr locked.
+func (lr *lobReader) readNext(ctx context.Context, p []byte) (n int, err error) {
+	if len(lr.pend) != 0 {
+		n = copy(p, lr.pend)
+		lr.pend = lr.pend[n:]
+		return n, nil
+	}
+	if lr.ociLobLocator == nil {
 		return 0, io.EOF
 	}
-	if !opened {
-		lr.Lock()
-		lr.opened = true
-		// Open the lob to obtain length; round-trip to database
-		//Log.Infof("Reader OCILobOpen %p", def.ociLobLocator)
-		//fmt.Printf("lobOpen(%p loc=%p)\n", lr, lr.ociLobLocator)
-		lr.Length, err = lobOpen(ses, ociLobLocator, C.OCI_LOB_READONLY)
-		lr.Unlock()
+	if err = lr.open(); err != nil {
+		return 0, err
+	}
+	if lr.Length == 0 || lr.off >= lr.Length {
+		return 0, io.EOF
+	}
+	if len(p) == 0 {
+		return 0, nil
+	}
+	csfrm, err := lr.charsetForm()
+	if err != nil {
+		return 0, err
+	}
+	buf := p
+	if csfrm != 0 && len(p) < len(lr.small) {
+		// the next character may not fit into p: keep its rest for the next Read
+		buf = lr.small[:]
+	}
+	k, err := lr.readChunk(ctx, buf)
+	n = k
+	if len(buf) != len(p) {
+		n = copy(p, buf[:k])
+		if lr.pend = buf[n:k]; len(lr.pend) != 0 && err == io.EOF {
+			err = nil // return the rest first
+		}
+	}
+	if err == nil && k == 0 {
+		err = io.ErrNoProgress
+	}
+	return n, err
+}
+
+// readChunk reads into buf at off with one OCILobRead2 call,
+// advances off and offByte, and marks the new offset.
+//
+// Must be called with lr locked, and the LOB opened.
+func (lr *lobReader) readChunk(ctx context.Context, buf []byte) (int, error) {
+	ses := lr.ses
+	ses.logF(ses.logCfg().Ses.Close, "OCILobRead2(%p) off=%d amt=%d length=%d\n", lr.ociLobLocator, lr.off, len(buf), lr.Length)
+	k, amt, err := lobRead(ctx, ses, lr.ociLobLocator, lr.csfrm, buf, lr.off)
+	ses.logF(ses.logCfg().Ses.Close, "OCILobRead2(%p) off=%d amt=%d err=%v\n", lr.ociLobLocator, lr.off, k, err)
+	lr.off += amt
+	lr.offByte += int64(k)
+	if lr.csfrm != 0 {
+		last := lobMark{}
+		if len(lr.marks) != 0 {
+			last = lr.marks[len(lr.marks)-1]
+		}
+		if lr.offByte-last.byteOff >= lobChunkSize {
+			lr.marks = append(lr.marks, lobMark{byteOff: lr.offByte, charOff: lr.off})
+		}
+	}
+	return k, err
+}
+
+// lobMark is a known offset of a CLOB: the character offset of a byte offset.
+type lobMark struct {
+	byteOff int64
+	charOff C.oraub8
+}
+
+// seek moves the next read (readNext) to the byte offset pos.
+// For CLOBs, it reads from the nearest known offset before pos;
+// beyond the end, offByte stops at the end, and readNext returns io.EOF.
+//
+// Must be called with lr locked.
+func (lr *lobReader) seek(ctx context.Context, pos int64) error {
+	start := lr.offByte - int64(len(lr.pend))
+	if pos == start {
+		return nil
+	}
+	if lr.ociLobLocator == nil {
+		return io.EOF
+	}
+	if err := lr.open(); err != nil {
+		return err
+	}
+	csfrm, err := lr.charsetForm()
+	if err != nil {
+		return err
+	}
+	if csfrm == 0 {
+		lr.off, lr.offByte, lr.pend = C.oraub8(pos), pos, nil
+		return nil
+	}
+	if pos > start && pos <= lr.offByte {
+		lr.pend = lr.pend[pos-start:]
+		return nil
+	}
+	lr.pend = nil
+	i := sort.Search(len(lr.marks), func(i int) bool { return lr.marks[i].byteOff > pos })
+	mark := lobMark{}
+	if i > 0 {
+		mark = lr.marks[i-1]
+	}
+	if pos < lr.offByte || mark.byteOff > lr.offByte {
+		lr.off, lr.offByte = mark.charOff, mark.byteOff
+	}
+	if lr.offByte == pos || lr.off >= lr.Length {
+		return nil
+	}
+	chunk := lobChunkPool.Get().(*[lobChunkSize]byte)
+	defer lobChunkPool.Put(chunk)
+	for lr.offByte < pos && lr.off < lr.Length {
+		k, err := lr.readChunk(ctx, chunk[:])
+		if lr.offByte > pos {
+			keep := int(lr.offByte - pos)
+			lr.pendBuf = append(lr.pendBuf[:0], chunk[k-keep:k]...)
+			lr.pend = lr.pendBuf
+		}
+		if err == io.EOF {
+			break
+		}
 		if err != nil {
+			return err
+		}
+		if k == 0 {
+			return io.ErrNoProgress
+		}
+	}
+	return nil
+}
+
+// byteSize returns the size of the LOB in bytes.
+// For CLOBs, it reads the LOB through from the last known offset.
+//
+// Must be called with lr locked.
+func (lr *lobReader) byteSize(ctx context.Context) (int64, error) {
+	if lr.sizeKnown {
+		return lr.size, nil
+	}
+	if err := lr.open(); err != nil {
+		return 0, err
+	}
+	csfrm, err := lr.charsetForm()
+	if err != nil {
+		return 0, err
+	}
+	if csfrm == 0 {
+		lr.size = int64(lr.Length)
+	} else {
+		if err = lr.seek(ctx, math.MaxInt64); err != nil {
 			return 0, err
 		}
+		lr.size = lr.offByte
+	}
+	lr.sizeKnown = true
+	return lr.size, nil
+}
+
+// charsetForm returns the character set form of a CLOB, and zero for a BLOB.
+//
+// Must be called with lr locked.
+func (lr *lobReader) charsetForm() (C.ub1, error) {
+	if !lr.isClob || lr.csfrm != 0 {
+		return lr.csfrm, nil
+	}
+	csfrm, err := lobCharsetForm(lr.ses, lr.ociLobLocator)
+	if err != nil {
+		return 0, err
 	}
+	lr.csfrm = csfrm
+	return csfrm, nil
+}
 
+// ReadAt reads len(p) bytes into p, starting at the byte offset off,
+// as io.ReaderAt - for CLOBs in bytes of the UTF-8 text.
+//
+// ReadAt does not change the position of Read.
+func (lr *lobReader) ReadAt(p []byte, off int64) (n int, err error) {
+	if off < 0 {
+		return 0, errF("negative offset %d", off)
+	}
 	lr.Lock()
-	Length, off := lr.Length, lr.off
-	piece := lr.piece
-	lr.Unlock()
-	if Length == 0 || off >= Length {
-		return 0, io.EOF
+	defer lr.Unlock()
+	lr.seekable = true
+	if lr.ociLobLocator == nil {
+		return 0, er("lobReader is closed.")
+	}
+	ctx := lr.fetchContext()
+	if err = lr.seek(ctx, off); err != nil {
+		return 0, err
+	}
+	for n < len(p) {
+		k, err := lr.readNext(ctx, p[n:])
+		n += k
+		if err != nil {
+			return n, err
+		}
 	}
+	return n, nil
+}
 
-	var byteAmt C.oraub8 // zero
-	ses.logF(_drv.Cfg().Log.Ses.Close, "OCILobRead2(%p) piece=%d off=%d amt=%d length=%d\n", ociLobLocator, piece, off, len(p), Length)
-	r := C.OCILobRead2(
-		ses.ocisvcctx,         //OCISvcCtx          *svchp,
-		ses.srv.env.ocierr,    //OCIError           *errhp,
-		ociLobLocator,         //OCILobLocator      *locp,
-		&byteAmt,              //oraub8             *byteAmtp,
-		nil,                   //oraub8             *char_amtp,
-		off+1,                 //oraub8             offset, offset is 1-based
-		unsafe.Pointer(&p[0]), //void               *bufp,
-		C.oraub8(len(p)),      //oraub8             bufl,
-		piece,                 //ub1                piece,
-		nil,                   //void               *ctxp,
-		nil,                   //OCICallbackLobRead2 (cbfp)
-		C.ub2(atomic.LoadUint32(&csIDAl32UTF8)), //ub2             csid,
-		C.SQLCS_IMPLICIT,                        //lr.charsetForm,                          //ub1                csfrm );
-	)
-	//Log.Infof("LobRead2 returned %d amt=%d", r, byteAmt)
-	err = nil
-	switch r {
-	case C.OCI_ERROR:
-		ses.Break()
-		err = ses.srv.env.ociError("OCILobRead2")
-	case C.OCI_NO_DATA:
-		err = io.EOF
-	case C.OCI_INVALID_HANDLE:
-		err = fmt.Errorf("Invalid handle %v", ociLobLocator)
+// Seek sets the byte offset for the next Read, as io.Seeker -
+// for CLOBs in bytes of the UTF-8 text, so io.SeekEnd reads all of a CLOB,
+// to know its size.
+func (lr *lobReader) Seek(offset int64, whence int) (int64, error) {
+	lr.Lock()
+	defer lr.Unlock()
+	lr.seekable = true
+	if lr.ociLobLocator == nil {
+		return 0, er("lobReader is closed.")
+	}
+	if err := lr.open(); err != nil {
+		return 0, err
+	}
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += lr.pos
+	case io.SeekEnd:
+		size, err := lr.byteSize(lr.fetchContext())
+		if err != nil {
+			return lr.pos, err
+		}
+		offset += size
+	default:
+		return lr.pos, errF("invalid whence %d", whence)
 	}
-	ses.logF(_drv.Cfg().Log.Ses.Close, "OCILobRead2(%p) off=%d amt=%d err=%v\n", ociLobLocator, off, byteAmt, err)
-	lr.off += byteAmt
-	// byteAmt represents the amount copied into buffer by oci
-	return int(byteAmt), err
+	if offset < 0 {
+		return lr.pos, errF("negative position %d", offset)
+	}
+	lr.pos = offset
+	return offset, nil
 }
 
 // WriteTo writes all data from the LOB into the given Writer.
@@ -351,110 +613,547 @@ func (lr *lobReader) WriteTo(w io.Writer) (n int64, err error) {
 		}
 		n += int64(k)
 		if err != nil {
+			if err == io.EOF {
+				err = nil
+			}
 			return n, err
 		}
 	}
 }
 
-// TODO(tgulacsi): find how to return lobReadWriter.
+// lobRead reads into p from the LOB, starting at off, with one OCILobRead2 call,
+// interrupted as ctx is done.
+//
+// The offset is 0-based, in bytes for BLOBs and in characters for CLOBs.
+// csfrm is the character set form of a CLOB or NCLOB (see lobCharsetForm),
+// and zero for a BLOB or BFILE.
+// Returns the number of bytes read, and the amount the offset advanced by:
+// the number of characters read for CLOBs, as OCI counts them.
+//
+// CLOBs and NCLOBs are read as AL32UTF8, in whole characters,
+// so p must have room for at least utf8.UTFMax bytes.
+func lobRead(ctx context.Context, ses *Ses, lob *C.OCILobLocator, csfrm C.ub1, p []byte, off C.oraub8) (n int, amt C.oraub8, err error) {
+	if len(p) == 0 {
+		return 0, 0, nil
+	}
+	if err = ctx.Err(); err != nil {
+		return 0, 0, err
+	}
+	cs := csfrm
+	if cs == 0 {
+		cs = C.SQLCS_IMPLICIT
+	}
+	byteAmt, charAmt := C.oraub8(len(p)), C.oraub8(0)
+	done := ses.watchCall(ctx, 0)
+	r := C.OCILobRead2(
+		ses.ocisvcctx,                           //OCISvcCtx          *svchp,
+		ses.srv.env.ocierr,                      //OCIError           *errhp,
+		lob,                                     //OCILobLocator      *locp,
+		&byteAmt,                                //oraub8             *byteAmtp,
+		&charAmt,                                //oraub8             *char_amtp,
+		off+1,                                   //oraub8             offset, offset is 1-based
+		unsafe.Pointer(&p[0]),                   //void               *bufp,
+		C.oraub8(len(p)),                        //oraub8             bufl,
+		C.OCI_ONE_PIECE,                         //ub1                piece,
+		nil,                                     //void               *ctxp,
+		nil,                                     //OCICallbackLobRead2 (cbfp)
+		C.ub2(atomic.LoadUint32(&csIDAl32UTF8)), //ub2                csid,
+		cs,                                      //ub1                csfrm );
+	)
+	if r == C.OCI_ERROR {
+		return 0, 0, done(ses.srv.env.ociError("OCILobRead2"))
+	}
+	done(nil)
+	switch r {
+	case C.OCI_NO_DATA:
+		err = io.EOF
+	case C.OCI_INVALID_HANDLE:
+		return 0, 0, fmt.Errorf("Invalid handle %v", lob)
+	}
+	// byteAmt and charAmt represent the amount copied into buffer by oci
+	n = int(byteAmt)
+	if csfrm == 0 {
+		return n, C.oraub8(n), err
+	}
+	return n, charAmt, err
+}
+
+// lobCharsetForm returns the character set form of the LOB locator:
+// SQLCS_NCHAR for NCLOBs, SQLCS_IMPLICIT for CLOBs.
+func lobCharsetForm(ses *Ses, lob *C.OCILobLocator) (C.ub1, error) {
+	var csfrm C.ub1
+	if C.OCILobCharSetForm(
+		ses.srv.env.ocienv, //OCIEnv                  *envhp,
+		ses.srv.env.ocierr, //OCIError                *errhp,
+		lob,                //const OCILobLocator     *locp,
+		&csfrm,             //ub1                     *csfrm );
+	) == C.OCI_ERROR {
+		return C.SQLCS_IMPLICIT, ses.srv.env.ociError("OCILobCharSetForm")
+	}
+	if csfrm == 0 { // not a character LOB
+		csfrm = C.SQLCS_IMPLICIT
+	}
+	return csfrm, nil
+}
+
+// lobReadAt reads len(p) bytes into p from the LOB of the given length,
+// starting at off, as io.ReaderAt.
+//
+// csfrm is the character set form of a CLOB, zero for a BLOB, as for lobRead.
+func lobReadAt(ctx context.Context, ses *Ses, lob *C.OCILobLocator, csfrm C.ub1, p []byte, off, length C.oraub8) (n int, err error) {
+	var small [utf8.UTFMax]byte
+	for n < len(p) && off < length {
+		buf := p[n:]
+		if csfrm != 0 && len(buf) < len(small) {
+			// the next character may not fit into the rest of p
+			buf = small[:]
+		}
+		k, amt, err := lobRead(ctx, ses, lob, csfrm, buf, off)
+		if len(buf) != len(p)-n {
+			k = copy(p[n:], buf[:k])
+		}
+		n += k
+		off += amt
+		if err != nil {
+			return n, err
+		}
+		if k == 0 {
+			return n, io.ErrNoProgress
+		}
+	}
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
 
-var _ = io.ReaderAt((*lobReadWriter)(nil))
-var _ = io.WriterAt((*lobReadWriter)(nil))
+var _ = io.ReaderAt((*LobReadWriter)(nil))
+var _ = io.WriterAt((*LobReadWriter)(nil))
+var _ = io.Closer((*LobReadWriter)(nil))
 
-type lobReadWriter struct {
+// LobReadWriter gives random access to a LOB locator: it can read, write,
+// trim and extend the LOB in place, without reading all of it into memory.
+//
+// Get one with Lob.ReadWriter for a fetched (L) or out-bound *Lob, or with
+// Ses.NewTempLob for a temporary LOB. A LobReadWriter can be bound as a
+// parameter, which binds its locator as is.
+//
+// Offsets and sizes are in bytes for BLOBs, and in characters for CLOBs,
+// as Oracle measures them. Writing to a persistent LOB needs a locator
+// selected FOR UPDATE (or returned by an INSERT/UPDATE ... RETURNING),
+// within the same transaction.
+//
+// The usual LOB restrictions apply: do not use the session for anything
+// else while a LobReadWriter call is in progress.
+type LobReadWriter struct {
+	sync.Mutex
 	ses           *Ses
 	ociLobLocator *C.OCILobLocator
-	size          C.oraub8
+	isClob        bool
+	isTemp        bool
+	opened        bool
+	// csfrm is the character set form of a CLOB, see charsetForm.
+	csfrm C.ub1
 }
 
-// Size returns the actual size of the LOB.
-func (lrw lobReadWriter) Size() uint64 {
-	return uint64(lrw.size)
+// IsClob reports whether the LOB is a character LOB.
+func (lrw *LobReadWriter) IsClob() bool { return lrw.isClob }
+
+// Size returns the actual size of the LOB:
+// the number of bytes for a BLOB, and the number of characters for a CLOB.
+func (lrw *LobReadWriter) Size() (int64, error) {
+	lrw.Lock()
+	defer lrw.Unlock()
+	if lrw.ociLobLocator == nil {
+		return 0, er("LobReadWriter is closed.")
+	}
+	return lrw.size()
 }
 
-// Close the LOB.
-func (lrw *lobReadWriter) Close() error {
-	lob := lrw.ociLobLocator
-	if lob == nil {
+// size must be called with lrw locked.
+func (lrw *LobReadWriter) size() (int64, error) {
+	var length C.oraub8
+	if C.OCILobGetLength2(
+		lrw.ses.ocisvcctx,      //OCISvcCtx          *svchp,
+		lrw.ses.srv.env.ocierr, //OCIError           *errhp,
+		lrw.ociLobLocator,      //OCILobLocator      *locp,
+		&length,                //oraub8 *lenp)
+	) == C.OCI_ERROR {
+		return 0, lrw.ses.srv.env.ociError("OCILobGetLength2")
+	}
+	return int64(length), nil
+}
+
+// ChunkSize returns the LOB's chunk size; reads and writes are most
+// efficient in multiples of it.
+func (lrw *LobReadWriter) ChunkSize() (int, error) {
+	lrw.Lock()
+	defer lrw.Unlock()
+	if lrw.ociLobLocator == nil {
+		return 0, er("LobReadWriter is closed.")
+	}
+	var chunkSize C.ub4
+	if C.OCILobGetChunkSize(
+		lrw.ses.ocisvcctx,      //OCISvcCtx          *svchp,
+		lrw.ses.srv.env.ocierr, //OCIError           *errhp,
+		lrw.ociLobLocator,      //OCILobLocator      *locp,
+		&chunkSize,             //ub4                *chunksizep );
+	) == C.OCI_ERROR {
+		return 0, lrw.ses.srv.env.ociError("OCILobGetChunkSize")
+	}
+	return int(chunkSize), nil
+}
+
+// Close the LOB: closes it if opened, frees the temporary LOB, and the locator.
+// Returns the first error.
+func (lrw *LobReadWriter) Close() error {
+	if lrw == nil {
 		return nil
 	}
-	lrw.ociLobLocator = nil
-	return lobClose(lrw.ses, lob)
+	lrw.Lock()
+	lob, ses := lrw.ociLobLocator, lrw.ses
+	isTemp, opened := lrw.isTemp, lrw.opened
+	lrw.ociLobLocator, lrw.ses, lrw.opened = nil, nil, false
+	lrw.Unlock()
+	if lob == nil || ses == nil {
+		return nil
+	}
+	var err error
+	if opened && C.OCILobClose(
+		ses.ocisvcctx,      //OCISvcCtx          *svchp,
+		ses.srv.env.ocierr, //OCIError           *errhp,
+		lob,                //OCILobLocator      *locp,
+	) == C.OCI_ERROR {
+		err = ses.srv.env.ociError("OCILobClose")
+	}
+	if isTemp && C.OCILobFreeTemporary(
+		ses.ocisvcctx,      //OCISvcCtx          *svchp,
+		ses.srv.env.ocierr, //OCIError           *errhp,
+		lob,                //OCILobLocator      *locp,
+	) == C.OCI_ERROR && err == nil {
+		err = ses.srv.env.ociError("OCILobFreeTemporary")
+	}
+	C.OCIDescriptorFree(unsafe.Pointer(lob), //void     *descp,
+		C.OCI_DTYPE_LOB) //ub4      type );
+	return err
+}
+
+// Trim the LOB to the given length.
+//
+// Trim cannot extend the LOB - see Truncate for that.
+func (lrw *LobReadWriter) Trim(length int64) error {
+	lrw.Lock()
+	defer lrw.Unlock()
+	return lrw.trim(length)
 }
 
-// Truncate the lob to the given length.
-func (lrw *lobReadWriter) Truncate(length int64) error {
+func (lrw *LobReadWriter) trim(length int64) error {
+	if lrw.ociLobLocator == nil {
+		return er("LobReadWriter is closed.")
+	}
+	if err := lrw.openRW(); err != nil {
+		return err
+	}
 	if C.OCILobTrim2(
 		lrw.ses.ocisvcctx,      //OCISvcCtx          *svchp,
 		lrw.ses.srv.env.ocierr, //OCIError           *errhp,
 		lrw.ociLobLocator,      //OCILobLocator      *locp,
 		C.oraub8(length),       //oraub8             *newlen)
 	) == C.OCI_ERROR {
-		return lrw.ses.srv.env.ociError()
+		return lrw.ses.srv.env.ociError("OCILobTrim2")
 	}
 	return nil
 }
 
+// Truncate changes the size of the LOB, as os.File.Truncate does.
+//
+// If the LOB is longer, it is trimmed; if shorter, it is extended
+// with zero bytes (BLOB) or spaces (CLOB).
+func (lrw *LobReadWriter) Truncate(size int64) error {
+	lrw.Lock()
+	defer lrw.Unlock()
+	if lrw.ociLobLocator == nil {
+		return er("LobReadWriter is closed.")
+	}
+	length, err := lrw.size()
+	if err != nil {
+		return err
+	}
+	if size == length {
+		return nil
+	}
+	if size < length {
+		return lrw.trim(size)
+	}
+	// Oracle fills the gap when writing beyond the end of the LOB.
+	fill := []byte{0}
+	if lrw.isClob {
+		fill[0] = ' '
+	}
+	_, _, err = lrw.writeAtLocked(fill, size-1)
+	return err
+}
+
 // ReadAt reads into p, starting from off.
-func (lrw *lobReadWriter) ReadAt(p []byte, off int64) (n int, err error) {
-	byteAmt := C.oraub8(len(p))
-	//Log.Infof("LobRead2 off=%d amt=%d", off, len(p))
-	r := C.OCILobRead2(
-		lrw.ses.ocisvcctx,      //OCISvcCtx          *svchp,
-		lrw.ses.srv.env.ocierr, //OCIError           *errhp,
-		lrw.ociLobLocator,      //OCILobLocator      *locp,
-		&byteAmt,               //oraub8             *byteAmtp,
-		nil,                    //oraub8             *char_amtp,
-		C.oraub8(off)+1,        //oraub8             offset, offset is 1-based
-		unsafe.Pointer(&p[0]),  //void               *bufp,
-		C.oraub8(len(p)),       //oraub8             bufl,
-		C.OCI_ONE_PIECE,        //ub1                piece,
-		nil,                    //void               *ctxp,
-		nil,                    //OCICallbackLobRead2 (cbfp)
-		C.ub2(atomic.LoadUint32(&csIDAl32UTF8)),
-		C.SQLCS_IMPLICIT, //ub1                csfrm );
-	)
-	//Log.Infof("LobRead2 returned %d amt=%d", r, byteAmt)
-	switch r {
-	case C.OCI_ERROR:
-		return 0, lrw.ses.srv.env.ociError()
-	case C.OCI_NO_DATA:
-		return int(byteAmt), io.EOF
-	case C.OCI_INVALID_HANDLE:
-		return 0, fmt.Errorf("Invalid Handle %v", lrw.ociLobLocator)
+//
+// For CLOBs, off is in characters, and p is filled with UTF-8 bytes.
+func (lrw *LobReadWriter) ReadAt(p []byte, off int64) (n int, err error) {
+	return lrw.ReadAtContext(context.Background(), p, off)
+}
+
+// ReadAtContext reads into p as ReadAt does, and interrupts the read as ctx is done.
+func (lrw *LobReadWriter) ReadAtContext(ctx context.Context, p []byte, off int64) (n int, err error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	lrw.Lock()
+	defer lrw.Unlock()
+	if lrw.ociLobLocator == nil {
+		return 0, er("LobReadWriter is closed.")
 	}
-	return int(byteAmt), nil
+	length, err := lrw.size()
+	if err != nil {
+		return 0, err
+	}
+	csfrm, err := lrw.charsetForm()
+	if err != nil {
+		return 0, err
+	}
+	return lobReadAt(ctx, lrw.ses, lrw.ociLobLocator, csfrm, p, C.oraub8(off), C.oraub8(length))
+}
+
+// charsetForm returns the character set form of a CLOB, and zero for a BLOB.
+//
+// Must be called with lrw locked.
+func (lrw *LobReadWriter) charsetForm() (C.ub1, error) {
+	if !lrw.isClob || lrw.csfrm != 0 {
+		return lrw.csfrm, nil
+	}
+	csfrm, err := lobCharsetForm(lrw.ses, lrw.ociLobLocator)
+	if err != nil {
+		return 0, err
+	}
+	lrw.csfrm = csfrm
+	return csfrm, nil
 }
 
 // WriteAt writes data in p into the LOB, starting at off.
-func (lrw *lobReadWriter) WriteAt(p []byte, off int64) (n int, err error) {
+//
+// For CLOBs, off is in characters, and p must hold whole UTF-8 characters.
+func (lrw *LobReadWriter) WriteAt(p []byte, off int64) (n int, err error) {
+	n, _, err = lrw.writeAt(p, off)
+	return n, err
+}
+
+// writeAt writes p at off, and returns the number of bytes and characters written.
+func (lrw *LobReadWriter) writeAt(p []byte, off int64) (n int, chars int64, err error) {
+	if len(p) == 0 {
+		return 0, 0, nil
+	}
+	lrw.Lock()
+	defer lrw.Unlock()
+	return lrw.writeAtLocked(p, off)
+}
+
+// writeAtLocked is writeAt, called with lrw locked.
+func (lrw *LobReadWriter) writeAtLocked(p []byte, off int64) (n int, chars int64, err error) {
+	if lrw.ociLobLocator == nil {
+		return 0, 0, er("LobReadWriter is closed.")
+	}
+	if err = lrw.openRW(); err != nil {
+		return 0, 0, err
+	}
+	csfrm, err := lrw.charsetForm()
+	if err != nil {
+		return 0, 0, err
+	}
+	if csfrm == 0 {
+		csfrm = C.SQLCS_IMPLICIT
+	}
 	//Log.Infof("LobWrite2 off=%d len=%d", off, n)
 	byteAmt := C.oraub8(len(p))
+	var charAmt C.oraub8 // zero: byteAmt is used
 	// Write to Oracle
-	//fmt.Println("OCILobWrite2")
 	if C.OCILobWrite2(
 		lrw.ses.ocisvcctx,      //OCISvcCtx          *svchp,
 		lrw.ses.srv.env.ocierr, //OCIError           *errhp,
 		lrw.ociLobLocator,      //OCILobLocator      *locp,
 		&byteAmt,               //oraub8          *byteAmtp,
-		nil,                    //oraub8          *char_amtp,
+		&charAmt,               //oraub8          *char_amtp,
 		C.oraub8(off)+1,        //oraub8          offset, starting position is 1
 		unsafe.Pointer(&p[0]),  //void            *bufp,
 		C.oraub8(len(p)),
-		C.OCI_ONE_PIECE, //ub1             piece,
-		nil,             //void            *ctxp,
-		nil,             //OCICallbackLobWrite2 (cbfp)
+		C.OCI_ONE_PIECE,                         //ub1             piece,
+		nil,                                     //void            *ctxp,
+		nil,                                     //OCICallbackLobWrite2 (cbfp)
 		C.ub2(atomic.LoadUint32(&csIDAl32UTF8)), //ub2             csid,
-		C.SQLCS_IMPLICIT,                        //ub1             csfrm );
-	//fmt.Printf("C.OCI_NEED_DATA %v, C.OCI_SUCCESS %v\n", C.OCI_NEED_DATA, C.OCI_SUCCESS)
+		csfrm,                                   //ub1             csfrm );
 	) == C.OCI_ERROR {
-		return 0, lrw.ses.srv.env.ociError()
+		return 0, 0, lrw.ses.srv.env.ociError("OCILobWrite2")
+	}
+	if !lrw.isClob {
+		return int(byteAmt), int64(byteAmt), nil
+	}
+	// charAmt is the number of characters written, as Oracle counts them.
+	return int(byteAmt), int64(charAmt), nil
+}
+
+// openRW opens the LOB for writing, to have the indexes updated only once,
+// at Close.
+//
+// Must be called with lrw locked.
+func (lrw *LobReadWriter) openRW() error {
+	if lrw.opened {
+		return nil
+	}
+	if C.OCILobOpen(
+		lrw.ses.ocisvcctx,      //OCISvcCtx          *svchp,
+		lrw.ses.srv.env.ocierr, //OCIError           *errhp,
+		lrw.ociLobLocator,      //OCILobLocator      *locp,
+		C.OCI_LOB_READWRITE,    //ub1              mode );
+	) == C.OCI_ERROR {
+		return lrw.ses.srv.env.ociError("OCILobOpen")
+	}
+	lrw.opened = true
+	return nil
+}
+
+// NewWriter returns an io.WriteCloser which writes sequentially into the LOB,
+// starting at off (in characters for CLOBs).
+//
+// The data is sent in LOB chunk sized pieces, so only one piece is buffered.
+// Close flushes the buffered data, but does not close the LobReadWriter.
+//
+// To replace the contents of the LOB, Trim(0) it first, or Trim the LOB to
+// the written length after closing the writer.
+func (lrw *LobReadWriter) NewWriter(off int64) (io.WriteCloser, error) {
+	chunkSize, err := lrw.ChunkSize()
+	if err != nil {
+		return nil, err
+	}
+	// write in multiples of the chunk size, up to lobChunkSize
+	bufSize := lobChunkSize
+	if chunkSize > 0 && chunkSize < bufSize {
+		bufSize -= bufSize % chunkSize
+	}
+	return &lobWriter{lrw: lrw, off: off, buf: bytesPool.Get(bufSize)[:0]}, nil
+}
+
+var _ = io.WriteCloser((*lobWriter)(nil))
+
+type lobWriter struct {
+	lrw *LobReadWriter
+	off int64
+	buf []byte
+	err error
+}
+
+// Write p into the buffer, and send the full pieces to the LOB.
+func (lw *lobWriter) Write(p []byte) (int, error) {
+	if lw.err != nil {
+		return 0, lw.err
+	}
+	var n int
+	for len(p) > 0 {
+		k := copy(lw.buf[len(lw.buf):cap(lw.buf)], p)
+		lw.buf = lw.buf[:len(lw.buf)+k]
+		n += k
+		p = p[k:]
+		if len(lw.buf) == cap(lw.buf) {
+			if err := lw.flush(false); err != nil {
+				return n, err
+			}
+		}
+	}
+	return n, nil
+}
+
+// flush sends the buffer to the LOB.
+// For CLOBs, an incomplete UTF-8 sequence at the end is kept for the next
+// piece, unless this is the last one.
+func (lw *lobWriter) flush(last bool) error {
+	if len(lw.buf) == 0 {
+		return nil
+	}
+	send := lw.buf
+	if lw.lrw.isClob && !last {
+		send = send[:utf8Boundary(send)]
+	}
+	n, chars, err := lw.lrw.writeAt(send, lw.off)
+	lw.off += chars
+	rest := copy(lw.buf, lw.buf[n:])
+	lw.buf = lw.buf[:rest]
+	if err != nil {
+		lw.err = err
+	}
+	return err
+}
+
+// Close flushes the remaining buffered data.
+func (lw *lobWriter) Close() error {
+	if lw.buf == nil {
+		return lw.err
+	}
+	err := lw.flush(true)
+	bytesPool.Put(lw.buf)
+	lw.buf = nil
+	if err == nil {
+		lw.err = errNew("lobWriter is closed")
+	}
+	return err
+}
+
+// ReadWriter returns a LobReadWriter for the LOB locator behind a Lob
+// fetched with the L GoColumnType, or returned in a *Lob out parameter.
+//
+// The LobReadWriter takes over the locator, so the Lob cannot be read after
+// this - close the returned LobReadWriter instead.
+func (this *Lob) ReadWriter() (*LobReadWriter, error) {
+	if this == nil {
+		return nil, er("Lob is nil.")
+	}
+	lr, ok := this.Reader.(*lobReader)
+	if !ok {
+		return nil, errF("Lob does not hold a LOB locator (%T)", this.Reader)
+	}
+	lr.Lock()
+	lob, ses, opened := lr.ociLobLocator, lr.ses, lr.opened
+	lr.ociLobLocator, lr.ses = nil, nil
+	lr.Unlock()
+	if lob == nil || ses == nil {
+		return nil, er("Lob is closed.")
+	}
+	if opened {
+		// opened read-only by the reader
+		C.OCILobClose(
+			ses.ocisvcctx,      //OCISvcCtx          *svchp,
+			ses.srv.env.ocierr, //OCIError           *errhp,
+			lob,                //OCILobLocator      *locp,
+		)
+	}
+	this.Reader, this.Closer = nil, nil
+	return &LobReadWriter{ses: ses, ociLobLocator: lob, isClob: this.C || lr.isClob, csfrm: lr.csfrm}, nil
+}
+
+// NewTempLob creates a temporary LOB for the duration of the session,
+// and returns a LobReadWriter for it.
+//
+// The temporary LOB is freed when the LobReadWriter is closed.
+// It can be bound as a parameter to insert or update a LOB column,
+// or to pass it to a PL/SQL procedure.
+func (ses *Ses) NewTempLob(isClob bool) (*LobReadWriter, error) {
+	if err := ses.checkClosed(); err != nil {
+		return nil, errE(err)
+	}
+	lobType := C.ub1(C.OCI_TEMP_BLOB)
+	if isClob {
+		lobType = C.OCI_TEMP_CLOB
 	}
-	//fmt.Printf("r %v, current %v, buffer %v\n", r, current, buffer)
-	if C.oraub8(off)+byteAmt > lrw.size {
-		lrw.size = C.oraub8(off) + byteAmt
+	lob, _, err := createTempLob(ses, lobType)
+	if err != nil {
+		return nil, err
 	}
-	return int(byteAmt), nil
+	return &LobReadWriter{ses: ses, ociLobLocator: lob, isClob: isClob, isTemp: true}, nil
 }
 
 func lobOpen(ses *Ses, lob *C.OCILobLocator, mode C.ub1) (
@@ -465,7 +1164,7 @@ func lobOpen(ses *Ses, lob *C.OCILobLocator, mode C.ub1) (
 	env := ses.srv.env
 	ses.RUnlock()
 
-	ses.log(_drv.Cfg().Log.Ses.Prep, "lobOpen")
+	ses.log(ses.logCfg().Ses.Prep, "lobOpen")
 	//Log.Infof("OCILobOpen %p\n%s", lob, getStack(1))
 	r := C.OCILobOpen(
 		ocisvcctx,  //OCISvcCtx          *svchp,
@@ -0,0 +1,190 @@
+// Copyright 2014 Rana Ian. All rights reserved.
+// Use of this source code is governed by The MIT License
+// found in the accompanying LICENSE file.
+
+package ora
+
+/*
+#include <stdlib.h>
+#include <oci.h>
+#include "version.h"
+
+// longPiece is the indicator, length and return code of a piece of a LONG fetch,
+// in C memory, as OCI writes them after OCIStmtSetPieceInfo has returned.
+typedef struct {
+	sb2 ind;
+	ub2 rcode;
+	ub4 alen;
+} longPiece;
+*/
+import "C"
+import (
+	"bytes"
+	"unsafe"
+)
+
+// longMinPiece is the minimum size of a piece of a LONG or LONG RAW fetch.
+const longMinPiece = 64 << 10
+
+// defLong defines a LONG or LONG RAW column for piecewise (dynamic) fetch,
+// so values of any length are fetched, without a preset buffer size.
+//
+// The rset fetches one row at a time, and calls nextPiece for each piece
+// OCIStmtFetch2 asks for. Each piece is fetched into pieceBuf, and
+// appended to buf by endPiece. pieceBuf starts at longMinPiece bytes,
+// and grows up to maxPiece for long values.
+//
+// The value is fetched whole, also for L: the Lob reads it from memory.
+type defLong struct {
+	ociDef
+	isRaw    bool
+	gct      GoColumnType
+	maxPiece int
+	buf      []byte
+	pending  bool
+	pieceBuf unsafe.Pointer
+	pieceCap int
+	piece    *C.longPiece
+}
+
+func (def *defLong) define(position int, isRaw bool, maxPiece uint32, gct GoColumnType, rset *Rset) error {
+	def.rset = rset
+	def.isRaw = isRaw
+	def.gct = gct
+	def.maxPiece = int(maxPiece)
+	if def.maxPiece < longMinPiece {
+		def.maxPiece = longMinPiece
+	}
+	def.freePiece()
+	def.pieceBuf, def.pieceCap = C.malloc(longMinPiece), longMinPiece
+	def.piece = (*C.longPiece)(C.malloc(C.sizeof_longPiece))
+	def.piece.ind, def.piece.alen, def.piece.rcode = -1, 0, 0
+	dty := C.ub2(C.SQLT_LNG)
+	if isRaw {
+		dty = C.SQLT_LBI
+	}
+	if r := C.OCIDEFINEBYPOS(
+		rset.ocistmt,               //OCIStmt     *stmtp,
+		&def.ocidef,                //OCIDefine   **defnpp,
+		rset.env.ocierr,            //OCIError    *errhp,
+		C.ub4(position),            //ub4         position,
+		nil,                        //void        *valuep,
+		C.LENGTH_TYPE(C.SB4MAXVAL), //sb8         value_sz,
+		dty,                        //ub2         dty,
+		nil,                        //void        *indp,
+		nil,                        //ub4         *rlenp,
+		nil,                        //ub2         *rcodep,
+		C.OCI_DYNAMIC_FETCH,        //ub4         mode );
+	); r == C.OCI_ERROR {
+		return rset.stmt.ses.srv.env.ociError()
+	}
+	return nil
+}
+
+// nextPiece sets the buffer for the next piece of the value.
+func (def *defLong) nextPiece(piece C.ub1) error {
+	full := def.pending && int(def.piece.alen) == def.pieceCap
+	def.endPiece()
+	if full && def.pieceCap < def.maxPiece {
+		// a long value: fetch it in larger pieces
+		n := 2 * def.pieceCap
+		if n > def.maxPiece {
+			n = def.maxPiece
+		}
+		C.free(def.pieceBuf)
+		def.pieceBuf, def.pieceCap = C.malloc(C.size_t(n)), n
+	}
+	def.piece.alen = C.ub4(def.pieceCap)
+	def.pending = true
+	if C.OCIStmtSetPieceInfo(
+		unsafe.Pointer(def.ocidef), //void          *hndlp,
+		C.OCI_HTYPE_DEFINE,         //ub4           type,
+		def.rset.env.ocierr,        //OCIError      *errhp,
+		def.pieceBuf,               //const void    *bufp,
+		&def.piece.alen,            //ub4           *alenp,
+		piece,                      //ub1           piece,
+		unsafe.Pointer(&def.piece.ind), //const void    *indp,
+		&def.piece.rcode,               //ub2           *rcodep );
+	) == C.OCI_ERROR {
+		def.pending = false
+		return def.rset.env.ociError("OCIStmtSetPieceInfo")
+	}
+	return nil
+}
+
+// endPiece appends the data of the last piece to buf.
+func (def *defLong) endPiece() {
+	if !def.pending {
+		return
+	}
+	def.pending = false
+	n := int(def.piece.alen)
+	def.buf = append(def.buf, (*[1 << 30]byte)(def.pieceBuf)[:n:n]...)
+}
+
+func (def *defLong) value(offset int) (value interface{}, err error) {
+	def.endPiece()
+	isNull := def.piece.ind < 0 || def.buf == nil
+	switch def.gct {
+	case S:
+		return string(def.buf), nil
+	case OraS:
+		return String{IsNull: isNull, Value: string(def.buf)}, nil
+	case OraBin:
+		if isNull {
+			return Raw{IsNull: true}, nil
+		}
+		return Raw{Value: def.buf}, nil
+	case L:
+		if isNull {
+			return (*Lob)(nil), nil
+		}
+		// not streamed: the value has been fetched whole
+		return &Lob{Reader: bytes.NewReader(def.buf), C: !def.isRaw}, nil
+	default: // Bin
+		if isNull {
+			return nil, nil
+		}
+		return def.buf, nil
+	}
+}
+
+// alloc resets the value before fetching the next row.
+// The previous value is not reused, as it has been returned to the caller.
+func (def *defLong) alloc() error {
+	def.buf, def.pending = nil, false
+	def.piece.ind, def.piece.alen, def.piece.rcode = -1, 0, 0
+	return nil
+}
+
+func (def *defLong) free() {
+	def.buf, def.pending = nil, false
+	def.freePiece()
+}
+
+// freePiece frees the C memory of the pieces.
+func (def *defLong) freePiece() {
+	if def.pieceBuf != nil {
+		C.free(def.pieceBuf)
+		def.pieceBuf, def.pieceCap = nil, 0
+	}
+	if def.piece != nil {
+		C.free(unsafe.Pointer(def.piece))
+		def.piece = nil
+	}
+}
+
+func (def *defLong) close() (err error) {
+	defer func() {
+		if value := recover(); value != nil {
+			err = errR(value)
+		}
+	}()
+
+	rset := def.rset
+	def.rset = nil
+	def.ocidef = nil
+	def.free()
+	rset.putDef(defIdxLong, def)
+	return nil
+}
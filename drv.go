@@ -40,6 +40,10 @@ func (c DrvCfg) SetPrefetchMemorySize(prefetchMemorySize uint32) DrvCfg {
 	c.StmtCfg = c.StmtCfg.SetPrefetchMemorySize(prefetchMemorySize)
 	return c
 }
+func (c DrvCfg) SetTimeout(timeout time.Duration) DrvCfg {
+	c.StmtCfg = c.StmtCfg.SetTimeout(timeout)
+	return c
+}
 func (c DrvCfg) SetLongBufferSize(size uint32) DrvCfg {
 	c.StmtCfg = c.StmtCfg.SetLongBufferSize(size)
 	return c
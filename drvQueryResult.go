@@ -5,6 +5,7 @@
 package ora
 
 import (
+	"context"
 	"database/sql/driver"
 	"fmt"
 	"io"
@@ -23,18 +24,26 @@ import "C"
 // DrvQueryResult implements the driver.Rows interface.
 type DrvQueryResult struct {
 	rset *Rset
+	ctx  context.Context // of QueryContext, observed by Next
 }
 
 // Next populates the specified slice with the next row of data.
 //
 // Returns io.EOF when there are no more rows.
 //
+// The fetch and the LOB reads are interrupted as the context
+// of QueryContext is done.
+//
 // Next is a member of the driver.Rows interface.
 func (qr *DrvQueryResult) Next(dest []driver.Value) (err error) {
 	if qr.rset == nil {
 		return er("empty Rset")
 	}
-	err = qr.rset.beginRow()
+	ctx := qr.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	err = qr.rset.beginRow(ctx)
 	if err != nil {
 		// FIXME(tgulacsi): this results in erroneous short iteration!
 		qr.rset.closeWithRemove()
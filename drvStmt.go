@@ -16,6 +16,8 @@ import (
 // DrvStmt implements the driver.Stmt interface.
 type DrvStmt struct {
 	stmt *Stmt
+	// con is the connection the statement is prepared on
+	con *Con
 }
 
 // checkIsOpen validates that the server is open.
@@ -26,6 +28,17 @@ func (ds *DrvStmt) checkIsOpen() error {
 	return nil
 }
 
+// checkSes returns an error if the connection of the statement is routed
+// to a replica session for a read-only transaction, and the statement
+// is of its primary session: it would run outside of the transaction.
+func (ds *DrvStmt) checkSes() error {
+	con := ds.con
+	if con == nil || con.primary == nil || ds.stmt.ses != con.primary || con.ses.openTxs.len() == 0 {
+		return nil
+	}
+	return er("DrvStmt of the primary session cannot run in a read-only transaction on a replica: prepare it in the transaction.")
+}
+
 // Close closes the SQL statement.
 //
 // Close is a member of the driver.Stmt interface.
@@ -59,13 +72,16 @@ func (ds *DrvStmt) Exec(values []driver.Value) (driver.Result, error) {
 	if err := ds.checkIsOpen(); err != nil {
 		return nil, errE(err)
 	}
+	if err := ds.checkSes(); err != nil {
+		return nil, errE(err)
+	}
 	params := make([]interface{}, len(values))
 	for n := range values {
 		params[n] = values[n]
 	}
 	rowsAffected, lastInsertId, err := ds.stmt.exe(params, false)
 	if err != nil {
-		return nil, maybeBadConn(err)
+		return nil, ds.stmt.ses.markBadConn(err)
 	}
 	if rowsAffected == 0 {
 		return driver.RowsAffected(0), nil
@@ -82,13 +98,16 @@ func (ds *DrvStmt) Query(values []driver.Value) (driver.Rows, error) {
 	if err := ds.checkIsOpen(); err != nil {
 		return nil, errE(err)
 	}
+	if err := ds.checkSes(); err != nil {
+		return nil, errE(err)
+	}
 	params := make([]interface{}, len(values))
 	for n := range values {
 		params[n] = values[n]
 	}
 	rset, err := ds.stmt.qry(params)
 	if err != nil {
-		return nil, maybeBadConn(err)
+		return nil, ds.stmt.ses.markBadConn(err)
 	}
 	return &DrvQueryResult{rset: rset}, nil
 }
@@ -101,15 +120,23 @@ func (ds *DrvStmt) sysName() string {
 	return ds.stmt.sysName() + fmt.Sprintf("S%v", ds.stmt.id)
 }
 
+// logCfg returns the LogDrvCfg of the Env of the DrvStmt.
+func (ds *DrvStmt) logCfg() LogDrvCfg {
+	if ds == nil {
+		return _drv.Cfg().Log
+	}
+	return ds.stmt.logCfg()
+}
+
 // log writes a message with an DrvStmt system name and caller info.
 func (ds *DrvStmt) log(enabled bool, v ...interface{}) {
-	cfg := _drv.Cfg()
-	if !cfg.Log.IsEnabled(enabled) {
+	Log := ds.logCfg()
+	if !Log.IsEnabled(enabled) {
 		return
 	}
 	if len(v) == 0 {
-		cfg.Log.Logger.Infof("%v %v", ds.sysName(), callInfo(1))
+		Log.Logger.Infof("%v %v", ds.sysName(), callInfo(1))
 	} else {
-		cfg.Log.Logger.Infof("%v %v %v", ds.sysName(), callInfo(1), fmt.Sprint(v...))
+		Log.Logger.Infof("%v %v %v", ds.sysName(), callInfo(1), fmt.Sprint(v...))
 	}
 }
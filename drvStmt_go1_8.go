@@ -12,12 +12,16 @@ import (
 )
 
 // ExecContext enhances the Stmt interface by providing Exec with context.
-// ExecContext must honor the context timeout and return when it is cancelled.
+// ExecContext must honor the context timeout and return when it is cancelled:
+// the call is interrupted by the OCI call timeout, or by Ses.Break.
 func (ds *DrvStmt) ExecContext(ctx context.Context, values []driver.NamedValue) (driver.Result, error) {
 	ds.log(true)
 	if err := ds.checkIsOpen(); err != nil {
 		return nil, errE(err)
 	}
+	if err := ds.checkSes(); err != nil {
+		return nil, errE(err)
+	}
 	params := make([]interface{}, len(values))
 	for n, v := range values {
 		params[n] = v
@@ -26,32 +30,12 @@ func (ds *DrvStmt) ExecContext(ctx context.Context, values []driver.NamedValue)
 		return nil, err
 	}
 
-	done := make(chan struct{})
-	go func() {
-		select {
-		case <-done:
-		case <-ctx.Done():
-			// select again to avoid race condition if both are done
-			select {
-			case <-done:
-			default:
-				if isCanceled(ctx.Err()) {
-					ds.stmt.RLock()
-					ses := ds.stmt.ses
-					ds.stmt.RUnlock()
-					ses.Break()
-				}
-			}
-		}
-	}()
-
 	var err error
 	var res DrvExecResult
 	res.rowsAffected, res.lastInsertId, err = ds.stmt.exeC(ctx, params, false)
-	close(done)
 
 	if err != nil {
-		return nil, maybeBadConn(err)
+		return nil, ds.stmt.ses.markBadConn(err)
 	}
 	if res.rowsAffected == 0 {
 		return driver.RowsAffected(0), nil
@@ -60,12 +44,16 @@ func (ds *DrvStmt) ExecContext(ctx context.Context, values []driver.NamedValue)
 }
 
 // QueryContext enhances the Stmt interface by providing Query with context.
-// QueryContext must honor the context timeout and return when it is cancelled.
+// QueryContext must honor the context timeout and return when it is cancelled:
+// the call is interrupted by the OCI call timeout, or by Ses.Break.
 func (ds *DrvStmt) QueryContext(ctx context.Context, values []driver.NamedValue) (driver.Rows, error) {
 	ds.log(true)
 	if err := ds.checkIsOpen(); err != nil {
 		return nil, errE(err)
 	}
+	if err := ds.checkSes(); err != nil {
+		return nil, errE(err)
+	}
 	params := make([]interface{}, len(values))
 	for n, v := range values {
 		params[n] = v
@@ -73,32 +61,12 @@ func (ds *DrvStmt) QueryContext(ctx context.Context, values []driver.NamedValue)
 	if err := ctx.Err(); err != nil {
 		return nil, err
 	}
-	done := make(chan struct{})
-	go func() {
-		select {
-		case <-done:
-		case <-ctx.Done():
-			// select again to avoid race condition if both are done
-			select {
-			case <-done:
-			default:
-				if isCanceled(ctx.Err()) {
-					ds.stmt.RLock()
-					ses := ds.stmt.ses
-					ds.stmt.RUnlock()
-					ses.Break()
-				}
-			}
-		}
-	}()
-
 	rset, err := ds.stmt.qryC(ctx, params)
-	close(done)
 
 	if err != nil {
-		return nil, maybeBadConn(err)
+		return nil, ds.stmt.ses.markBadConn(err)
 	}
-	return &DrvQueryResult{rset: rset}, nil
+	return &DrvQueryResult{rset: rset, ctx: ctx}, nil
 }
 
 // vim: set fileencoding=utf-8 noet:
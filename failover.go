@@ -0,0 +1,314 @@
+// Copyright 2014 Rana Ian. All rights reserved.
+// Use of this source code is governed by The MIT License
+// found in the accompanying LICENSE file.
+
+package ora
+
+import (
+	"sync"
+	"time"
+)
+
+// PoolTarget is a connect target of a Pool, see Pool.SetFailover.
+type PoolTarget struct {
+	// Dblink is the connect string of the target, as SrvCfg.Dblink.
+	Dblink string
+
+	// Weight is the share of the new connections of the target with RoundRobin,
+	// relative to the other targets. If Weight <= 0, then 1 is used.
+	Weight int
+}
+
+// TargetPreference orders the connect targets of a Pool.
+type TargetPreference uint8
+
+const (
+	// PreferPrimary connects to the first available target of the list,
+	// failing back to it as its circuit closes again.
+	PreferPrimary = TargetPreference(0)
+	// RoundRobin distributes the new connections among the available targets,
+	// by their Weight.
+	RoundRobin = TargetPreference(1)
+)
+
+const (
+	DefaultFailureThreshold = 1
+	DefaultOpenDuration     = 30 * time.Second
+)
+
+// ErrNoTarget is returned by Pool.Get when the circuits of all the connect
+// targets are open. It is retryable.
+var ErrNoTarget = &ErrorClass{name: "no available connect target", retryable: true}
+
+// FailoverCfg configures the connect targets of a Pool, see Pool.SetFailover.
+//
+// Each target has a circuit breaker: after FailureThreshold consecutive failures
+// to connect with a transient error (IsRetryable), its circuit opens, and the
+// target is skipped without waiting for a connect timeout. After OpenDuration,
+// a new connection tries the target again (half-open): its success closes the
+// circuit, its failure opens it again.
+type FailoverCfg struct {
+	// Targets are the connect targets, in the order of preference.
+	Targets []PoolTarget
+
+	// Preference orders the targets of the new connections.
+	Preference TargetPreference
+
+	// FailureThreshold is the number of consecutive failures which opens the
+	// circuit of a target. If FailureThreshold <= 0, then DefaultFailureThreshold is used.
+	FailureThreshold int
+
+	// OpenDuration is the time the circuit of a target stays open, before a new
+	// connection tries it again. If OpenDuration <= 0, then DefaultOpenDuration is used.
+	OpenDuration time.Duration
+
+	// ProbeInterval makes the pool probe the targets with an open circuit this often,
+	// in the background, closing the circuit of the healthy ones.
+	//
+	// Zero disables the background probes.
+	ProbeInterval time.Duration
+
+	// Probe checks the health of the target. If nil, a connection is opened to it, and closed.
+	Probe func(dblink string) error
+}
+
+// CircuitState is the state of the circuit breaker of a connect target.
+type CircuitState uint8
+
+const (
+	// CircuitClosed is a healthy target.
+	CircuitClosed = CircuitState(0)
+	// CircuitOpen is a failed target, skipped by the new connections.
+	CircuitOpen = CircuitState(1)
+	// CircuitHalfOpen is a failed target tried again by a new connection.
+	CircuitHalfOpen = CircuitState(2)
+)
+
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	}
+	return "unknown"
+}
+
+// TargetState is the state of a connect target of a Pool, see Pool.Targets.
+type TargetState struct {
+	PoolTarget
+	Circuit  CircuitState
+	Failures int   // number of consecutive failures
+	Err      error // error of the last failure
+}
+
+// poolTarget is a connect target with its circuit breaker.
+type poolTarget struct {
+	TargetState
+	openedAt time.Time
+	// cw is the current weight of the smooth weighted round robin
+	cw int
+}
+
+func (t *poolTarget) weight() int {
+	if t.Weight <= 0 {
+		return 1
+	}
+	return t.Weight
+}
+
+// targetSet are the connect targets of a Pool.
+type targetSet struct {
+	sync.Mutex
+	cfg     FailoverCfg
+	targets []*poolTarget
+	current string
+	now     func() time.Time
+	stop    chan struct{}
+}
+
+func newTargetSet(cfg FailoverCfg) (*targetSet, error) {
+	if len(cfg.Targets) == 0 {
+		return nil, errNew("FailoverCfg.Targets may not be empty")
+	}
+	if cfg.FailureThreshold <= 0 {
+		cfg.FailureThreshold = DefaultFailureThreshold
+	}
+	if cfg.OpenDuration <= 0 {
+		cfg.OpenDuration = DefaultOpenDuration
+	}
+	ts := &targetSet{cfg: cfg, now: time.Now}
+	for _, t := range cfg.Targets {
+		if t.Dblink == "" {
+			return nil, errNew("FailoverCfg.Targets may not have an empty Dblink")
+		}
+		ts.targets = append(ts.targets, &poolTarget{TargetState: TargetState{PoolTarget: t}})
+	}
+	return ts, nil
+}
+
+// candidates returns the targets to be tried by a new connection, in order:
+// the ones with a closed circuit, or with an open one for OpenDuration.
+func (ts *targetSet) candidates() []*poolTarget {
+	ts.Lock()
+	defer ts.Unlock()
+	now := ts.now()
+	cands := make([]*poolTarget, 0, len(ts.targets))
+	for _, t := range ts.targets {
+		switch t.Circuit {
+		case CircuitClosed:
+		case CircuitOpen:
+			if now.Sub(t.openedAt) < ts.cfg.OpenDuration {
+				continue
+			}
+		default: // half-open: being tried by another connection
+			continue
+		}
+		cands = append(cands, t)
+	}
+	if ts.cfg.Preference == RoundRobin && len(cands) > 1 {
+		// smooth weighted round robin picks the first,
+		// the others follow in order, to fail over to
+		var total, best int
+		for i, t := range cands {
+			t.cw += t.weight()
+			total += t.weight()
+			if t.cw > cands[best].cw {
+				best = i
+			}
+		}
+		first := cands[best]
+		first.cw -= total
+		copy(cands[1:best+1], cands[:best])
+		cands[0] = first
+	}
+	return cands
+}
+
+// acquire reports whether t may be tried now, half-opening its open circuit.
+func (ts *targetSet) acquire(t *poolTarget) bool {
+	ts.Lock()
+	defer ts.Unlock()
+	switch t.Circuit {
+	case CircuitClosed:
+		return true
+	case CircuitOpen:
+		if ts.now().Sub(t.openedAt) >= ts.cfg.OpenDuration {
+			t.Circuit = CircuitHalfOpen
+			return true
+		}
+	}
+	return false
+}
+
+// report records the result of connecting to t.
+func (ts *targetSet) report(t *poolTarget, err error) {
+	ts.Lock()
+	defer ts.Unlock()
+	if err == nil {
+		t.Circuit, t.Failures, t.Err = CircuitClosed, 0, nil
+		ts.current = t.Dblink
+		return
+	}
+	if !IsRetryable(err) {
+		// not a failure of the target, as a wrong password
+		if t.Circuit == CircuitHalfOpen {
+			t.Circuit = CircuitClosed
+		}
+		return
+	}
+	t.Failures++
+	t.Err = err
+	if t.Circuit == CircuitHalfOpen || t.Failures >= ts.cfg.FailureThreshold {
+		t.Circuit, t.openedAt = CircuitOpen, ts.now()
+	}
+}
+
+// connect calls open with the candidate targets in order, till one succeeds.
+// It returns the error of the last target tried, ErrNoTarget if none could be.
+func (ts *targetSet) connect(open func(dblink string) error) error {
+	err := error(ErrNoTarget)
+	for _, t := range ts.candidates() {
+		if !ts.acquire(t) {
+			continue
+		}
+		err = open(t.Dblink)
+		ts.report(t, err)
+		if err == nil || !IsRetryable(err) {
+			return err
+		}
+	}
+	return err
+}
+
+// probe checks the health of the targets with an open circuit, with probe.
+func (ts *targetSet) probe(probe func(dblink string) error) {
+	ts.Lock()
+	var probed []*poolTarget
+	for _, t := range ts.targets {
+		if t.Circuit == CircuitOpen {
+			t.Circuit = CircuitHalfOpen
+			probed = append(probed, t)
+		}
+	}
+	ts.Unlock()
+	for _, t := range probed {
+		ts.report(t, probe(t.Dblink))
+	}
+}
+
+// start starts the background probes of the ProbeInterval, with probe.
+func (ts *targetSet) start(probe func(dblink string) error) {
+	if ts.cfg.ProbeInterval <= 0 {
+		return
+	}
+	if ts.cfg.Probe != nil {
+		probe = ts.cfg.Probe
+	}
+	ts.stop = make(chan struct{})
+	go func(stop <-chan struct{}) {
+		ticker := time.NewTicker(ts.cfg.ProbeInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				ts.probe(probe)
+			}
+		}
+	}(ts.stop)
+}
+
+// close stops the background probes.
+func (ts *targetSet) close() {
+	if ts == nil {
+		return
+	}
+	ts.Lock()
+	defer ts.Unlock()
+	if ts.stop != nil {
+		close(ts.stop)
+		ts.stop = nil
+	}
+}
+
+// currentTarget returns the Dblink of the target connected last.
+func (ts *targetSet) currentTarget() string {
+	ts.Lock()
+	defer ts.Unlock()
+	return ts.current
+}
+
+// states returns the states of the targets.
+func (ts *targetSet) states() []TargetState {
+	ts.Lock()
+	defer ts.Unlock()
+	states := make([]TargetState, len(ts.targets))
+	for i, t := range ts.targets {
+		states[i] = t.TargetState
+	}
+	return states
+}
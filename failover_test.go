@@ -0,0 +1,148 @@
+// Copyright 2014 Rana Ian. All rights reserved.
+// Use of this source code is governed by The MIT License
+// found in the accompanying LICENSE file.
+
+package ora
+
+import (
+	"testing"
+	"time"
+)
+
+// fakeTargets opens the connections of a targetSet, failing for the down ones.
+type fakeTargets struct {
+	down   map[string]error
+	opened []string
+}
+
+func (f *fakeTargets) open(dblink string) error {
+	f.opened = append(f.opened, dblink)
+	return f.down[dblink]
+}
+
+func newTestTargetSet(t *testing.T, cfg FailoverCfg) (*targetSet, *time.Time) {
+	ts, err := newTargetSet(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC)
+	ts.now = func() time.Time { return now }
+	return ts, &now
+}
+
+func TestTargetSetPreferPrimary(t *testing.T) {
+	ts, now := newTestTargetSet(t, FailoverCfg{
+		Targets:      []PoolTarget{{Dblink: "primary"}, {Dblink: "standby"}},
+		OpenDuration: time.Minute,
+	})
+	f := &fakeTargets{down: map[string]error{"primary": &ORAError{code: 12541}}}
+
+	if err := ts.connect(f.open); err != nil || ts.currentTarget() != "standby" {
+		t.Fatalf("got %v, target %q", err, ts.currentTarget())
+	}
+	if st := ts.states(); st[0].Circuit != CircuitOpen || st[0].Failures != 1 || ErrorCode(st[0].Err) != 12541 {
+		t.Errorf("got %+v, wanted an open circuit", st[0])
+	}
+	// the open circuit is skipped
+	f.opened = nil
+	if err := ts.connect(f.open); err != nil || len(f.opened) != 1 || f.opened[0] != "standby" {
+		t.Errorf("got %v, opened %q", err, f.opened)
+	}
+
+	// fail back after OpenDuration
+	delete(f.down, "primary")
+	*now = now.Add(time.Minute)
+	f.opened = nil
+	if err := ts.connect(f.open); err != nil || ts.currentTarget() != "primary" {
+		t.Errorf("got %v, target %q, opened %q", err, ts.currentTarget(), f.opened)
+	}
+	if st := ts.states(); st[0].Circuit != CircuitClosed || st[0].Failures != 0 {
+		t.Errorf("got %+v, wanted a closed circuit", st[0])
+	}
+
+	// all down
+	f.down["primary"], f.down["standby"] = &ORAError{code: 12514}, &ORAError{code: 12541}
+	if err := ts.connect(f.open); ErrorCode(err) != 12541 {
+		t.Errorf("got %v, wanted the error of the last target", err)
+	}
+	f.opened = nil
+	if err := ts.connect(f.open); err != ErrNoTarget || len(f.opened) != 0 || !IsRetryable(err) {
+		t.Errorf("got %v, opened %q", err, f.opened)
+	}
+}
+
+func TestTargetSetRoundRobin(t *testing.T) {
+	ts, _ := newTestTargetSet(t, FailoverCfg{
+		Targets:    []PoolTarget{{Dblink: "a", Weight: 2}, {Dblink: "b"}},
+		Preference: RoundRobin,
+	})
+	f := &fakeTargets{}
+	for i := 0; i < 6; i++ {
+		if err := ts.connect(f.open); err != nil {
+			t.Fatal(err)
+		}
+	}
+	n := make(map[string]int)
+	for _, dblink := range f.opened {
+		n[dblink]++
+	}
+	if n["a"] != 4 || n["b"] != 2 {
+		t.Errorf("got %q, wanted 4 a and 2 b", f.opened)
+	}
+
+	// fail over to the next one
+	f.down = map[string]error{"a": &ORAError{code: 3113}}
+	f.opened = nil
+	for i := 0; i < 3; i++ {
+		if err := ts.connect(f.open); err != nil || ts.currentTarget() != "b" {
+			t.Fatalf("got %v, target %q", err, ts.currentTarget())
+		}
+	}
+	if len(f.opened) > 4 {
+		t.Errorf("got %q, wanted a tried once", f.opened)
+	}
+}
+
+func TestTargetSetBreaker(t *testing.T) {
+	ts, now := newTestTargetSet(t, FailoverCfg{
+		Targets:          []PoolTarget{{Dblink: "a"}, {Dblink: "b"}},
+		FailureThreshold: 2,
+	})
+	f := &fakeTargets{down: map[string]error{"a": &ORAError{code: 12170}}}
+	ts.connect(f.open)
+	if st := ts.states(); st[0].Circuit != CircuitClosed {
+		t.Errorf("got %v after 1 failure, wanted closed", st[0].Circuit)
+	}
+	ts.connect(f.open)
+	if st := ts.states(); st[0].Circuit != CircuitOpen {
+		t.Errorf("got %v after 2 failures, wanted open", st[0].Circuit)
+	}
+
+	// half-open: a failure opens the circuit again
+	*now = now.Add(DefaultOpenDuration)
+	f.opened = nil
+	ts.connect(f.open)
+	if st := ts.states(); st[0].Circuit != CircuitOpen || len(f.opened) != 2 {
+		t.Errorf("got %v, opened %q", st[0].Circuit, f.opened)
+	}
+
+	// a probe closes the circuit of a healthy target
+	ts.probe(func(string) error { return nil })
+	if st := ts.states(); st[0].Circuit != CircuitClosed {
+		t.Errorf("got %v after the probe, wanted closed", st[0].Circuit)
+	}
+
+	// not a failure of the target: no failover
+	f.down["a"] = &ORAError{code: 1017}
+	f.opened = nil
+	if err := ts.connect(f.open); ErrorCode(err) != 1017 || len(f.opened) != 1 {
+		t.Errorf("got %v, opened %q", err, f.opened)
+	}
+	if st := ts.states(); st[0].Circuit != CircuitClosed {
+		t.Errorf("got %v, wanted closed", st[0].Circuit)
+	}
+
+	if _, err := newTargetSet(FailoverCfg{}); err == nil {
+		t.Error("no targets: wanted an error")
+	}
+}
@@ -45,6 +45,16 @@ func (l *envList) len() int {
 	return len(l.items)
 }
 
+// all returns a copy of the items.
+func (l *envList) all() []*Env {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]*Env(nil), l.items...)
+}
+
 ////////////////////////////////////////////////////////////////////////////////
 // srvList
 ////////////////////////////////////////////////////////////////////////////////
@@ -99,6 +109,16 @@ func (l *srvList) len() int {
 	return len(l.items)
 }
 
+// all returns a copy of the items.
+func (l *srvList) all() []*Srv {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]*Srv(nil), l.items...)
+}
+
 ////////////////////////////////////////////////////////////////////////////////
 // conList
 ////////////////////////////////////////////////////////////////////////////////
@@ -207,6 +227,16 @@ func (l *sesList) len() int {
 	return len(l.items)
 }
 
+// all returns a copy of the items.
+func (l *sesList) all() []*Ses {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]*Ses(nil), l.items...)
+}
+
 ////////////////////////////////////////////////////////////////////////////////
 // txList
 ////////////////////////////////////////////////////////////////////////////////
@@ -261,6 +291,15 @@ func (l *txList) len() int {
 	return len(l.items)
 }
 
+func (l *txList) all() []*Tx {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]*Tx(nil), l.items...)
+}
+
 ////////////////////////////////////////////////////////////////////////////////
 // stmtList
 ////////////////////////////////////////////////////////////////////////////////
@@ -315,6 +354,16 @@ func (l *stmtList) len() int {
 	return len(l.items)
 }
 
+// all returns a copy of the items.
+func (l *stmtList) all() []*Stmt {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]*Stmt(nil), l.items...)
+}
+
 ////////////////////////////////////////////////////////////////////////////////
 // rsetList
 ////////////////////////////////////////////////////////////////////////////////
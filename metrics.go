@@ -0,0 +1,87 @@
+// Copyright 2014 Rana Ian. All rights reserved.
+// Use of this source code is governed by The MIT License
+// found in the accompanying LICENSE file.
+
+package ora
+
+import "sync"
+
+// Metrics is a snapshot of the open objects of the driver,
+// and of the state of the registered pools.
+type Metrics struct {
+	NumEnv  int // number of open Env
+	NumSrv  int // number of open Srv
+	NumSes  int // number of open Ses
+	NumStmt int // number of open Stmt
+	NumRset int // number of open Rset
+
+	// Pools are the PoolStats of the registered pools, by name.
+	Pools map[string]PoolStats
+}
+
+// PoolStatser is implemented by Pool, SrvPool, SesPool and BoundedPool.
+type PoolStatser interface {
+	Stats() PoolStats
+}
+
+var registeredPools = struct {
+	sync.Mutex
+	m map[string]PoolStatser
+}{m: make(map[string]PoolStatser)}
+
+// RegisterPool registers the pool under name, to be reported by CollectMetrics.
+// A pool registered previously with the same name is replaced.
+func RegisterPool(name string, pool PoolStatser) {
+	registeredPools.Lock()
+	registeredPools.m[name] = pool
+	registeredPools.Unlock()
+}
+
+// UnregisterPool removes the pool registered under name.
+func UnregisterPool(name string) {
+	registeredPools.Lock()
+	delete(registeredPools.m, name)
+	registeredPools.Unlock()
+}
+
+// CollectMetrics returns the number of the open Env, Srv, Ses, Stmt and Rset,
+// and the PoolStats of the registered pools.
+func CollectMetrics() Metrics {
+	var m Metrics
+	_drv.RLock()
+	envs := _drv.openEnvs.all()
+	_drv.RUnlock()
+	m.NumEnv = len(envs)
+	for _, env := range envs {
+		env.RLock()
+		openSrvs := env.openSrvs
+		env.RUnlock()
+		srvs := openSrvs.all()
+		m.NumSrv += len(srvs)
+		for _, srv := range srvs {
+			srv.RLock()
+			openSess := srv.openSess
+			srv.RUnlock()
+			sess := openSess.all()
+			m.NumSes += len(sess)
+			for _, ses := range sess {
+				ses.RLock()
+				openStmts := ses.openStmts
+				ses.RUnlock()
+				stmts := openStmts.all()
+				m.NumStmt += len(stmts)
+				for _, stmt := range stmts {
+					m.NumRset += stmt.NumRset()
+				}
+			}
+		}
+	}
+
+	registeredPools.Lock()
+	m.Pools = make(map[string]PoolStats, len(registeredPools.m))
+	for name, pool := range registeredPools.m {
+		m.Pools[name] = pool.Stats()
+	}
+	registeredPools.Unlock()
+	return m
+}
@@ -0,0 +1,132 @@
+// Copyright 2014 Rana Ian. All rights reserved.
+// Use of this source code is governed by The MIT License
+// found in the accompanying LICENSE file.
+
+// Package metrics publishes the metrics of the ora driver and its registered pools
+// (see ora.CollectMetrics and ora.RegisterPool) with expvar,
+// and as an http.Handler in the Prometheus text format.
+//
+// It is a separate package, as importing expvar registers
+// the /debug/vars handler on http.DefaultServeMux.
+package metrics
+
+import (
+	"bytes"
+	"expvar"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/rana/ora.v4"
+)
+
+// PublishExpvar publishes ora.CollectMetrics with expvar, under name.
+// As expvar.Publish, it panics if name is already published.
+func PublishExpvar(name string) {
+	expvar.Publish(name, expvar.Func(func() interface{} { return ora.CollectMetrics() }))
+}
+
+// Handler returns an http.Handler which writes ora.CollectMetrics
+// in the Prometheus text format.
+func Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		WriteText(w, ora.CollectMetrics())
+	})
+}
+
+type family struct {
+	name, typ, help string
+}
+
+var drvFamilies = []struct {
+	family
+	value func(ora.Metrics) int
+}{
+	{family{"ora_envs", "gauge", "Number of open environments."}, func(m ora.Metrics) int { return m.NumEnv }},
+	{family{"ora_servers", "gauge", "Number of open server connections."}, func(m ora.Metrics) int { return m.NumSrv }},
+	{family{"ora_sessions", "gauge", "Number of open sessions."}, func(m ora.Metrics) int { return m.NumSes }},
+	{family{"ora_statements", "gauge", "Number of open statements."}, func(m ora.Metrics) int { return m.NumStmt }},
+	{family{"ora_resultsets", "gauge", "Number of open result sets."}, func(m ora.Metrics) int { return m.NumRset }},
+}
+
+var poolFamilies = []struct {
+	family
+	value func(ora.PoolStats) float64
+}{
+	{family{"ora_pool_max_active", "gauge", "Limit of the open sessions, 0 if unlimited."},
+		func(s ora.PoolStats) float64 { return float64(s.MaxActive) }},
+	{family{"ora_pool_active", "gauge", "Number of open sessions, idle or in use."},
+		func(s ora.PoolStats) float64 { return float64(s.Active) }},
+	{family{"ora_pool_idle", "gauge", "Number of idle sessions."},
+		func(s ora.PoolStats) float64 { return float64(s.Idle) }},
+	{family{"ora_pool_in_use", "gauge", "Number of sessions in use."},
+		func(s ora.PoolStats) float64 { return float64(s.InUse) }},
+	{family{"ora_pool_waiting", "gauge", "Number of Get calls waiting for a session."},
+		func(s ora.PoolStats) float64 { return float64(s.Waiting) }},
+	{family{"ora_pool_hits_total", "counter", "Get calls served by an idle session."},
+		func(s ora.PoolStats) float64 { return float64(s.Hits) }},
+	{family{"ora_pool_misses_total", "counter", "Get calls which had to open a new session."},
+		func(s ora.PoolStats) float64 { return float64(s.Misses) }},
+	{family{"ora_pool_creations_total", "counter", "Sessions opened by the pool."},
+		func(s ora.PoolStats) float64 { return float64(s.Creations) }},
+	{family{"ora_pool_evictions_total", "counter", "Sessions closed by the pool."},
+		func(s ora.PoolStats) float64 { return float64(s.Evictions) }},
+	{family{"ora_pool_waits_total", "counter", "Get calls which had to wait for a session."},
+		func(s ora.PoolStats) float64 { return float64(s.WaitCount) }},
+	{family{"ora_pool_wait_seconds_total", "counter", "Time spent waiting in Get."},
+		func(s ora.PoolStats) float64 { return float64(s.WaitDuration) / float64(time.Second) }},
+	{family{"ora_pool_timeouts_total", "counter", "Waiting Get calls given up as their context is done."},
+		func(s ora.PoolStats) float64 { return float64(s.Timeouts) }},
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// WriteText writes m in the Prometheus text exposition format.
+func WriteText(w io.Writer, m ora.Metrics) error {
+	var buf bytes.Buffer
+	for _, f := range drvFamilies {
+		f.writeHeader(&buf)
+		fmt.Fprintf(&buf, "%s %d\n", f.name, f.value(m))
+	}
+	names := make([]string, 0, len(m.Pools))
+	for name := range m.Pools {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, f := range poolFamilies {
+		if len(names) == 0 {
+			break
+		}
+		f.writeHeader(&buf)
+		for _, name := range names {
+			fmt.Fprintf(&buf, "%s{pool=\"%s\"} %s\n",
+				f.name, labelEscaper.Replace(name),
+				strconv.FormatFloat(f.value(m.Pools[name]), 'f', -1, 64))
+		}
+	}
+	// the connect target of the pools with failover, as a label
+	header := false
+	for _, name := range names {
+		target := m.Pools[name].Target
+		if target == "" {
+			continue
+		}
+		if !header {
+			family{"ora_pool_target", "gauge", "Connect target of the last new connection of the pool."}.writeHeader(&buf)
+			header = true
+		}
+		fmt.Fprintf(&buf, "ora_pool_target{pool=\"%s\",target=\"%s\"} 1\n",
+			labelEscaper.Replace(name), labelEscaper.Replace(target))
+	}
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+func (f family) writeHeader(buf *bytes.Buffer) {
+	fmt.Fprintf(buf, "# HELP %s %s\n# TYPE %s %s\n", f.name, f.help, f.name, f.typ)
+}
@@ -0,0 +1,52 @@
+// Copyright 2014 Rana Ian. All rights reserved.
+// Use of this source code is governed by The MIT License
+// found in the accompanying LICENSE file.
+
+package metrics
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"gopkg.in/rana/ora.v4"
+)
+
+func TestWriteText(t *testing.T) {
+	m := ora.Metrics{
+		NumEnv: 1, NumSrv: 2, NumSes: 3, NumStmt: 4, NumRset: 5,
+		Pools: map[string]ora.PoolStats{
+			"b":          {Active: 2, Idle: 1, InUse: 1, Hits: 10, Misses: 2, Creations: 2, Target: "standby"},
+			`a"\` + "\n": {MaxActive: 4, WaitDuration: 1500 * time.Millisecond, Timeouts: 1},
+		},
+	}
+	var buf bytes.Buffer
+	if err := WriteText(&buf, m); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	t.Log(out)
+	for _, want := range []string{
+		"# TYPE ora_envs gauge\nora_envs 1\n",
+		"ora_resultsets 5\n",
+		"# HELP ora_pool_hits_total Get calls served by an idle session.\n# TYPE ora_pool_hits_total counter\n",
+		"ora_pool_max_active{pool=\"a\\\"\\\\\\n\"} 4\nora_pool_max_active{pool=\"b\"} 0\n",
+		"ora_pool_hits_total{pool=\"b\"} 10\n",
+		"ora_pool_wait_seconds_total{pool=\"a\\\"\\\\\\n\"} 1.5\n",
+		"ora_pool_timeouts_total{pool=\"a\\\"\\\\\\n\"} 1\n",
+		"# TYPE ora_pool_target gauge\nora_pool_target{pool=\"b\",target=\"standby\"} 1\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("%q is missing", want)
+		}
+	}
+
+	buf.Reset()
+	if err := WriteText(&buf, ora.Metrics{}); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(buf.String(), "ora_pool") {
+		t.Errorf("pool families without pools:\n%s", buf.String())
+	}
+}
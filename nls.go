@@ -0,0 +1,160 @@
+// Copyright 2014 Rana Ian. All rights reserved.
+// Use of this source code is governed by The MIT License
+// found in the accompanying LICENSE file.
+
+package ora
+
+import (
+	"bytes"
+	"fmt"
+	"hash/fnv"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// NLS are the ALTER SESSION settings of a session, set once per new session
+// by Srv.OpenSes. The empty ones are left as the database default.
+type NLS struct {
+	Language          string // NLS_LANGUAGE
+	Territory         string // NLS_TERRITORY
+	DateFormat        string // NLS_DATE_FORMAT, as YYYY-MM-DD HH24:MI:SS
+	TimestampFormat   string // NLS_TIMESTAMP_FORMAT
+	TimestampTZFormat string // NLS_TIMESTAMP_TZ_FORMAT
+	NumericCharacters string // NLS_NUMERIC_CHARACTERS, the decimal and group separators, as .,
+	Sort              string // NLS_SORT
+	Comp              string // NLS_COMP
+	// TimeZone is the TIME_ZONE of the session, as +02:00, Europe/Budapest, LOCAL or DBTIMEZONE.
+	TimeZone string
+	// CurrentSchema is the CURRENT_SCHEMA of the session: a simple SQL identifier,
+	// as HR, or a quoted one, as "Hr".
+	CurrentSchema string
+}
+
+// IsZero reports whether none of the settings is set.
+func (n NLS) IsZero() bool { return n == NLS{} }
+
+// check returns an error if a setting cannot be part of the ALTER SESSION statement:
+// the values are quoted, but the CurrentSchema must be an identifier.
+func (n NLS) check() error {
+	s := n.CurrentSchema
+	if s == "" {
+		return nil
+	}
+	if len(s) > 2 && s[0] == '"' && s[len(s)-1] == '"' {
+		if name := s[1 : len(s)-1]; len(name) <= 128 && !strings.ContainsAny(name, "\"\x00") {
+			return nil
+		}
+		return errF("invalid CURRENT_SCHEMA %q", s)
+	}
+	return checkIdentifier("CURRENT_SCHEMA", s)
+}
+
+// checkIdentifier returns an error if name, the what of a statement,
+// is not a simple (unquoted) SQL identifier.
+func checkIdentifier(what, name string) error {
+	if name == "" || len(name) > 128 {
+		return errF("invalid %s %q", what, name)
+	}
+	for i, r := range name {
+		switch {
+		case 'a' <= r && r <= 'z', 'A' <= r && r <= 'Z':
+		case i > 0 && ('0' <= r && r <= '9' || r == '_' || r == '$' || r == '#'):
+		default:
+			return errF("invalid %s %q", what, name)
+		}
+	}
+	return nil
+}
+
+// alterSession returns the ALTER SESSION statement of the settings,
+// or the empty string if none is set. The settings must be checked.
+func (n NLS) alterSession() string {
+	var buf bytes.Buffer
+	for _, kv := range []struct {
+		key, value string
+	}{
+		// NLS_LANGUAGE and NLS_TERRITORY first, as they reset the others
+		{"NLS_LANGUAGE", n.Language},
+		{"NLS_TERRITORY", n.Territory},
+		{"NLS_DATE_FORMAT", n.DateFormat},
+		{"NLS_TIMESTAMP_FORMAT", n.TimestampFormat},
+		{"NLS_TIMESTAMP_TZ_FORMAT", n.TimestampTZFormat},
+		{"NLS_NUMERIC_CHARACTERS", n.NumericCharacters},
+		{"NLS_SORT", n.Sort},
+		{"NLS_COMP", n.Comp},
+		{"TIME_ZONE", n.TimeZone},
+	} {
+		if kv.value != "" {
+			fmt.Fprintf(&buf, " %s='%s'", kv.key, strings.Replace(kv.value, "'", "''", -1))
+		}
+	}
+	if n.CurrentSchema != "" {
+		buf.WriteString(" CURRENT_SCHEMA=")
+		buf.WriteString(n.CurrentSchema)
+	}
+	if buf.Len() == 0 {
+		return ""
+	}
+	return "ALTER SESSION SET" + buf.String()
+}
+
+// location returns the location of TimeZone, nil if it is not set,
+// or it is not an offset or a location name known by Go, as LOCAL.
+func (n NLS) location() *time.Location {
+	tz := strings.TrimSpace(n.TimeZone)
+	if tz == "" {
+		return nil
+	}
+	if tz[0] == '+' || tz[0] == '-' {
+		i := strings.IndexByte(tz, ':')
+		if i < 0 {
+			return nil
+		}
+		hour, err := strconv.Atoi(tz[1:i])
+		if err != nil {
+			return nil
+		}
+		min, err := strconv.Atoi(tz[i+1:])
+		if err != nil {
+			return nil
+		}
+		offset := hour*3600 + min*60
+		if tz[0] == '-' {
+			offset = -offset
+		}
+		return time.FixedZone(tz, offset)
+	}
+	if strings.EqualFold(tz, "LOCAL") || strings.EqualFold(tz, "DBTIMEZONE") {
+		return nil
+	}
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		return nil
+	}
+	return loc
+}
+
+// initStmts returns the statements initializing a new session of the SesCfg.
+func (c SesCfg) initStmts() []string {
+	var stmts []string
+	if qry := c.NLS.alterSession(); qry != "" {
+		stmts = append(stmts, qry)
+	}
+	return append(stmts, c.Init...)
+}
+
+// initTag returns the session pool tag of the initialized sessions of the SesCfg,
+// or the empty string if there is nothing to initialize.
+func (c SesCfg) initTag() string {
+	stmts := c.initStmts()
+	if len(stmts) == 0 {
+		return ""
+	}
+	h := fnv.New64a()
+	for _, qry := range stmts {
+		h.Write([]byte(qry))
+		h.Write([]byte{0})
+	}
+	return fmt.Sprintf("ora=%x", h.Sum64())
+}
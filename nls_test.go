@@ -0,0 +1,73 @@
+// Copyright 2014 Rana Ian. All rights reserved.
+// Use of this source code is governed by The MIT License
+// found in the accompanying LICENSE file.
+
+package ora
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNLS(t *testing.T) {
+	for i, tc := range []struct {
+		nls  NLS
+		qry  string
+		zone string
+		off  int
+	}{
+		{NLS{}, "", "", 0},
+		{NLS{DateFormat: "YYYY-MM-DD", NumericCharacters: ".,", TimeZone: "+02:00", Territory: "HUNGARY"},
+			"ALTER SESSION SET NLS_TERRITORY='HUNGARY' NLS_DATE_FORMAT='YYYY-MM-DD' NLS_NUMERIC_CHARACTERS='.,' TIME_ZONE='+02:00'",
+			"+02:00", 7200},
+		{NLS{DateFormat: `"It''s" DD`, TimeZone: "-05:30", CurrentSchema: "HR"},
+			`ALTER SESSION SET NLS_DATE_FORMAT='"It''''s" DD' TIME_ZONE='-05:30' CURRENT_SCHEMA=HR`,
+			"-05:30", -19800},
+		{NLS{TimeZone: "UTC"}, "ALTER SESSION SET TIME_ZONE='UTC'", "UTC", 0},
+		{NLS{TimeZone: "LOCAL"}, "ALTER SESSION SET TIME_ZONE='LOCAL'", "", 0},
+		{NLS{CurrentSchema: `"Hr$1"`}, `ALTER SESSION SET CURRENT_SCHEMA="Hr$1"`, "", 0},
+	} {
+		if err := tc.nls.check(); err != nil {
+			t.Errorf("%d. %v", i, err)
+		}
+		if qry := tc.nls.alterSession(); qry != tc.qry {
+			t.Errorf("%d. got %q, wanted %q", i, qry, tc.qry)
+		}
+		loc := tc.nls.location()
+		if tc.zone == "" {
+			if loc != nil {
+				t.Errorf("%d. got %v, wanted nil", i, loc)
+			}
+			continue
+		}
+		if loc == nil {
+			t.Errorf("%d. got nil location, wanted %s", i, tc.zone)
+			continue
+		}
+		if name, off := time.Date(2017, 1, 1, 0, 0, 0, 0, loc).Zone(); off != tc.off || (name != tc.zone && tc.zone != "UTC") {
+			t.Errorf("%d. got %s %d, wanted %s %d", i, name, off, tc.zone, tc.off)
+		}
+	}
+
+	for i, schema := range []string{
+		"HR SQL_TRACE=TRUE", "HR;DROP", "1HR", `"HR" X="Y"`, `"H"R"`, `""`, `"`,
+	} {
+		if err := (NLS{CurrentSchema: schema}).check(); err == nil {
+			t.Errorf("%d. %q: wanted error", i, schema)
+		}
+	}
+	if _, err := ParseDSN("oracle://u:p@h/s?current_schema=HR+SQL_TRACE%3DTRUE"); err == nil {
+		t.Errorf("wanted error for an invalid current_schema")
+	}
+
+	a, b := SesCfg{NLS: NLS{DateFormat: "YYYY"}}, SesCfg{NLS: NLS{DateFormat: "YYYY"}, Init: []string{"BEGIN NULL; END;"}}
+	if (SesCfg{}).initTag() != "" {
+		t.Errorf("got tag for nothing to init")
+	}
+	if a.initTag() == "" || a.initTag() == b.initTag() || a.initTag() != a.initTag() {
+		t.Errorf("got tags %q and %q", a.initTag(), b.initTag())
+	}
+	if stmts := b.initStmts(); len(stmts) != 2 || stmts[1] != b.Init[0] {
+		t.Errorf("got %q", stmts)
+	}
+}
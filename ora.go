@@ -132,7 +132,7 @@ func init() {
 	_drv.defPools[defIdxBool] = newPool(func() interface{} { return &defBool{} })
 	_drv.defPools[defIdxLob] = newPool(func() interface{} { return &defLob{} })
 	_drv.defPools[defIdxRaw] = newPool(func() interface{} { return &defRaw{} })
-	_drv.defPools[defIdxLongRaw] = newPool(func() interface{} { return &defLongRaw{} })
+	_drv.defPools[defIdxLong] = newPool(func() interface{} { return &defLong{} })
 	_drv.defPools[defIdxBfile] = newPool(func() interface{} { return &defBfile{} })
 	_drv.defPools[defIdxIntervalYM] = newPool(func() interface{} { return &defIntervalYM{} })
 	_drv.defPools[defIdxIntervalDS] = newPool(func() interface{} { return &defIntervalDS{} })
@@ -0,0 +1,334 @@
+// Copyright 2014 Rana Ian. All rights reserved.
+// Use of this source code is governed by The MIT License
+// found in the accompanying LICENSE file.
+
+package ora
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// ErrorClass is a class of Oracle errors, by their ORA- codes.
+// An error of the class matches it with errors.Is:
+//
+//	if errors.Is(err, ora.ErrUniqueViolation) {
+//		...
+//	}
+type ErrorClass struct {
+	name      string
+	codes     []int
+	retryable bool
+}
+
+func (c *ErrorClass) Error() string { return "ora: " + c.name }
+
+// Codes returns the ORA- codes of the class.
+func (c *ErrorClass) Codes() []int { return append([]int(nil), c.codes...) }
+
+// Retryable reports whether the failed operation may succeed if retried,
+// in a new transaction or on a new connection.
+func (c *ErrorClass) Retryable() bool { return c.retryable }
+
+func (c *ErrorClass) has(code int) bool {
+	for _, cd := range c.codes {
+		if cd == code {
+			return true
+		}
+	}
+	return false
+}
+
+// The classes of the Oracle errors.
+var (
+	ErrUniqueViolation     = &ErrorClass{name: "unique constraint violated", codes: []int{1}}
+	ErrForeignKeyViolation = &ErrorClass{name: "integrity constraint violated", codes: []int{2291, 2292}}
+	ErrNotNullViolation    = &ErrorClass{name: "NULL not allowed", codes: []int{1400, 1407}}
+	ErrCheckViolation      = &ErrorClass{name: "check constraint violated", codes: []int{2290}}
+	ErrValueTooLarge       = &ErrorClass{name: "value too large", codes: []int{1438, 12899}}
+	ErrNoDataFound         = &ErrorClass{name: "no data found", codes: []int{1403, 100}}
+	ErrTooManyRows         = &ErrorClass{name: "exact fetch returns more than requested number of rows", codes: []int{1422}}
+	ErrTableNotFound       = &ErrorClass{name: "table or view does not exist", codes: []int{942}}
+	ErrInvalidIdentifier   = &ErrorClass{name: "invalid identifier", codes: []int{904}}
+	ErrCanceled            = &ErrorClass{name: "user requested cancel of current operation", codes: []int{1013}}
+
+	ErrDeadlock = &ErrorClass{name: "deadlock detected", codes: []int{60}, retryable: true}
+	// ErrResourceBusy is NOWAIT or WAIT n lock contention.
+	ErrResourceBusy     = &ErrorClass{name: "resource busy", codes: []int{54, 30006}, retryable: true}
+	ErrSerialization    = &ErrorClass{name: "can't serialize access for this transaction", codes: []int{8177}, retryable: true}
+	ErrSnapshotTooOld   = &ErrorClass{name: "snapshot too old", codes: []int{1555}, retryable: true}
+	ErrPackageDiscarded = &ErrorClass{name: "existing state of packages has been discarded",
+		codes: []int{4061, 4065, 4068, 6508}, retryable: true}
+	// ErrTimeout is a timed out call, connection or lock wait; a *TimeoutError, too.
+	ErrTimeout = &ErrorClass{name: "timeout", codes: []int{51, 3136, 3156, 12170}, retryable: true}
+	// ErrSessionKilled is a killed session: the connection is lost.
+	ErrSessionKilled = &ErrorClass{name: "session killed", codes: []int{28, 31, 2396}, retryable: true}
+	// ErrConnectionLost is a broken connection, or an unavailable database.
+	ErrConnectionLost = &ErrorClass{name: "connection lost",
+		codes: []int{1012, 1033, 1034, 1089, 1090, 1092, 3113, 3114, 3135,
+			12514, 12528, 12537, 12541, 12543, 12545, 12547, 12570, 25408, 28547},
+		retryable: true}
+)
+
+// errorClasses are the ErrorClasses, looked up by Classify.
+var errorClasses = []*ErrorClass{
+	ErrUniqueViolation, ErrForeignKeyViolation, ErrNotNullViolation, ErrCheckViolation,
+	ErrValueTooLarge, ErrNoDataFound, ErrTooManyRows, ErrTableNotFound, ErrInvalidIdentifier,
+	ErrCanceled, ErrDeadlock, ErrResourceBusy, ErrSerialization, ErrSnapshotTooOld,
+	ErrPackageDiscarded, ErrTimeout, ErrSessionKilled, ErrConnectionLost,
+}
+
+// Classify returns the ErrorClass of err, nil if it is not classified.
+// A TimeoutError and context.DeadlineExceeded are ErrTimeout,
+// context.Canceled is ErrCanceled.
+func Classify(err error) *ErrorClass {
+	for e := err; e != nil; e = unwrapErr(e) {
+		switch e {
+		case context.DeadlineExceeded:
+			return ErrTimeout
+		case context.Canceled:
+			return ErrCanceled
+		}
+		switch x := e.(type) {
+		case *ErrorClass:
+			return x
+		case *TimeoutError:
+			return ErrTimeout
+		}
+	}
+	if code := ErrorCode(err); code != 0 {
+		return classOf(code)
+	}
+	return nil
+}
+
+// classOf returns the ErrorClass of the ORA- code, nil if it has none.
+func classOf(code int) *ErrorClass {
+	for _, c := range errorClasses {
+		if c.has(code) {
+			return c
+		}
+	}
+	return nil
+}
+
+// ErrorCode returns the ORA- code of err, 0 if it has none.
+func ErrorCode(err error) int {
+	for ; err != nil; err = unwrapErr(err) {
+		if cd, ok := err.(interface {
+			Code() int
+		}); ok {
+			if code := cd.Code(); code != 0 {
+				return code
+			}
+		}
+	}
+	return 0
+}
+
+// IsRetryable reports whether the operation failed with err may succeed if retried,
+// as a deadlock, lock contention, a timeout or a lost connection.
+func IsRetryable(err error) bool {
+	if err == driver.ErrBadConn {
+		return true
+	}
+	c := Classify(err)
+	return c != nil && c.retryable
+}
+
+// IsConnectionLost reports whether err means the connection is not usable anymore.
+func IsConnectionLost(err error) bool {
+	if err == driver.ErrBadConn {
+		return true
+	}
+	c := Classify(err)
+	return c == ErrConnectionLost || c == ErrSessionKilled
+}
+
+// AsORAError returns the *ORAError in the chain of err.
+func AsORAError(err error) (*ORAError, bool) {
+	for ; err != nil; err = unwrapErr(err) {
+		if oe, ok := err.(*ORAError); ok {
+			return oe, true
+		}
+	}
+	return nil, false
+}
+
+// unwrapErr returns the error wrapped by err, nil if it wraps none.
+func unwrapErr(err error) error {
+	switch x := err.(type) {
+	case interface {
+		Unwrap() error
+	}:
+		return x.Unwrap()
+	case interface {
+		Cause() error
+	}:
+		if cause := x.Cause(); cause != err {
+			return cause
+		}
+	}
+	return nil
+}
+
+// Is reports whether target is the ErrorClass of the error, for errors.Is.
+func (e *ORAError) Is(target error) bool {
+	c, ok := target.(*ErrorClass)
+	return ok && e != nil && c.has(e.code)
+}
+
+// Is reports whether target is the ErrorClass of the error code, for errors.Is,
+// also for a wrapped error with its code only in the text.
+func (e *oraErr) Is(target error) bool {
+	c, ok := target.(*ErrorClass)
+	return ok && e != nil && Classify(e) == c
+}
+
+var (
+	rConstraint = regexp.MustCompile(`constraint \(([^()\s]+)\) violated`)
+	rObject     = regexp.MustCompile(`"[^"]+"(?:\."[^"]+")*`)
+)
+
+// Constraint returns the name of the violated constraint, as OWNER.NAME,
+// from the message of a constraint violation (ORA-00001, ORA-02290 to ORA-02292).
+func (e *ORAError) Constraint() string {
+	if e == nil {
+		return ""
+	}
+	if m := rConstraint.FindStringSubmatch(e.message); m != nil {
+		return m[1]
+	}
+	return ""
+}
+
+// Object returns the object named by the message, as OWNER.TABLE.COLUMN
+// for ORA-01400, ORA-01407 and ORA-12899.
+func (e *ORAError) Object() string {
+	if e == nil {
+		return ""
+	}
+	return strings.Replace(rObject.FindString(e.message), `"`, "", -1)
+}
+
+// isParseErrorCode reports whether code is an error of parsing a statement:
+// ORA-00900 to ORA-00999, or ORA-06550 of PL/SQL compilation.
+func isParseErrorCode(code int) bool {
+	return 900 <= code && code <= 999 || code == 6550
+}
+
+// setParseError records the SQL text and the parse error offset of a statement,
+// for a parse error; the offset of other errors is zero.
+func (e *ORAError) setParseError(sql string, offset int) {
+	if offset == 0 && !isParseErrorCode(e.code) {
+		return
+	}
+	e.sql, e.offset, e.hasOffset = sql, offset, true
+}
+
+// Offset returns the byte offset of the parse error in the SQL text,
+// and false if the error is not a parse error of a statement.
+func (e *ORAError) Offset() (int, bool) {
+	if e == nil {
+		return 0, false
+	}
+	return e.offset, e.hasOffset
+}
+
+// SQL returns the SQL text of the statement failed to parse,
+// "" if the error is not a parse error.
+func (e *ORAError) SQL() string {
+	if e == nil {
+		return ""
+	}
+	return e.sql
+}
+
+// Excerpt returns the line of the SQL text with the parse error,
+// prefixed by its number, and a caret under the error position:
+//
+//	2: FROM dual WHER 1 = 1
+//	                  ^
+//
+// Excerpt returns "" if the error is not a parse error.
+func (e *ORAError) Excerpt() string {
+	if e == nil || !e.hasOffset || e.sql == "" {
+		return ""
+	}
+	sql, off := e.sql, e.offset
+	if off > len(sql) {
+		off = len(sql)
+	}
+	for off > 0 && off < len(sql) && !utf8.RuneStart(sql[off]) {
+		off--
+	}
+	start := strings.LastIndexByte(sql[:off], '\n') + 1
+	end := len(sql)
+	if i := strings.IndexByte(sql[off:], '\n'); i >= 0 {
+		end = off + i
+	}
+	prefix := strconv.Itoa(strings.Count(sql[:start], "\n")+1) + ": "
+	// pad the caret as the line, keeping its tabs
+	pad := make([]byte, 0, len(prefix)+off-start)
+	pad = append(pad, strings.Repeat(" ", len(prefix))...)
+	for _, r := range sql[start:off] {
+		if r == '\t' {
+			pad = append(pad, '\t')
+		} else {
+			pad = append(pad, ' ')
+		}
+	}
+	return prefix + strings.TrimRight(sql[start:end], "\r") + "\n" + string(pad) + "^"
+}
+
+// PLSQLFrame is a frame of the PL/SQL error stack:
+// an "ORA-06512: at ..." line of the error message.
+type PLSQLFrame struct {
+	// Owner and Object name the stored PL/SQL unit,
+	// both are empty for an anonymous block.
+	Owner, Object string
+	// Line is the line number in the unit.
+	Line int
+}
+
+func (f PLSQLFrame) String() string {
+	if f.Object == "" {
+		return fmt.Sprintf("at line %d", f.Line)
+	}
+	if f.Owner == "" {
+		return fmt.Sprintf("at %q, line %d", f.Object, f.Line)
+	}
+	return fmt.Sprintf("at \"%s.%s\", line %d", f.Owner, f.Object, f.Line)
+}
+
+var rPLSQLFrame = regexp.MustCompile(`ORA-06512: at (?:"([^"]*)", )?line (\d+)`)
+
+// Stack returns the PL/SQL error stack of the message,
+// the frame raising the error first, nil if it has none.
+func (e *ORAError) Stack() []PLSQLFrame {
+	if e == nil {
+		return nil
+	}
+	var frames []PLSQLFrame
+	for _, m := range rPLSQLFrame.FindAllStringSubmatch(e.message, -1) {
+		line, err := strconv.Atoi(m[2])
+		if err != nil {
+			continue
+		}
+		f := PLSQLFrame{Line: line}
+		if unit := m[1]; unit != "" {
+			if i := strings.IndexByte(unit, '.'); i >= 0 {
+				f.Owner, f.Object = unit[:i], unit[i+1:]
+			} else {
+				f.Object = unit
+			}
+		}
+		frames = append(frames, f)
+	}
+	return frames
+}
@@ -0,0 +1,152 @@
+// +build go1.13
+
+// Copyright 2014 Rana Ian. All rights reserved.
+// Use of this source code is governed by The MIT License
+// found in the accompanying LICENSE file.
+
+package ora
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestClassify(t *testing.T) {
+	for i, tc := range []struct {
+		err                error
+		class              *ErrorClass
+		retryable, lost    bool
+		constraint, object string
+	}{
+		{&ORAError{code: 1, message: "ORA-00001: unique constraint (SCOTT.PK_EMP) violated"},
+			ErrUniqueViolation, false, false, "SCOTT.PK_EMP", ""},
+		{&ORAError{code: 2291, message: "ORA-02291: integrity constraint (SCOTT.FK_DEPTNO) violated - parent key not found"},
+			ErrForeignKeyViolation, false, false, "SCOTT.FK_DEPTNO", ""},
+		{&ORAError{code: 2290, message: "ORA-02290: check constraint (SCOTT.CK_SAL) violated"},
+			ErrCheckViolation, false, false, "SCOTT.CK_SAL", ""},
+		{&ORAError{code: 1400, message: `ORA-01400: cannot insert NULL into ("SCOTT"."EMP"."ENAME")`},
+			ErrNotNullViolation, false, false, "", "SCOTT.EMP.ENAME"},
+		{&ORAError{code: 12899, message: `ORA-12899: value too large for column "SCOTT"."EMP"."JOB" (actual: 12, maximum: 9)`},
+			ErrValueTooLarge, false, false, "", "SCOTT.EMP.JOB"},
+		{&ORAError{code: 60, message: "ORA-00060: deadlock detected while waiting for resource"},
+			ErrDeadlock, true, false, "", ""},
+		{&ORAError{code: 54}, ErrResourceBusy, true, false, "", ""},
+		{&ORAError{code: 1403}, ErrNoDataFound, false, false, "", ""},
+		{&ORAError{code: 28}, ErrSessionKilled, true, true, "", ""},
+		{&ORAError{code: 3113}, ErrConnectionLost, true, true, "", ""},
+		{&ORAError{code: 942}, ErrTableNotFound, false, false, "", ""},
+		{&ORAError{code: 20001, message: "ORA-20001: custom"}, nil, false, false, "", ""},
+		{&oraErr{Underlying: &ORAError{code: 8177}}, ErrSerialization, true, false, "", ""},
+		{&oraErr{Underlying: errors.New("ORA-04068: existing state of packages has been discarded")}, ErrPackageDiscarded, true, false, "", ""},
+		{&TimeoutError{Err: &ORAError{code: 1013}}, ErrTimeout, true, false, "", ""},
+		{&oraErr{Underlying: &TimeoutError{Err: &ORAError{code: 1013}}}, ErrTimeout, true, false, "", ""},
+		{&ORAError{code: 1013}, ErrCanceled, false, false, "", ""},
+		{context.DeadlineExceeded, ErrTimeout, true, false, "", ""},
+		{context.Canceled, ErrCanceled, false, false, "", ""},
+		{driver.ErrBadConn, nil, true, true, "", ""},
+		{errors.New("no code"), nil, false, false, "", ""},
+		{nil, nil, false, false, "", ""},
+	} {
+		if got := Classify(tc.err); got != tc.class {
+			t.Errorf("%d. %v: got class %v, wanted %v", i, tc.err, got, tc.class)
+		}
+		if tc.class != nil && tc.class != ErrTimeout && tc.class != ErrCanceled {
+			if !errors.Is(tc.err, tc.class) {
+				t.Errorf("%d. %v is not %v", i, tc.err, tc.class)
+			}
+			if errors.Is(tc.err, ErrTimeout) {
+				t.Errorf("%d. %v is ErrTimeout", i, tc.err)
+			}
+		}
+		if got := IsRetryable(tc.err); got != tc.retryable {
+			t.Errorf("%d. %v: got retryable %t, wanted %t", i, tc.err, got, tc.retryable)
+		}
+		if got := IsConnectionLost(tc.err); got != tc.lost {
+			t.Errorf("%d. %v: got connection lost %t, wanted %t", i, tc.err, got, tc.lost)
+		}
+		oe, ok := AsORAError(tc.err)
+		if !ok {
+			continue
+		}
+		if got := oe.Constraint(); got != tc.constraint {
+			t.Errorf("%d. got constraint %q, wanted %q", i, got, tc.constraint)
+		}
+		if got := oe.Object(); got != tc.object {
+			t.Errorf("%d. got object %q, wanted %q", i, got, tc.object)
+		}
+	}
+
+	// errors.Is through wrapping
+	err := fmt.Errorf("insert: %w", &oraErr{Underlying: &ORAError{code: 1}})
+	if !errors.Is(err, ErrUniqueViolation) || ErrorCode(err) != 1 {
+		t.Errorf("%v: got code %d", err, ErrorCode(err))
+	}
+	if !errors.Is(&TimeoutError{Err: &ORAError{code: 3156}}, ErrTimeout) {
+		t.Errorf("TimeoutError is not ErrTimeout")
+	}
+	if err = (&oraErr{Underlying: &TimeoutError{Err: &ORAError{code: 1013}}}); errors.Is(err, ErrCanceled) || !errors.Is(err, ErrTimeout) {
+		t.Errorf("%v: a timeout is not a cancel", err)
+	}
+}
+
+func TestORAErrorExcerpt(t *testing.T) {
+	for i, tc := range []struct {
+		code   int
+		sql    string
+		offset int
+		want   string
+	}{
+		{code: 1476, sql: "SELECT 1/0 FROM DUAL", offset: 0},
+		{code: 923, sql: "SELECT 1 FORM DUAL", offset: 9, want: "1: SELECT 1 FORM DUAL\n            ^"},
+		{code: 933, sql: "SELECT 1\nFROM dual WHER 1 = 1\r\nORDER BY 1", offset: 19,
+			want: "2: FROM dual WHER 1 = 1\n             ^"},
+		{code: 942, sql: "SELECT *\n\tFROM nonexistent", offset: 15, want: "2: \tFROM nonexistent\n   \t     ^"},
+		{code: 904, sql: "SELECT árvíz, x FROM t", offset: 16, want: "1: SELECT árvíz, x FROM t\n                 ^"},
+		{code: 900, sql: "XSELECT", offset: 0, want: "1: XSELECT\n   ^"},
+		{code: 6550, sql: "BEGIN\n  x := ;\nEND;", offset: 500, want: "3: END;\n       ^"},
+	} {
+		oe := &ORAError{code: tc.code}
+		oe.setParseError(tc.sql, tc.offset)
+		if got := oe.Excerpt(); got != tc.want {
+			t.Errorf("%d. got\n%s\nwanted\n%s", i, got, tc.want)
+		}
+		if off, ok := oe.Offset(); ok != (tc.want != "") || ok && off != tc.offset {
+			t.Errorf("%d. got offset %d, %t", i, off, ok)
+		}
+	}
+}
+
+func TestORAErrorStack(t *testing.T) {
+	oe := &ORAError{code: 20001, message: `ORA-20001: boom
+ORA-06512: at "SCOTT.PKG", line 12
+ORA-06512: at "SCOTT.PROC", line 3
+ORA-06512: at "TRG_X", line 7
+ORA-06512: at line 2`}
+	want := []PLSQLFrame{
+		{Owner: "SCOTT", Object: "PKG", Line: 12},
+		{Owner: "SCOTT", Object: "PROC", Line: 3},
+		{Object: "TRG_X", Line: 7},
+		{Line: 2},
+	}
+	got := oe.Stack()
+	if len(got) != len(want) {
+		t.Fatalf("got %v, wanted %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("%d. got %v, wanted %v", i, got[i], want[i])
+		}
+	}
+	if s := got[0].String(); s != `at "SCOTT.PKG", line 12` {
+		t.Errorf("got %q", s)
+	}
+	if s := got[3].String(); s != "at line 2" {
+		t.Errorf("got %q", s)
+	}
+	if frames := (&ORAError{code: 1, message: "ORA-00001: unique constraint (A.B) violated"}).Stack(); frames != nil {
+		t.Errorf("got %v, wanted none", frames)
+	}
+}
@@ -5,7 +5,10 @@
 package ora
 
 import (
+	"context"
 	"io"
+	"net/url"
+	"strconv"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -18,6 +21,14 @@ type PoolCfg struct {
 	Username       string
 	Password       string
 	Min, Max, Incr uint32
+
+	// ConnClass is the DRCP connection class of the sessions: DRCP shares its
+	// pooled servers among the sessions of the same connection class only.
+	// Set the same ConnClass on each application server to share them.
+	// If empty, DRCP generates a unique one for each OCI session pool.
+	ConnClass string
+	// Purity is the DRCP session purity of the sessions.
+	Purity Purity
 }
 
 type PoolType uint8
@@ -29,11 +40,82 @@ const (
 	CPool   = PoolType(3)
 )
 
+// Purity is the DRCP session purity: whether a session may be one used before.
+type Purity uint8
+
+const (
+	// PurityDefault is the default of OCI: SELF for the sessions of a session pool.
+	PurityDefault = Purity(0)
+	// PurityNew requests a new session, without the state of a previous use.
+	PurityNew = Purity(1)
+	// PuritySelf allows a session used before with the same connection class,
+	// with its session state, as the settings of SesCfg.NLS and SesCfg.Init.
+	PuritySelf = Purity(2)
+)
+
+func (p Purity) String() string {
+	switch p {
+	case PurityNew:
+		return "new"
+	case PuritySelf:
+		return "self"
+	}
+	return "default"
+}
+
 const (
 	DefaultPoolSize      = 4
 	DefaultEvictDuration = time.Minute
 )
 
+// PoolPolicy configures the reuse of the idle sessions of Pool and BoundedPool.
+type PoolPolicy struct {
+	// ValidateAfter makes Get check an idle session with Ses.Ping before
+	// handing it out, if it has been idle for longer than this.
+	// Broken sessions are closed, and the next one is tried.
+	//
+	// Zero disables the validation.
+	ValidateAfter time.Duration
+
+	// MaxLifetime is the maximum time a session is reused after it has been opened:
+	// older sessions are closed instead of being put back to the pool.
+	//
+	// Zero means no limit.
+	MaxLifetime time.Duration
+
+	// IdleTimeout is the maximum time a session is kept idle in the pool:
+	// Get skips, and the evictor closes the sessions idle for longer.
+	//
+	// If zero, each eviction halves the idle sessions.
+	IdleTimeout time.Duration
+
+	// LIFO makes Get reuse the most recently returned idle session,
+	// letting the rarely used ones time out.
+	// The default is FIFO, which reuses the idle sessions uniformly.
+	LIFO bool
+}
+
+// expired reports whether c, idle since since, shall be closed at now.
+// The lifetime applies to the elements with an opened() method, as *Ses.
+func (pp PoolPolicy) expired(c io.Closer, since, now time.Time) bool {
+	if pp.IdleTimeout > 0 && now.Sub(since) > pp.IdleTimeout {
+		return true
+	}
+	if pp.MaxLifetime > 0 {
+		if o, ok := c.(interface {
+			opened() time.Time
+		}); ok && now.Sub(o.opened()) > pp.MaxLifetime {
+			return true
+		}
+	}
+	return false
+}
+
+// mustValidate reports whether an element idle since since shall be validated at now.
+func (pp PoolPolicy) mustValidate(since, now time.Time) bool {
+	return pp.ValidateAfter > 0 && now.Sub(since) > pp.ValidateAfter
+}
+
 // NewPool returns an idle session pool,
 // which evicts the idle sessions every minute,
 // and automatically manages the required new connections (Srv).
@@ -42,9 +124,11 @@ const (
 //
 // This pool does NOT limit the number of active connections, just helps
 // reuse already established connections and sessions, lowering the resource
-// usage on the server.
+// usage on the server. Use NewBoundedPool to limit them.
 //
 // If size <= 0, then DefaultPoolSize is used.
+//
+// Use SetPolicy for validating, and expiring the idle sessions.
 func (env *Env) NewPool(srvCfg SrvCfg, sesCfg SesCfg, size int) *Pool {
 	if srvCfg.IsZero() {
 		panic("srvCfg shall not be empty")
@@ -58,6 +142,7 @@ func (env *Env) NewPool(srvCfg SrvCfg, sesCfg SesCfg, size int) *Pool {
 		srv: newIdlePool(size),
 		ses: newIdlePool(size),
 	}
+	p.newSes, p.usable = p.openSes, p.usableSes
 	p.poolEvictor = &poolEvictor{
 		Evict: func(d time.Duration) {
 			p.ses.Evict(d)
@@ -73,23 +158,98 @@ func NewPool(dsn string, size int) (*Pool, error) {
 	if err != nil {
 		return nil, err
 	}
-	srvCfg := SrvCfg{StmtCfg: NewStmtCfg(), Pool: DSNPool(dsn)}
-	sesCfg := SesCfg{Mode: DSNMode(dsn)}
-	sesCfg.Username, sesCfg.Password, srvCfg.Dblink = SplitDSN(dsn)
-	return env.NewPool(srvCfg, sesCfg, size), nil
+	d, err := ParseDSN(dsn)
+	if err != nil {
+		env.Close()
+		return nil, err
+	}
+	return env.NewPool(d.SrvCfg(NewStmtCfg()), d.SesCfg(), size), nil
 }
 
 type Pool struct {
+	counters poolCounters // keep it first for 64-bit alignment
+
 	env    *Env
 	srvCfg SrvCfg
 	sesCfg SesCfg
 
 	sync.Mutex
 	srv, ses *idlePool
+	retry    RetryPolicy
+	// newSes opens a new session, called with the Pool unlocked,
+	// as connecting to a failed target may take long
+	newSes func() (*Ses, error)
+	// usable reports whether an idle session, idle since since, can be handed out,
+	// called with the Pool unlocked, as it may ping the session
+	usable func(ses *Ses, since time.Time) bool
+	// targets are the connect targets of SetFailover, a *targetSet
+	targets atomic.Value
 
 	*poolEvictor
 }
 
+// SetPolicy sets the reuse policy of the idle sessions and connections.
+func (p *Pool) SetPolicy(policy PoolPolicy) {
+	p.ses.SetPolicy(policy)
+	p.srv.SetPolicy(policy)
+}
+
+// SetRetryPolicy sets the RetryPolicy of Get, and of the sessions it returns:
+// Get retries opening a new session, the sessions retry their queries
+// and idempotent statements, replacing their lost connection.
+func (p *Pool) SetRetryPolicy(rp RetryPolicy) {
+	p.Lock()
+	p.retry = rp
+	p.Unlock()
+}
+
+// SetFailover makes the new connections of the pool connect to the targets of cfg,
+// instead of SrvCfg.Dblink, skipping the failed ones by their circuit breakers.
+// The idle connections and sessions are reused, whichever target they are connected to.
+func (p *Pool) SetFailover(cfg FailoverCfg) error {
+	ts, err := newTargetSet(cfg)
+	if err != nil {
+		return err
+	}
+	ts.start(func(dblink string) error {
+		srvCfg := p.srvCfg
+		srvCfg.Dblink = dblink
+		srv, err := p.env.OpenSrv(srvCfg)
+		if err != nil {
+			return err
+		}
+		return srv.Close()
+	})
+	if old := p.targetSet(); old != nil {
+		old.close()
+	}
+	p.targets.Store(ts)
+	return nil
+}
+
+// targetSet returns the connect targets of SetFailover, nil if it has none.
+func (p *Pool) targetSet() *targetSet {
+	ts, _ := p.targets.Load().(*targetSet)
+	return ts
+}
+
+// Targets returns the states of the connect targets of SetFailover.
+func (p *Pool) Targets() []TargetState {
+	if ts := p.targetSet(); ts != nil {
+		return ts.states()
+	}
+	return nil
+}
+
+// Stats returns a snapshot of the state of the session pool.
+func (p *Pool) Stats() PoolStats {
+	st := p.counters.stats(p.ses)
+	if ts := p.targetSet(); ts != nil {
+		st.Target = ts.currentTarget()
+	}
+	return st
+}
+
 // Close all idle sessions and connections.
 func (p *Pool) Close() (err error) {
 	defer func() {
@@ -97,10 +257,11 @@ func (p *Pool) Close() (err error) {
 			err = errR(r)
 		}
 	}()
+	p.targetSet().close()
 	p.Lock()
 	defer p.Unlock()
 	for {
-		x := p.ses.Get()
+		x, _ := p.ses.Get()
 		if x == nil {
 			break
 		}
@@ -118,39 +279,94 @@ func (p *Pool) Close() (err error) {
 // Get a session - either an idle session, or if such does not exist, then
 // a new session on an idle connection; if such does not exist, then
 // a new session on a new connection.
+//
+// Opening a new session is retried as the RetryPolicy of the Pool says.
 func (p *Pool) Get() (ses *Ses, err error) {
+	start, hit := time.Now(), false
+	defer func() { p.counters.got(start, hit, ses != nil && err == nil) }()
+	p.Lock()
+	rp := p.retry
+	p.Unlock()
+	if err = rp.Do(context.Background(), func(int) error {
+		ses, hit, err = p.get()
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	ses.Lock()
+	ses.retry, ses.reopen = rp, p.reopen
+	ses.Unlock()
+	return ses, nil
+}
+
+// get returns an idle session, or a new one, and whether it is an idle one.
+func (p *Pool) get() (ses *Ses, hit bool, err error) {
 	defer func() {
 		if r := recover(); r != nil {
 			err = errR(r)
 		}
 	}()
-	p.Lock()
-	defer p.Unlock()
-
-	// Instead of closing the session, put it back to the session pool.
-	Instead := func(ses *Ses) error { p.Put(ses); return nil }
-	// try get session from the ses pool
+	// try get session from the ses pool,
+	// without locking the Pool while validating the session
+	usable := p.usable
+	if usable == nil {
+		usable = p.usableSes
+	}
 	for {
-		x := p.ses.Get()
+		x, since := p.ses.Get()
 		if x == nil { // the ses pool is empty
 			break
 		}
 		ses = x.(sesSrvPB).Ses
-		if ses == nil || !ses.IsOpen() {
+		if !usable(ses, since) {
+			p.ses.discard(x)
 			continue
 		}
-		ses.insteadClose = Instead
-		return ses, nil
+		ses.Lock()
+		ses.insteadClose, ses.pool = p.instead, p
+		ses.Unlock()
+		return ses, true, nil
+	}
+	if ses, err = p.newSes(); err != nil {
+		return nil, false, err
 	}
+	ses.Lock()
+	ses.insteadClose, ses.pool = p.instead, p
+	ses.Unlock()
+	return ses, false, nil
+}
 
-	var srv *Srv
-	// try to get srv from the srv pool
+// usableSes reports whether the idle ses is open, and validates it if the policy says.
+func (p *Pool) usableSes(ses *Ses, since time.Time) bool {
+	if ses == nil || !ses.IsOpen() {
+		return false
+	}
+	return !p.ses.Policy().mustValidate(since, time.Now()) || ses.Ping() == nil
+}
+
+// instead is the Close of the sessions handed out: it puts the session back to the pool.
+func (p *Pool) instead(ses *Ses) error { p.Put(ses); return nil }
+
+// reopen opens a new session, to replace a lost one.
+func (p *Pool) reopen() (*Ses, error) {
+	return p.newSes()
+}
+
+// openSes opens a new session on an idle connection; if such does not exist, then
+// on a new connection, of a failover target chosen without locking the Pool.
+func (p *Pool) openSes() (ses *Ses, err error) {
+	p.Lock()
 	if p.sesCfg.IsZero() {
 		p.sesCfg = NewSesCfg()
 		p.sesCfg.StmtCfg = Cfg().StmtCfg
 	}
+	sesCfg := p.sesCfg
+	p.Unlock()
+
+	// try to get srv from the srv pool
+	var srv *Srv
 	for {
-		x := p.srv.Get()
+		x, _ := p.srv.Get()
 		if x == nil { // the srv pool is empty
 			break
 		}
@@ -164,36 +380,62 @@ func (p *Pool) Get() (ses *Ses, err error) {
 		if !ok {
 			continue
 		}
-		if ses, err = srv.OpenSes(p.sesCfg); err == nil {
-			ses.insteadClose = Instead
+		if ses, err = srv.OpenSes(sesCfg); err == nil {
 			return ses, nil
 		}
 		_ = srv.Close()
 	}
 
+	ts := p.targetSet()
+	if ts == nil {
+		return p.openSesOn(p.srvCfg, sesCfg)
+	}
+	err = ts.connect(func(dblink string) error {
+		srvCfg := p.srvCfg
+		srvCfg.Dblink = dblink
+		var err error
+		ses, err = p.openSesOn(srvCfg, sesCfg)
+		return err
+	})
+	return ses, err
+}
+
+// openSesOn opens a new session on a new connection.
+func (p *Pool) openSesOn(srvCfg SrvCfg, sesCfg SesCfg) (*Ses, error) {
 	//fmt.Fprintf(os.Stderr, "POOL: create new srv!\n")
-	if srv, err = p.env.OpenSrv(p.srvCfg); err != nil {
+	srv, err := p.env.OpenSrv(srvCfg)
+	if err != nil {
 		return nil, err
 	}
-	if ses, err = srv.OpenSes(p.sesCfg); err != nil {
+	ses, err := srv.OpenSes(sesCfg)
+	if err != nil {
 		srv.Close()
 		return nil, err
 	}
-	ses.insteadClose = Instead
 	return ses, nil
 }
 
 // Put the session back to the session pool.
 // Ensure that on ses Close (eviction), srv is put back on the idle pool.
 func (p *Pool) Put(ses *Ses) {
-	if ses == nil || !ses.IsOpen() {
+	if ses == nil {
+		return
+	}
+	ses.Lock()
+	if ses.pool == p { // only the sessions handed out by Get are in use
+		p.counters.put()
+	}
+	ses.insteadClose, ses.pool = nil, nil
+	ses.Unlock()
+	if !ses.IsOpen() {
+		return
+	}
+	if err := ses.resetClientInfo(); err != nil {
+		p.ses.discard(sesSrvPB{Ses: ses, p: p.srv})
 		return
 	}
-	p.ses.Lock()
-	ses.insteadClose = nil
 	//fmt.Fprintf(os.Stderr, "POOL: put back ses\n")
-	p.ses.putLocked(sesSrvPB{Ses: ses, p: p.srv})
-	p.ses.Unlock()
+	p.ses.Put(sesSrvPB{Ses: ses, p: p.srv})
 }
 
 type sesSrvPB struct {
@@ -237,6 +479,8 @@ func (env *Env) NewSrvPool(srvCfg SrvCfg, size int) *SrvPool {
 }
 
 type SrvPool struct {
+	counters poolCounters // keep it first for 64-bit alignment
+
 	env    *Env
 	srvCfg SrvCfg
 	srv    *idlePool
@@ -250,16 +494,45 @@ func (p *SrvPool) Close() error {
 
 // Get a connection.
 func (p *SrvPool) Get() (*Srv, error) {
-	x := p.srv.Get()
+	start := time.Now()
+	x, _ := p.srv.Get()
 	if x != nil {
-		return x.(*Srv), nil
+		p.counters.got(start, true, true)
+		return p.handOut(x.(*Srv)), nil
 	}
-	return p.env.OpenSrv(p.srvCfg)
+	srv, err := p.env.OpenSrv(p.srvCfg)
+	p.counters.got(start, false, err == nil)
+	if err != nil {
+		return nil, err
+	}
+	return p.handOut(srv), nil
+}
+
+// handOut marks srv as in use, handed out by this pool.
+func (p *SrvPool) handOut(srv *Srv) *Srv {
+	srv.Lock()
+	srv.pool = p
+	srv.Unlock()
+	return srv
+}
+
+// Stats returns a snapshot of the pool state.
+func (p *SrvPool) Stats() PoolStats {
+	return p.counters.stats(p.srv)
 }
 
 // Put the connection back to the idle pool.
 func (p *SrvPool) Put(srv *Srv) {
-	if srv == nil || !srv.IsOpen() {
+	if srv == nil {
+		return
+	}
+	srv.Lock()
+	if srv.pool == p { // only the connections handed out by Get are in use
+		p.counters.put()
+	}
+	srv.pool = nil
+	srv.Unlock()
+	if !srv.IsOpen() {
 		return
 	}
 	p.srv.Put(srv)
@@ -280,6 +553,8 @@ func (srv *Srv) NewSesPool(sesCfg SesCfg, size int) *SesPool {
 }
 
 type SesPool struct {
+	counters poolCounters // keep it first for 64-bit alignment
+
 	srv    *Srv
 	sesCfg SesCfg
 	ses    *idlePool
@@ -293,28 +568,107 @@ func (p *SesPool) Close() error {
 
 // Get a session from an idle Srv.
 func (p *SesPool) Get() (*Ses, error) {
+	start := time.Now()
 	for {
-		x := p.ses.Get()
+		x, _ := p.ses.Get()
 		if x == nil { // the pool is empty
 			break
 		}
 		ses := x.(*Ses)
 		if err := ses.Ping(); err == nil {
-			return ses, nil
+			p.counters.got(start, true, true)
+			return p.handOut(ses), nil
 		}
-		ses.Close()
+		p.ses.discard(ses)
+	}
+	ses, err := p.srv.OpenSes(p.sesCfg)
+	p.counters.got(start, false, err == nil)
+	if err != nil {
+		return nil, err
 	}
-	return p.srv.OpenSes(p.sesCfg)
+	return p.handOut(ses), nil
+}
+
+// handOut marks ses as in use, handed out by this pool.
+func (p *SesPool) handOut(ses *Ses) *Ses {
+	ses.Lock()
+	ses.pool = p
+	ses.Unlock()
+	return ses
+}
+
+// Stats returns a snapshot of the pool state.
+func (p *SesPool) Stats() PoolStats {
+	return p.counters.stats(p.ses)
 }
 
 // Put the session back to the session pool.
 func (p *SesPool) Put(ses *Ses) {
-	if ses == nil || !ses.IsOpen() {
+	if ses == nil {
+		return
+	}
+	ses.Lock()
+	if ses.pool == p { // only the sessions handed out by Get are in use
+		p.counters.put()
+	}
+	ses.pool = nil
+	ses.Unlock()
+	if !ses.IsOpen() {
+		return
+	}
+	if err := ses.resetClientInfo(); err != nil {
+		p.ses.discard(ses)
 		return
 	}
 	p.ses.Put(ses)
 }
 
+// poolCounters are the event counters of Pool, SrvPool and SesPool,
+// accessed atomically.
+type poolCounters struct {
+	hits, misses, creations uint64
+	getNanos                uint64
+	inUse                   int64
+}
+
+// got counts a Get call started at start, served by an idle element if hit,
+// successful if ok.
+func (c *poolCounters) got(start time.Time, hit, ok bool) {
+	atomic.AddUint64(&c.getNanos, uint64(time.Since(start)))
+	if hit {
+		atomic.AddUint64(&c.hits, 1)
+	} else {
+		atomic.AddUint64(&c.misses, 1)
+		if ok {
+			atomic.AddUint64(&c.creations, 1)
+		}
+	}
+	if ok {
+		atomic.AddInt64(&c.inUse, 1)
+	}
+}
+
+// put counts an element returned to the pool.
+func (c *poolCounters) put() {
+	atomic.AddInt64(&c.inUse, -1)
+}
+
+// stats returns the PoolStats of the counters and the idle elements.
+func (c *poolCounters) stats(idle *idlePool) PoolStats {
+	numIdle, evictions := idle.stats()
+	inUse := int(atomic.LoadInt64(&c.inUse))
+	return PoolStats{
+		Active:       numIdle + inUse,
+		Idle:         numIdle,
+		InUse:        inUse,
+		Hits:         atomic.LoadUint64(&c.hits),
+		Misses:       atomic.LoadUint64(&c.misses),
+		Creations:    atomic.LoadUint64(&c.creations),
+		Evictions:    evictions,
+		WaitDuration: time.Duration(atomic.LoadUint64(&c.getNanos)),
+	}
+}
+
 type poolEvictor struct {
 	Evict func(time.Duration)
 
@@ -357,10 +711,15 @@ func (p *poolEvictor) SetEvictDuration(dur time.Duration) {
 	p.tickerCh <- time.NewTicker(dur)
 }
 
-// SplitDSN splits the user/password@dblink string to username, password and dblink,
+// SplitDSN splits the user/password@dblink string (or the URL form, see DSN)
+// to username, password and dblink,
 // to be used as SesCfg.Username, SesCfg.Password, SrvCfg.Dblink.
 func SplitDSN(dsn string) (username, password, sid string) {
-	dsn = strings.TrimSpace(dsn)
+	if isURLDSN(dsn) {
+		d, _ := ParseDSN(dsn)
+		return d.Username, d.Password, d.Dblink
+	}
+	dsn, _ = dsnParams(strings.TrimSpace(dsn))
 	switch DSNMode(dsn) {
 	case SysOper:
 		dsn = dsn[:len(dsn)-11]
@@ -381,6 +740,11 @@ func SplitDSN(dsn string) (username, password, sid string) {
 
 // DSNMode returns the SessionMode (SysDefault/SysDba/SysOper).
 func DSNMode(str string) SessionMode {
+	if isURLDSN(str) {
+		d, _ := ParseDSN(str)
+		return d.Mode
+	}
+	str, _ = dsnParams(strings.TrimSpace(str))
 	if len(str) <= 11 {
 		return SysDefault
 	}
@@ -395,14 +759,58 @@ func DSNMode(str string) SessionMode {
 
 // DSNPool returns the Pool config from dsn.
 func DSNPool(str string) PoolCfg {
-	if strings.HasSuffix(str, ":POOLED") || strings.Contains(str, "(SERVER=POOLED)") {
-		pc := PoolCfg{Type: DRCPool, Min: 1, Max: 999, Incr: 1}
+	if isURLDSN(str) {
+		d, _ := ParseDSN(str)
+		return d.SrvCfg(StmtCfg{}).Pool
+	}
+	str, params := dsnParams(strings.TrimSpace(str))
+	dblink := str
+	if strings.Contains(str, "@") {
+		_, _, dblink = SplitDSN(str)
+	}
+	if pooledDblink(dblink) {
+		var d DSN
+		d.setParams(params)
+		pc := PoolCfg{Type: DRCPool, Min: 1, Max: 999, Incr: 1, ConnClass: d.ConnClass, Purity: d.Purity}
 		pc.Username, pc.Password, _ = SplitDSN(str)
 		return pc
 	}
 	return PoolCfg{}
 }
 
+// DSNStmtCacheSize returns the statement cache size
+// from the "stmt_cache" parameter of dsn (user/password@dblink?stmt_cache=20),
+// to be used as SrvCfg.StmtCacheSize.
+//
+// Returns zero if the parameter is missing or invalid.
+func DSNStmtCacheSize(dsn string) uint32 {
+	if isURLDSN(dsn) {
+		d, _ := ParseDSN(dsn)
+		return d.StmtCacheSize
+	}
+	_, params := dsnParams(strings.TrimSpace(dsn))
+	size, err := strconv.ParseUint(params.Get("stmt_cache"), 10, 32)
+	if err != nil {
+		return 0
+	}
+	return uint32(size)
+}
+
+// dsnParams splits the "?key=value&..." parameters from the end of dsn.
+// Only a '?' after the dblink starts the parameters, as the password may contain it:
+// without an '@', or if the parameters cannot be parsed, dsn is returned unchanged.
+func dsnParams(dsn string) (string, url.Values) {
+	i, at := strings.LastIndexByte(dsn, '?'), strings.LastIndexByte(dsn, '@')
+	if i < 0 || at < 0 || i < at {
+		return dsn, nil
+	}
+	params, err := url.ParseQuery(dsn[i+1:])
+	if err != nil {
+		return dsn, nil
+	}
+	return dsn[:i], params
+}
+
 // NewEnvSrvSes is a comfort function which opens the environment,
 // creates a connection (Srv) to the server,
 // and opens a session (Ses), in one call.
@@ -413,9 +821,12 @@ func NewEnvSrvSes(dsn string) (*Env, *Srv, *Ses, error) {
 	if err != nil {
 		return nil, nil, nil, err
 	}
-	srvCfg := SrvCfg{StmtCfg: env.Cfg(), Pool: DSNPool(dsn)}
-	sesCfg := SesCfg{Mode: DSNMode(dsn)}
-	sesCfg.Username, sesCfg.Password, srvCfg.Dblink = SplitDSN(dsn)
+	d, err := ParseDSN(dsn)
+	if err != nil {
+		env.Close()
+		return nil, nil, nil, err
+	}
+	srvCfg, sesCfg := d.SrvCfg(env.Cfg()), d.SesCfg()
 	//fmt.Fprintf(os.Stderr, "dsn=% => srv=%#v ses=%#v", dsn, srvCfg, sesCfg)
 	srv, err := env.OpenSrv(srvCfg)
 	if err != nil {
@@ -431,117 +842,152 @@ func NewEnvSrvSes(dsn string) (*Env, *Srv, *Ses, error) {
 	return env, srv, ses, nil
 }
 
-const poolWaitGet = 10 * time.Millisecond
-const poolWaitPut = 1 * time.Second
-
 // idlePool is a pool of io.Closers.
 // Each element will be Closed on eviction.
 //
-// The backing store is a simple []io.Closer, which is treated as random store,
-// to achive uniform reuse.
+// The elements are kept in the order they were put back,
+// and reused in FIFO or LIFO order, as the policy says.
 type idlePool struct {
-	sync.RWMutex
-	elems atomic.Value
-}
+	sync.Mutex
+	size   int
+	policy PoolPolicy
+	elems  []idleElem // the most recently put is the last
+	closed bool
 
-func (p *idlePool) Elems() chan io.Closer {
-	i := p.elems.Load()
-	if i == nil {
-		return nil
-	}
-	return i.(chan io.Closer)
+	evictions uint64 // number of elements closed by the pool
 }
-func (p *idlePool) SetElems(c chan io.Closer) chan io.Closer {
-	b := p.Elems()
-	p.elems.Store(c)
-	return b
+
+// idleElem is an element of an idlePool, with the time it was put back at.
+type idleElem struct {
+	io.Closer
+	since time.Time
 }
 
 // NewidlePool returns an idlePool.
 func newIdlePool(size int) *idlePool {
-	var p idlePool
-	p.SetElems(make(chan io.Closer, size))
-	return &p
+	if size <= 0 {
+		size = DefaultPoolSize
+	}
+	return &idlePool{size: size}
+}
+
+// Policy returns the reuse policy.
+func (p *idlePool) Policy() PoolPolicy {
+	p.Lock()
+	defer p.Unlock()
+	return p.policy
+}
+
+// SetPolicy sets the reuse policy.
+func (p *idlePool) SetPolicy(policy PoolPolicy) {
+	p.Lock()
+	p.policy = policy
+	p.Unlock()
 }
 
-// Evict halves the idle items
+// Evict closes the idle elements expired by the policy.
+// Without IdleTimeout, it halves the idle elements, the least recently used first.
 func (p *idlePool) Evict(dur time.Duration) {
-	p.RLock()
-	defer p.RUnlock()
-	elems := p.Elems()
-	n := len(elems)/2 + 1
-	for i := 0; i < n; i++ {
-		select {
-		case elem, ok := <-elems:
-			if !ok {
-				return
-			}
-			if elem != nil {
-				elem.Close()
-			}
-		default:
-			return
+	now := time.Now()
+	p.Lock()
+	var old []io.Closer
+	keep := p.elems[:0]
+	for _, elem := range p.elems {
+		if p.policy.expired(elem.Closer, elem.since, now) {
+			old = append(old, elem.Closer)
+		} else {
+			keep = append(keep, elem)
+		}
+	}
+	if p.policy.IdleTimeout <= 0 && len(keep) > 0 {
+		n := len(keep)/2 + 1
+		for _, elem := range keep[:n] {
+			old = append(old, elem.Closer)
 		}
+		keep = keep[:copy(keep, keep[n:])]
+	}
+	for i := len(keep); i < len(p.elems); i++ {
+		p.elems[i] = idleElem{}
+	}
+	p.elems = keep
+	p.evictions += uint64(len(old))
+	p.Unlock()
+	for _, c := range old {
+		c.Close()
 	}
 }
 
-// Get returns a closer or nil, if no pool found.
-func (p *idlePool) Get() io.Closer {
-	p.RLock()
-	defer p.RUnlock()
-	for {
-		elems := p.Elems()
-		select {
-		case elem := <-elems:
-			if elem != nil {
-				return elem
-			}
-		case <-time.After(poolWaitGet):
-			return nil
+// Get returns a closer and the time it was put back at,
+// or nil, if the pool is empty. The expired elements are closed.
+func (p *idlePool) Get() (io.Closer, time.Time) {
+	now := time.Now()
+	p.Lock()
+	var old []io.Closer
+	var elem idleElem
+	for len(p.elems) > 0 {
+		if p.policy.LIFO {
+			elem = p.elems[len(p.elems)-1]
+			p.elems[len(p.elems)-1] = idleElem{}
+			p.elems = p.elems[:len(p.elems)-1]
+		} else {
+			elem = p.elems[0]
+			p.elems[0] = idleElem{}
+			p.elems = p.elems[1:]
+		}
+		if elem.Closer != nil && !p.policy.expired(elem.Closer, elem.since, now) {
+			break
+		}
+		if elem.Closer != nil {
+			old = append(old, elem.Closer)
 		}
+		elem = idleElem{}
+	}
+	p.evictions += uint64(len(old))
+	p.Unlock()
+	for _, c := range old {
+		c.Close()
 	}
+	return elem.Closer, elem.since
 }
 
-// Put a new element into the store. The slot is chosen randomly.
-// If no empty slot is found, one (random) is Close()-d and this new
-// element is put there.
-// This way elements reused uniformly.
+// Put a new element into the store.
+// If the pool is full, or the element is expired, then it is Close()-d.
 func (p *idlePool) Put(c io.Closer) {
-	p.RLock()
-	p.putLocked(c)
-	p.RUnlock()
-}
-
-func (p *idlePool) putLocked(c io.Closer) {
-	select {
-	case p.Elems() <- c:
+	now := time.Now()
+	p.Lock()
+	if p.closed || len(p.elems) >= p.size || p.policy.expired(c, now, now) {
+		p.evictions++
+		p.Unlock()
+		c.Close()
 		return
-	default:
-		go func() {
-			select {
-			case p.Elems() <- c:
-				return
-			case <-time.After(poolWaitPut):
-				c.Close()
-			}
-		}()
 	}
+	p.elems = append(p.elems, idleElem{Closer: c, since: now})
+	p.Unlock()
+}
+
+// discard closes a broken element got from the pool.
+func (p *idlePool) discard(c io.Closer) error {
+	p.Lock()
+	p.evictions++
+	p.Unlock()
+	return c.Close()
+}
+
+// stats returns the number of idle elements, and the number of evictions.
+func (p *idlePool) stats() (idle int, evictions uint64) {
+	p.Lock()
+	defer p.Unlock()
+	return len(p.elems), p.evictions
 }
 
 // Close all elements.
 func (p *idlePool) Close() error {
 	p.Lock()
-	defer p.Unlock()
-	elems := p.SetElems(nil)
-	if elems == nil {
-		return nil
-	}
-	close(elems)
+	elems := p.elems
+	p.elems, p.closed = nil, true
+	p.Unlock()
 	var err error
-	for elem := range elems {
-		if elem == nil {
-			continue
-		}
+	for _, elem := range elems {
 		if closeErr := elem.Close(); closeErr != nil && err == nil {
 			err = closeErr
 		}
@@ -0,0 +1,225 @@
+// Copyright 2014 Rana Ian. All rights reserved.
+// Use of this source code is governed by The MIT License
+// found in the accompanying LICENSE file.
+
+package ora
+
+/*
+#include <oci.h>
+*/
+import "C"
+import (
+	"container/list"
+	"context"
+	"math/rand"
+	"sync/atomic"
+	"time"
+)
+
+// RetryPolicy retries the operations failed with a transient error,
+// as a deadlock, lock contention, a timeout or a lost connection.
+//
+// The zero RetryPolicy does not retry.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of attempts, the first one included.
+	// Zero or one means no retry.
+	MaxAttempts int
+
+	// Backoff returns the time to wait before the retry-th retry, starting with 1.
+	// If nil, DefaultBackoff is used.
+	Backoff func(retry int) time.Duration
+
+	// Retryable reports whether the operation failed with err may be retried.
+	// If nil, IsRetryable is used.
+	Retryable func(err error) bool
+}
+
+// DefaultBackoff is the Backoff of a RetryPolicy without one.
+var DefaultBackoff = ExponentialBackoff(100*time.Millisecond, 5*time.Second)
+
+// ExponentialBackoff returns a Backoff doubling the wait from base up to max,
+// each wait a random duration between its half and itself.
+func ExponentialBackoff(base, max time.Duration) func(retry int) time.Duration {
+	return func(retry int) time.Duration {
+		d := base
+		for i := 1; i < retry && d < max; i++ {
+			d *= 2
+		}
+		if d > max {
+			d = max
+		}
+		if d <= 0 {
+			return 0
+		}
+		return d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+	}
+}
+
+func (rp RetryPolicy) retryable(err error) bool {
+	if rp.Retryable != nil {
+		return rp.Retryable(err)
+	}
+	return IsRetryable(err)
+}
+
+func (rp RetryPolicy) backoff(retry int) time.Duration {
+	if rp.Backoff != nil {
+		return rp.Backoff(retry)
+	}
+	return DefaultBackoff(retry)
+}
+
+// Do calls f till it succeeds, fails with an error not to be retried,
+// MaxAttempts is reached or ctx is done, waiting the Backoff between the calls.
+// attempt is the number of the call, starting with 1.
+//
+// Do returns the error of the last call.
+func (rp RetryPolicy) Do(ctx context.Context, f func(attempt int) error) error {
+	for attempt := 1; ; attempt++ {
+		err := f(attempt)
+		if err == nil || attempt >= rp.MaxAttempts || !rp.retryable(err) {
+			return err
+		}
+		if d := rp.backoff(attempt); d > 0 {
+			t := time.NewTimer(d)
+			select {
+			case <-ctx.Done():
+				t.Stop()
+				return err
+			case <-t.C:
+			}
+		} else if ctx.Err() != nil {
+			return err
+		}
+	}
+}
+
+// SetRetryPolicy sets the RetryPolicy of the queries and of the idempotent
+// statements (StmtCfg.SetIdempotent) of the session.
+//
+// The sessions of a Pool have the RetryPolicy of the Pool, and their lost
+// connection is replaced before a retry, outside of a transaction.
+func (ses *Ses) SetRetryPolicy(rp RetryPolicy) {
+	ses.Lock()
+	ses.retry = rp
+	ses.Unlock()
+}
+
+// withRetry calls f, retrying it as the RetryPolicy of the session says if retry is true.
+// The lost connection of a session of a Pool is replaced before the retry.
+func (stmt *Stmt) withRetry(ctx context.Context, retry bool, f func() error) error {
+	stmt.RLock()
+	ses := stmt.ses
+	stmt.RUnlock()
+	if !retry || ses == nil {
+		return f()
+	}
+	ses.RLock()
+	rp, reopen := ses.retry, ses.reopen
+	ses.RUnlock()
+	if rp.MaxAttempts <= 1 {
+		return f()
+	}
+	retryable := rp.retryable
+	rp.Retryable = func(err error) bool {
+		if !retryable(err) {
+			return false
+		}
+		// the work of the transaction is lost with the connection
+		return !IsConnectionLost(err) || reopen != nil && ses.openTxs.len() == 0
+	}
+	var last error
+	return rp.Do(ctx, func(attempt int) error {
+		if attempt > 1 && IsConnectionLost(last) {
+			if err := ses.replace(reopen); err != nil {
+				last = err
+				return err
+			}
+		}
+		last = f()
+		return last
+	})
+}
+
+// replace replaces the lost connection and session of ses with a new one,
+// opened by reopen, and prepares the open statements of ses again.
+// The Ses and its Stmts remain usable, their open Rsets are closed.
+func (ses *Ses) replace(reopen func() (*Ses, error)) error {
+	if ses.openTxs.len() != 0 {
+		return errF("session in a transaction cannot be replaced")
+	}
+	nses, err := reopen()
+	if err != nil {
+		return err
+	}
+	ses.logF(ses.logCfg().Ses.Close, "replace lost session with %s", nses.sysName())
+
+	// swap the handles: nses gets the lost ones, to be closed
+	ses.cmu.Lock()
+	ses.Lock()
+	nses.Lock()
+	oldSrv, newSrv := ses.srv, nses.srv
+	oldEnv := ses.Env()
+	ses.srv, nses.srv = nses.srv, ses.srv
+	ses.ocisvcctx, nses.ocisvcctx = nses.ocisvcctx, ses.ocisvcctx
+	ses.ocises, nses.ocises = nses.ocises, ses.ocises
+	ses.openedAt, nses.openedAt = nses.openedAt, ses.openedAt
+	ses.stmtCacheSize, nses.stmtCacheSize = nses.stmtCacheSize, ses.stmtCacheSize
+	ses.initTag, nses.initTag = nses.initTag, ses.initTag
+	ses.env.Store(nses.Env())
+	nses.env.Store(oldEnv)
+	ses.ctxClientInfo = false
+	nses.insteadClose = nil
+	atomic.StoreInt32(&ses.badConn, 0)
+	nses.Unlock()
+	ses.Unlock()
+	ses.cmu.Unlock()
+	newSrv.openSess.remove(nses)
+	newSrv.openSess.add(ses)
+	oldSrv.openSess.remove(ses)
+	oldSrv.openSess.add(nses)
+
+	for _, stmt := range ses.openStmts.all() {
+		if err := stmt.reprepare(oldEnv); err != nil {
+			ses.logF(true, "prepare %s again: %v", stmt.sysName(), err)
+		}
+	}
+	// close the lost handles, and the connection if it has no other sessions
+	if err := nses.Close(); err != nil {
+		ses.logF(ses.logCfg().Ses.Close, "close lost session: %v", err)
+	}
+	if oldSrv.openSess.len() == 0 {
+		if err := oldSrv.Close(); err != nil {
+			ses.logF(ses.logCfg().Ses.Close, "close lost connection: %v", err)
+		}
+	}
+	return nil
+}
+
+// reprepare prepares the statement again on its replaced session,
+// releasing its statement handle of oldEnv.
+func (stmt *Stmt) reprepare(oldEnv *Env) error {
+	errs := _drv.listPool.Get().(*list.List)
+	stmt.RLock()
+	ses, sql := stmt.ses, stmt.sql
+	old, oldCached := stmt.ocistmt, stmt.cached
+	openRsets := stmt.openRsets
+	stmt.RUnlock()
+	openRsets.closeAll(errs)
+	errs.Init()
+	_drv.listPool.Put(errs)
+
+	ocistmt, cached, err := ses.prepare(sql)
+	if err != nil {
+		return err
+	}
+	stmt.Lock()
+	stmt.ocistmt, stmt.cached = ocistmt, cached
+	stmt.env.Store(ses.Env())
+	stmt.Unlock()
+	if old != nil {
+		// the connection is lost: the release fails on the server
+		releaseOCIStmt(oldEnv, old, sql, oldCached)
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+// Copyright 2014 Rana Ian. All rights reserved.
+// Use of this source code is governed by The MIT License
+// found in the accompanying LICENSE file.
+
+package ora
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func noBackoff(int) time.Duration { return 0 }
+
+func TestRetryPolicyDo(t *testing.T) {
+	errLost, errAuth := &ORAError{code: 3113}, &ORAError{code: 1017}
+	for i, tc := range []struct {
+		rp    RetryPolicy
+		errs  []error
+		calls int
+		err   error
+	}{
+		{rp: RetryPolicy{}, errs: []error{errLost}, calls: 1, err: errLost},
+		{rp: RetryPolicy{MaxAttempts: 3, Backoff: noBackoff}, errs: []error{errLost, errLost, nil}, calls: 3},
+		{rp: RetryPolicy{MaxAttempts: 2, Backoff: noBackoff}, errs: []error{errLost, errLost, nil}, calls: 2, err: errLost},
+		{rp: RetryPolicy{MaxAttempts: 3, Backoff: noBackoff}, errs: []error{errAuth, nil}, calls: 1, err: errAuth},
+		{rp: RetryPolicy{MaxAttempts: 3, Backoff: noBackoff,
+			Retryable: func(err error) bool { return ErrorCode(err) == 1017 }},
+			errs: []error{errAuth, errLost, nil}, calls: 2, err: errLost},
+	} {
+		var calls int
+		err := tc.rp.Do(context.Background(), func(attempt int) error {
+			calls++
+			if attempt != calls {
+				t.Errorf("%d. got attempt %d, wanted %d", i, attempt, calls)
+			}
+			return tc.errs[attempt-1]
+		})
+		if err != tc.err || calls != tc.calls {
+			t.Errorf("%d. got %d calls, %v; wanted %d, %v", i, calls, err, tc.calls, tc.err)
+		}
+	}
+
+	// a done context stops the retries
+	ctx, cancel := context.WithCancel(context.Background())
+	rp := RetryPolicy{MaxAttempts: 5, Backoff: func(int) time.Duration { return time.Minute }}
+	var calls int
+	start := time.Now()
+	err := rp.Do(ctx, func(int) error {
+		calls++
+		cancel()
+		return errLost
+	})
+	if err != errLost || calls != 1 || time.Since(start) > 10*time.Second {
+		t.Errorf("got %d calls, %v in %s", calls, err, time.Since(start))
+	}
+}
+
+func TestExponentialBackoff(t *testing.T) {
+	b := ExponentialBackoff(100*time.Millisecond, time.Second)
+	for i, max := range []time.Duration{
+		100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond,
+		800 * time.Millisecond, time.Second, time.Second,
+	} {
+		if d := b(i + 1); d < max/2 || d > max {
+			t.Errorf("%d. got %s, wanted %s..%s", i+1, d, max/2, max)
+		}
+	}
+	if d := ExponentialBackoff(0, time.Second)(3); d != 0 {
+		t.Errorf("got %s for zero base", d)
+	}
+}
+
+func TestPoolGetRetry(t *testing.T) {
+	var calls int
+	errs := []error{&ORAError{code: 12541}, &ORAError{code: 3113}, nil}
+	p := &Pool{ses: newIdlePool(1), srv: newIdlePool(1)}
+	p.newSes = func() (*Ses, error) {
+		calls++
+		if err := errs[calls-1]; err != nil {
+			return nil, err
+		}
+		return &Ses{}, nil
+	}
+
+	if _, err := p.Get(); err == nil || calls != 1 {
+		t.Fatalf("got %d calls, %v without a RetryPolicy", calls, err)
+	}
+
+	calls = 0
+	rp := RetryPolicy{MaxAttempts: 3, Backoff: noBackoff}
+	p.SetRetryPolicy(rp)
+	ses, err := p.Get()
+	if err != nil || calls != 3 {
+		t.Fatalf("got %d calls, %v", calls, err)
+	}
+	if ses.retry.MaxAttempts != rp.MaxAttempts || ses.reopen == nil || ses.insteadClose == nil {
+		t.Errorf("session got no retry policy")
+	}
+	if st := p.Stats(); st.Misses != 2 {
+		t.Errorf("got %d misses, wanted 2", st.Misses)
+	}
+
+	// not retryable
+	calls, errs[0] = 0, &ORAError{code: 1017}
+	if _, err = p.Get(); ErrorCode(err) != 1017 || calls != 1 {
+		t.Errorf("got %d calls, %v", calls, err)
+	}
+}
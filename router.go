@@ -0,0 +1,315 @@
+// Copyright 2014 Rana Ian. All rights reserved.
+// Use of this source code is governed by The MIT License
+// found in the accompanying LICENSE file.
+
+package ora
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultRouterCheckInterval is the CheckInterval of a RouterCfg without one.
+	DefaultRouterCheckInterval = 10 * time.Second
+
+	// DefaultLagQuery queries the apply lag of an Active Data Guard standby.
+	DefaultLagQuery = "SELECT value FROM v$dataguard_stats WHERE name = 'apply lag'"
+)
+
+// RouterCfg configures the replica checks of a Router, see NewRouter.
+type RouterCfg struct {
+	// MaxLag is the maximum apply lag of a replica the read-only work is routed to.
+	// Zero disables the staleness guard: the replicas are only pinged.
+	MaxLag time.Duration
+
+	// CheckInterval is the interval of the background checks of the replicas.
+	// If CheckInterval <= 0, then DefaultRouterCheckInterval is used.
+	CheckInterval time.Duration
+
+	// LagQuery returns the apply lag of the replica in its first column, as an
+	// INTERVAL DAY TO SECOND ("+DD HH:MI:SS[.FF]"), or as a number of seconds.
+	// No row, or NULL means an unknown lag, a stale replica.
+	// If empty, then DefaultLagQuery is used.
+	LagQuery string
+}
+
+// ReplicaState is the state of a replica pool of a Router, see Router.Replicas.
+type ReplicaState struct {
+	Healthy   bool          // the read-only work is routed to it
+	Lag       time.Duration // apply lag of the last check
+	Err       error         // error of the last check
+	CheckedAt time.Time     // time of the last check, zero before the first one
+}
+
+type replica struct {
+	pool *Pool
+	ReplicaState
+}
+
+// Router routes the sessions of read-only work to replica pools, as Active Data
+// Guard standbys, everything else to the primary pool.
+//
+// The replicas are checked in the background: a replica is used only if it
+// answers, with an apply lag of at most MaxLag. Without a healthy replica,
+// the read-only work is routed to the primary, too.
+//
+// A Router is also a driver.Connector (Go 1.10+): sql.OpenDB(router) routes
+// the transactions begun with sql.TxOptions{ReadOnly: true} to the replicas.
+// The statements prepared before such a transaction cannot run in it
+// (as with Tx.Stmt): prepare them in the transaction.
+type Router struct {
+	primary *Pool
+	cfg     RouterCfg
+	// check returns the apply lag of a replica
+	check func(*Pool) (time.Duration, error)
+	now   func() time.Time
+
+	mu       sync.Mutex
+	replicas []*replica
+	next     int
+	stop     chan struct{}
+}
+
+// NewRouter returns a Router of the primary and the replicas pools,
+// starting the background checks of the replicas.
+//
+// The read-only work is routed to the primary till the first check of a replica ends.
+// Close stops the checks; the pools are not closed.
+func NewRouter(primary *Pool, replicas []*Pool, cfg RouterCfg) *Router {
+	if cfg.CheckInterval <= 0 {
+		cfg.CheckInterval = DefaultRouterCheckInterval
+	}
+	if cfg.LagQuery == "" {
+		cfg.LagQuery = DefaultLagQuery
+	}
+	r := &Router{primary: primary, cfg: cfg, now: time.Now, stop: make(chan struct{})}
+	r.check = r.checkReplica
+	for _, p := range replicas {
+		r.replicas = append(r.replicas, &replica{pool: p})
+	}
+	if len(r.replicas) != 0 {
+		go r.run(r.stop)
+	}
+	return r
+}
+
+// Primary returns the primary pool.
+func (r *Router) Primary() *Pool { return r.primary }
+
+// Get returns a session of the primary pool.
+func (r *Router) Get() (*Ses, error) { return r.primary.Get() }
+
+// GetReadOnly returns a session of a healthy replica pool, round robin,
+// or of the primary if no replica is healthy, or it fails to give a session.
+func (r *Router) GetReadOnly() (*Ses, error) {
+	if ses := r.getReplica(); ses != nil {
+		return ses, nil
+	}
+	return r.primary.Get()
+}
+
+// getReplica returns a session of a healthy replica pool, round robin,
+// nil if there is none. A failed replica is marked unhealthy.
+func (r *Router) getReplica() *Ses {
+	for _, rp := range r.healthy() {
+		ses, err := rp.pool.Get()
+		if err == nil {
+			return ses
+		}
+		r.report(rp, 0, err)
+	}
+	return nil
+}
+
+// GetContext returns a session for the work of ctx: of a replica,
+// if ctx is marked by WithReadOnly, of the primary otherwise.
+func (r *Router) GetContext(ctx context.Context) (*Ses, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if ctxReadOnly(ctx) {
+		return r.GetReadOnly()
+	}
+	return r.Get()
+}
+
+// Replicas returns the states of the replica pools, in the order of NewRouter.
+func (r *Router) Replicas() []ReplicaState {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	states := make([]ReplicaState, len(r.replicas))
+	for i, rp := range r.replicas {
+		states[i] = rp.ReplicaState
+	}
+	return states
+}
+
+// Close stops the background checks of the replicas.
+func (r *Router) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.stop != nil {
+		close(r.stop)
+		r.stop = nil
+	}
+	return nil
+}
+
+// healthy returns the healthy replicas, starting with the next one in turn.
+func (r *Router) healthy() []*replica {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	n := len(r.replicas)
+	healthy := make([]*replica, 0, n)
+	for i := 0; i < n; i++ {
+		if rp := r.replicas[(r.next+i)%n]; rp.Healthy {
+			healthy = append(healthy, rp)
+		}
+	}
+	if n != 0 {
+		r.next = (r.next + 1) % n
+	}
+	return healthy
+}
+
+// report records the result of a check of rp.
+func (r *Router) report(rp *replica, lag time.Duration, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	rp.Lag, rp.Err, rp.CheckedAt = lag, err, r.now()
+	rp.Healthy = err == nil && (r.cfg.MaxLag <= 0 || lag <= r.cfg.MaxLag)
+}
+
+// checkAll checks the replicas.
+func (r *Router) checkAll() {
+	r.mu.Lock()
+	replicas := append([]*replica(nil), r.replicas...)
+	r.mu.Unlock()
+	for _, rp := range replicas {
+		lag, err := r.check(rp.pool)
+		r.report(rp, lag, err)
+	}
+}
+
+// run checks the replicas each CheckInterval, till stop is closed.
+func (r *Router) run(stop <-chan struct{}) {
+	r.checkAll()
+	ticker := time.NewTicker(r.cfg.CheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			r.checkAll()
+		}
+	}
+}
+
+// checkReplica pings the replica, and queries its apply lag with the LagQuery.
+func (r *Router) checkReplica(p *Pool) (time.Duration, error) {
+	ses, err := p.Get()
+	if err != nil {
+		return 0, err
+	}
+	defer ses.Close()
+	if r.cfg.MaxLag <= 0 {
+		return 0, ses.Ping()
+	}
+	rset, err := ses.PrepAndQry(r.cfg.LagQuery)
+	if err != nil {
+		return 0, err
+	}
+	var v interface{}
+	if rset.Next() {
+		v = rset.Row[0]
+		for rset.Next() {
+		}
+	}
+	if err = rset.Err(); err != nil {
+		return 0, err
+	}
+	switch x := v.(type) {
+	case nil:
+	case IntervalDS:
+		if !x.IsNull {
+			return time.Duration(x.Day)*24*time.Hour + time.Duration(x.Hour)*time.Hour +
+				time.Duration(x.Minute)*time.Minute + time.Duration(x.Second)*time.Second +
+				time.Duration(x.Nanosecond), nil
+		}
+	default:
+		if s := fmt.Sprint(x); s != "" {
+			return parseApplyLag(s)
+		}
+	}
+	return 0, errNew("the apply lag of the replica is unknown")
+}
+
+// parseApplyLag parses an INTERVAL DAY TO SECOND as "+DD HH:MI:SS[.FF]",
+// as in V$DATAGUARD_STATS, or a number of seconds.
+func parseApplyLag(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	if secs, err := strconv.ParseFloat(s, 64); err == nil {
+		if secs < 0 {
+			return 0, errF("negative apply lag %q", s)
+		}
+		return time.Duration(secs * float64(time.Second)), nil
+	}
+	i := strings.IndexByte(s, ' ')
+	if i < 0 {
+		return 0, errF("cannot parse apply lag %q", s)
+	}
+	day, err := strconv.Atoi(strings.TrimPrefix(s[:i], "+"))
+	if err != nil || day < 0 {
+		return 0, errF("cannot parse the days of apply lag %q", s)
+	}
+	hms := strings.Split(s[i+1:], ":")
+	if len(hms) != 3 {
+		return 0, errF("cannot parse apply lag %q", s)
+	}
+	hour, err1 := strconv.Atoi(hms[0])
+	min, err2 := strconv.Atoi(hms[1])
+	sec, err3 := strconv.ParseFloat(hms[2], 64)
+	if err1 != nil || err2 != nil || err3 != nil || hour < 0 || min < 0 || sec < 0 {
+		return 0, errF("cannot parse apply lag %q", s)
+	}
+	return time.Duration(day)*24*time.Hour + time.Duration(hour)*time.Hour +
+		time.Duration(min)*time.Minute + time.Duration(sec*float64(time.Second)), nil
+}
+
+// route switches the connection to a session of a healthy replica of its Router,
+// for a read-only transaction. Without one, the connection stays on the primary.
+func (con *Con) route() {
+	if ses := con.router.getReplica(); ses != nil {
+		con.primary, con.ses = con.ses, ses
+	}
+}
+
+// unroute puts the replica session of a finished read-only transaction
+// back to its pool, switching the connection back to the primary session.
+//
+// database/sql closes the statements of the transaction after its end,
+// so the switch waits for the next use of the connection.
+func (con *Con) unroute() {
+	if con.primary == nil || con.ses.openTxs.len() != 0 {
+		return
+	}
+	con.ses.Close()
+	con.ses, con.primary = con.primary, nil
+}
+
+// endRoute rolls back the transactions left open on the replica session, and unroutes.
+func (con *Con) endRoute() {
+	if con.primary == nil {
+		return
+	}
+	for _, tx := range con.ses.openTxs.all() {
+		tx.Rollback()
+	}
+	con.unroute()
+}
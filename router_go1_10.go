@@ -0,0 +1,44 @@
+// +build go1.10
+
+// Copyright 2014 Rana Ian. All rights reserved.
+// Use of this source code is governed by The MIT License
+// found in the accompanying LICENSE file.
+
+package ora
+
+import (
+	"context"
+	"database/sql/driver"
+)
+
+// Ensure that Router implements driver.Connector.
+var _ = driver.Connector((*Router)(nil))
+
+// Connect returns a connection of a session of the primary pool;
+// its transactions begun as read-only switch to a session of a replica.
+// Closing the connection puts its session back to the pool.
+//
+// Connect is a member of the driver.Connector interface.
+func (r *Router) Connect(ctx context.Context) (driver.Conn, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	ses, err := r.Get()
+	if err != nil {
+		return nil, maybeBadConn(err)
+	}
+	con := _drv.conPool.Get().(*Con)
+	con.env, con.ses, con.router = ses.Env(), ses, r
+	if con.id == 0 {
+		con.id = _drv.conId.nextId()
+	}
+	con.env.RLock()
+	con.env.openCons.add(con)
+	con.env.RUnlock()
+	return con, nil
+}
+
+// Driver returns the ora driver.
+//
+// Driver is a member of the driver.Connector interface.
+func (r *Router) Driver() driver.Driver { return _drv }
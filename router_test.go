@@ -0,0 +1,98 @@
+// Copyright 2014 Rana Ian. All rights reserved.
+// Use of this source code is governed by The MIT License
+// found in the accompanying LICENSE file.
+
+package ora
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestParseApplyLag(t *testing.T) {
+	for _, tc := range []struct {
+		in   string
+		want time.Duration
+		ok   bool
+	}{
+		{"+00 00:00:03", 3 * time.Second, true},
+		{"+01 02:03:04.5", 26*time.Hour + 3*time.Minute + 4500*time.Millisecond, true},
+		{" 12.25 ", 12250 * time.Millisecond, true},
+		{"0", 0, true},
+		{"-1", 0, false},
+		{"+00 00:03", 0, false},
+		{"unknown", 0, false},
+	} {
+		got, err := parseApplyLag(tc.in)
+		if (err == nil) != tc.ok || got != tc.want {
+			t.Errorf("%q: got %s, %v; wanted %s", tc.in, got, err, tc.want)
+		}
+	}
+}
+
+// newTestPool returns a Pool of sessions opened by newSes.
+func newTestPool(newSes func() (*Ses, error)) *Pool {
+	return &Pool{ses: newIdlePool(1), srv: newIdlePool(1), newSes: newSes}
+}
+
+func TestRouter(t *testing.T) {
+	sessions := make(map[*Ses]string)
+	pool := func(name string) *Pool {
+		return newTestPool(func() (*Ses, error) {
+			ses := &Ses{}
+			sessions[ses] = name
+			return ses, nil
+		})
+	}
+	primary, a, b := pool("primary"), pool("a"), pool("b")
+	down := newTestPool(func() (*Ses, error) { return nil, &ORAError{code: 12541} })
+	lags := map[*Pool]time.Duration{a: time.Second, b: time.Minute}
+	r := &Router{primary: primary, cfg: RouterCfg{MaxLag: 10 * time.Second}, now: time.Now}
+	r.check = func(p *Pool) (time.Duration, error) { return lags[p], nil }
+	for _, p := range []*Pool{a, b, down} {
+		r.replicas = append(r.replicas, &replica{pool: p})
+	}
+
+	get := func(ctx context.Context) string {
+		ses, err := r.GetContext(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return sessions[ses]
+	}
+	ro := WithReadOnly(context.Background())
+	if got := get(ro); got != "primary" {
+		t.Errorf("before the check: got %q, wanted the primary", got)
+	}
+
+	r.checkAll()
+	st := r.Replicas()
+	if !st[0].Healthy || st[1].Healthy || !st[2].Healthy || st[1].Lag != time.Minute {
+		t.Errorf("got %+v, wanted b stale", st)
+	}
+	for i := 0; i < 3; i++ {
+		if got := get(ro); got != "a" {
+			t.Errorf("%d. got %q, wanted a", i, got)
+		}
+	}
+	if st = r.Replicas(); st[2].Healthy || ErrorCode(st[2].Err) != 12541 {
+		t.Errorf("got %+v, wanted the failed replica unhealthy", st[2])
+	}
+	if got := get(context.Background()); got != "primary" {
+		t.Errorf("got %q, wanted the primary", got)
+	}
+
+	// no healthy replica: fall back to the primary
+	lags[a] = time.Hour
+	r.checkAll()
+	if got := get(ro); got != "primary" {
+		t.Errorf("got %q, wanted the primary", got)
+	}
+	// no staleness guard
+	r.cfg.MaxLag = 0
+	r.checkAll()
+	if got := get(ro); got != "a" && got != "b" {
+		t.Errorf("got %q, wanted a replica", got)
+	}
+}
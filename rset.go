@@ -10,6 +10,7 @@ package ora
 import "C"
 import (
 	"container/list"
+	"context"
 	"fmt"
 	"io"
 	"sync"
@@ -96,6 +97,8 @@ type Rset struct {
 	fetched, offset int64
 	fetchLen        int
 	finished        bool
+	// ctx is the context of the last fetch, used by the LOB readers of the row.
+	ctx context.Context
 
 	sysNamer
 }
@@ -176,7 +179,7 @@ func (rset *Rset) closeWithRemove() (err error) {
 
 // close releases allocated resources.
 func (rset *Rset) close() (err error) {
-	rset.log(_drv.Cfg().Log.Rset.Close)
+	rset.log(rset.logCfg().Rset.Close)
 
 	defer func() {
 		if value := recover(); value != nil {
@@ -224,26 +227,28 @@ func (rset *Rset) close() (err error) {
 }
 
 // beginRow allocates a handle for each column and fetches one row.
-func (rset *Rset) beginRow() (err error) {
-	rset.log(_drv.Cfg().Log.Rset.BeginRow)
+// The fetch is interrupted as ctx is done, or after the StmtCfg.Timeout.
+func (rset *Rset) beginRow(ctx context.Context) (err error) {
+	rset.log(rset.logCfg().Rset.BeginRow)
 	rset.Lock()
 	defer rset.Unlock()
+	rset.ctx = ctx
 
 	fetched, offset, finished := rset.fetched, rset.offset, rset.finished
 	ocistmt := rset.ocistmt
 
-	rset.logF(_drv.Cfg().Log.Rset.BeginRow, "fetched=%d offset=%d finished=%t", fetched, offset, finished)
+	rset.logF(rset.logCfg().Rset.BeginRow, "fetched=%d offset=%d finished=%t", fetched, offset, finished)
 	if fetched > 0 && fetched > offset {
 		atomic.AddInt32(&rset.index, 1)
 		return nil
 	}
 	if finished {
-		rset.log(_drv.Cfg().Log.Rset.BeginRow, "finished")
+		rset.log(rset.logCfg().Rset.BeginRow, "finished")
 		return io.EOF
 	}
 	// check is open
 	if ocistmt == nil {
-		rset.log(_drv.Cfg().Log.Rset.BeginRow, "Rset is closed")
+		rset.log(rset.logCfg().Rset.BeginRow, "Rset is closed")
 		return io.EOF
 	}
 	// allocate define descriptor handles
@@ -252,7 +257,7 @@ func (rset *Rset) beginRow() (err error) {
 	}
 	env := rset.env
 	for _, define := range rset.defs {
-		//rset.logF(_drv.Cfg().Log.Rset.BeginRow, "defs[%d]=%#v", i, define)
+		//rset.logF(rset.logCfg().Rset.BeginRow, "defs[%d]=%#v", i, define)
 		if define == nil {
 			continue
 		}
@@ -263,6 +268,10 @@ func (rset *Rset) beginRow() (err error) {
 	}
 
 	rset.finished = false
+	done := func(err error) error { return err }
+	if stmt := rset.stmt; stmt != nil && stmt.ses != nil {
+		done = stmt.ses.watchCall(ctx, stmt.Cfg().Timeout())
+	}
 	// fetch rset.fetchLen rows
 	r := C.OCIStmtFetch2(
 		rset.ocistmt,         //OCIStmt     *stmthp,
@@ -271,11 +280,17 @@ func (rset *Rset) beginRow() (err error) {
 		C.OCI_FETCH_NEXT,     //ub2         orientation,
 		C.sb4(0),             //sb4         fetchOffset,
 		C.OCI_DEFAULT)        //ub4         mode );
+	if r == C.OCI_NEED_DATA {
+		if r, err = rset.fetchPieces(); err != nil {
+			return done(err)
+		}
+	}
 	if r == C.OCI_ERROR {
-		err := env.ociError()
-		return err
-	} else if r == C.OCI_NO_DATA {
-		rset.log(_drv.Cfg().Log.Rset.BeginRow, "OCI_NO_DATA")
+		return done(env.ociError())
+	}
+	done(nil)
+	if r == C.OCI_NO_DATA {
+		rset.log(rset.logCfg().Rset.BeginRow, "OCI_NO_DATA")
 		rset.finished = true
 		fetchLen := rset.fetchLen
 		if fetchLen == 1 {
@@ -306,9 +321,60 @@ func (rset *Rset) beginRow() (err error) {
 	return err
 }
 
+// fetchPieces completes a piecewise fetch of LONG and LONG RAW columns:
+// OCIStmtFetch2 returns OCI_NEED_DATA till all pieces are fetched.
+func (rset *Rset) fetchPieces() (C.sword, error) {
+	env := rset.env
+	r := C.sword(C.OCI_NEED_DATA)
+	for r == C.OCI_NEED_DATA {
+		var hndl unsafe.Pointer
+		var hndlType, iter, idx C.ub4
+		var inOut, piece C.ub1
+		if r = C.OCIStmtGetPieceInfo(
+			rset.ocistmt, //OCIStmt  *stmtp,
+			env.ocierr,   //OCIError *errhp,
+			&hndl,        //void     **hndlpp,
+			&hndlType,    //ub4      *typep,
+			&inOut,       //ub1      *in_outp,
+			&iter,        //ub4      *iterp,
+			&idx,         //ub4      *idxp,
+			&piece,       //ub1      *piecep );
+		); r == C.OCI_ERROR {
+			return r, env.ociError("OCIStmtGetPieceInfo")
+		}
+		var def *defLong
+		for _, d := range rset.defs {
+			if dl, ok := d.(*defLong); ok && unsafe.Pointer(dl.ocidef) == hndl {
+				def = dl
+				break
+			}
+		}
+		if def == nil {
+			// only LONG and LONG RAW are defined dynamically
+			return C.OCI_ERROR, errF("no define for piece handle %p (type %d)", hndl, hndlType)
+		}
+		if err := def.nextPiece(piece); err != nil {
+			return C.OCI_ERROR, err
+		}
+		r = C.OCIStmtFetch2(
+			rset.ocistmt,         //OCIStmt     *stmthp,
+			env.ocierr,           //OCIError    *errhp,
+			C.ub4(rset.fetchLen), //ub4         nrows,
+			C.OCI_FETCH_NEXT,     //ub2         orientation,
+			C.sb4(0),             //sb4         fetchOffset,
+			C.OCI_DEFAULT)        //ub4         mode );
+	}
+	for _, d := range rset.defs {
+		if dl, ok := d.(*defLong); ok {
+			dl.endPiece()
+		}
+	}
+	return r, nil
+}
+
 // endRow deallocates a handle for each column.
 func (rset *Rset) endRow() {
-	rset.log(_drv.Cfg().Log.Rset.EndRow)
+	rset.log(rset.logCfg().Rset.EndRow)
 	rset.Lock()
 	defer rset.Unlock()
 	done := rset.finished && !(rset.fetched > 0 && rset.fetched > rset.offset)
@@ -333,7 +399,7 @@ func (rset *Rset) Exhaust() {
 		return
 	}
 	for {
-		err := rset.beginRow()
+		err := rset.beginRow(context.Background())
 		rset.endRow()
 		if err != nil {
 			return
@@ -349,7 +415,13 @@ func (rset *Rset) Exhaust() {
 //
 // When Next returns false check Rset.Err() for any error that may have occured.
 func (rset *Rset) Next() bool {
-	rset.log(_drv.Cfg().Log.Rset.Next)
+	return rset.NextContext(context.Background())
+}
+
+// NextContext loads the next row as Next does, and interrupts the fetch
+// from the server as ctx is done. The LOBs of the row (L) are read with ctx, too.
+func (rset *Rset) NextContext(ctx context.Context) bool {
+	rset.log(rset.logCfg().Rset.Next)
 	erase := func(err error) {
 		rset.Lock()
 		rset.err = err
@@ -372,9 +444,9 @@ func (rset *Rset) Next() bool {
 		erase(err)
 		return false
 	}
-	err := rset.beginRow()
+	err := rset.beginRow(ctx)
 	defer rset.endRow()
-	rset.logF(_drv.Cfg().Log.Rset.Next, "beginRow=%v", err)
+	rset.logF(rset.logCfg().Rset.Next, "beginRow=%v", err)
 	if err != nil {
 		// io.EOF means no more data; return nil err
 		if err == io.EOF {
@@ -391,7 +463,7 @@ func (rset *Rset) Next() bool {
 	rset.RUnlock()
 	for n, define := range defs {
 		value, err := define.value(int(offset))
-		//rset.logF(_drv.Cfg().Log.Rset.Next, "value[%d]=%v (%v)", n, value, err)
+		//rset.logF(rset.logCfg().Rset.Next, "value[%d]=%v (%v)", n, value, err)
 		if err != nil {
 			erase(err)
 			return false
@@ -402,7 +474,7 @@ func (rset *Rset) Next() bool {
 	rset.defs = defs
 	rset.Row = Row
 	rset.Unlock()
-	//rset.logF(_drv.Cfg().Log.Rset.Next, "Row=%#v", rset.Row)
+	//rset.logF(rset.logCfg().Rset.Next, "Row=%#v", rset.Row)
 	return true
 }
 
@@ -429,7 +501,7 @@ func (rset *Rset) putDef(idx int, def def) {
 
 // Open defines select-list columns.
 func (rset *Rset) open(stmt *Stmt, ocistmt *C.OCIStmt) error {
-	logCfg := _drv.Cfg().Log
+	logCfg := rset.logCfg()
 	rset.Lock()
 	defer rset.Unlock()
 
@@ -558,6 +630,13 @@ func (rset *Rset) open(stmt *Stmt, ocistmt *C.OCIStmt) error {
 		lobFetchLen = cfg.lobFetchLen
 	}
 
+	for _, param := range params {
+		switch param.typeCode {
+		case C.SQLT_LNG, C.SQLT_LBI:
+			// LONG and LONG RAW are fetched piecewise, one row at a time.
+			lobFetchLen = 1
+		}
+	}
 	if fetchLen != lobFetchLen {
 	Loop:
 		for _, param := range params {
@@ -765,6 +844,8 @@ func (rset *Rset) open(stmt *Stmt, ocistmt *C.OCIStmt) error {
 			// LONG
 			if gcts == nil || n >= len(gcts) || gcts[n] == D {
 				gct = cfg.long
+			} else if gcts[n] == L {
+				gct = L
 			} else {
 				err = checkStringColumn(gcts[n])
 				if err != nil {
@@ -773,8 +854,10 @@ func (rset *Rset) open(stmt *Stmt, ocistmt *C.OCIStmt) error {
 				gct = gcts[n]
 			}
 
-			// longBufferSize: Use a moderate default buffer size; 2GB max buffer may not be feasible on all clients
-			defs[n], err = rset.defineString(n, stmt.Cfg().longBufferSize, gct, false)
+			// fetched piecewise, longBufferSize is the maximum size of a piece
+			def := rset.getDef(defIdxLong).(*defLong)
+			defs[n] = def
+			err = def.define(n+1, false, stmt.Cfg().longBufferSize, gct, rset)
 			if err != nil {
 				return err
 			}
@@ -843,6 +926,8 @@ func (rset *Rset) open(stmt *Stmt, ocistmt *C.OCIStmt) error {
 			// LONG RAW
 			if gcts == nil || n >= len(gcts) || gcts[n] == D {
 				gct = cfg.longRaw
+			} else if gcts[n] == L {
+				gct = L
 			} else {
 				err = checkBinColumn(gcts[n])
 				if err != nil {
@@ -850,13 +935,10 @@ func (rset *Rset) open(stmt *Stmt, ocistmt *C.OCIStmt) error {
 				}
 				gct = gcts[n]
 			}
-			isNullable := false
-			if gct == OraBin {
-				isNullable = true
-			}
-			def := rset.getDef(defIdxLongRaw).(*defLongRaw)
+			// fetched piecewise, longRawBufferSize is the maximum size of a piece
+			def := rset.getDef(defIdxLong).(*defLong)
 			defs[n] = def
-			err = def.define(n+1, cfg.longRawBufferSize, isNullable, rset)
+			err = def.define(n+1, true, cfg.longRawBufferSize, gct, rset)
 			if err != nil {
 				return err
 			}
@@ -876,9 +958,13 @@ func (rset *Rset) open(stmt *Stmt, ocistmt *C.OCIStmt) error {
 			}
 		case C.SQLT_FILE:
 			// BFILE
+			gct = D
+			if gcts != nil && n < len(gcts) && gcts[n] == L {
+				gct = L
+			}
 			def := rset.getDef(defIdxBfile).(*defBfile)
 			defs[n] = def
-			err = def.define(n+1, rset)
+			err = def.define(n+1, gct, rset)
 			if err != nil {
 				return err
 			}
@@ -1025,9 +1111,17 @@ func (rset *Rset) sysName() string {
 	return rset.sysNamer.Name(func() string { return fmt.Sprintf("%sS%v", rset.stmt.sysName(), rset.id) })
 }
 
+// logCfg returns the LogDrvCfg of the Env of the Rset.
+func (rset *Rset) logCfg() LogDrvCfg {
+	if rset == nil {
+		return _drv.Cfg().Log
+	}
+	return rset.env.logCfg()
+}
+
 // log writes a message with an Rset system name and caller info.
 func (rset *Rset) log(enabled bool, v ...interface{}) {
-	logCfg := _drv.Cfg().Log
+	logCfg := rset.logCfg()
 	if !logCfg.IsEnabled(enabled) {
 		return
 	}
@@ -1040,7 +1134,7 @@ func (rset *Rset) log(enabled bool, v ...interface{}) {
 
 // log writes a formatted message with an Rset system name and caller info.
 func (rset *Rset) logF(enabled bool, format string, v ...interface{}) {
-	logCfg := _drv.Cfg().Log
+	logCfg := rset.logCfg()
 	if !logCfg.IsEnabled(enabled) {
 		return
 	}
@@ -0,0 +1,172 @@
+// Copyright 2014 Rana Ian. All rights reserved.
+// Use of this source code is governed by The MIT License
+// found in the accompanying LICENSE file.
+
+package ora
+
+import (
+	"context"
+	"fmt"
+)
+
+// checkSavepointName returns an error if name is not a simple SQL identifier,
+// as it is part of the SAVEPOINT statement.
+func checkSavepointName(name string) error {
+	return checkIdentifier("savepoint name", name)
+}
+
+// Savepoint sets a savepoint named name in the transaction,
+// to be rolled back to with RollbackTo.
+func (tx *Tx) Savepoint(name string) error {
+	if err := checkSavepointName(name); err != nil {
+		return err
+	}
+	if err := tx.checkIsOpen(); err != nil {
+		return err
+	}
+	tx.RLock()
+	ses := tx.ses
+	tx.RUnlock()
+	if _, err := ses.PrepAndExe("SAVEPOINT " + name); err != nil {
+		return err
+	}
+	tx.Lock()
+	tx.savepoints = append(tx.dropSavepoint(name), name)
+	tx.Unlock()
+	return nil
+}
+
+// RollbackTo rolls back the changes made after the savepoint name, which remains set.
+// The savepoints set after name are released.
+func (tx *Tx) RollbackTo(name string) error {
+	if err := tx.checkIsOpen(); err != nil {
+		return err
+	}
+	tx.RLock()
+	ses, i := tx.ses, tx.savepointIndex(name)
+	tx.RUnlock()
+	if i < 0 {
+		return errF("no savepoint %q", name)
+	}
+	if _, err := ses.PrepAndExe("ROLLBACK TO SAVEPOINT " + name); err != nil {
+		return err
+	}
+	tx.Lock()
+	if i = tx.savepointIndex(name); i >= 0 {
+		tx.savepoints = tx.savepoints[:i+1]
+	}
+	tx.Unlock()
+	return nil
+}
+
+// Release releases the savepoint name and the ones set after it:
+// they cannot be rolled back to anymore, their changes remain part of the transaction.
+//
+// Oracle has no RELEASE SAVEPOINT: the savepoints are only forgotten by the Tx.
+func (tx *Tx) Release(name string) error {
+	if err := tx.checkIsOpen(); err != nil {
+		return err
+	}
+	tx.Lock()
+	defer tx.Unlock()
+	i := tx.savepointIndex(name)
+	if i < 0 {
+		return errF("no savepoint %q", name)
+	}
+	tx.savepoints = tx.savepoints[:i]
+	return nil
+}
+
+// savepointIndex returns the index of the savepoint name, -1 if it is not set.
+//
+// Must be called with tx locked.
+func (tx *Tx) savepointIndex(name string) int {
+	for i := len(tx.savepoints) - 1; i >= 0; i-- {
+		if tx.savepoints[i] == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// dropSavepoint returns the savepoints without name,
+// as setting a savepoint again moves it.
+//
+// Must be called with tx locked.
+func (tx *Tx) dropSavepoint(name string) []string {
+	if i := tx.savepointIndex(name); i >= 0 {
+		return append(tx.savepoints[:i], tx.savepoints[i+1:]...)
+	}
+	return tx.savepoints
+}
+
+// InTx calls f in a transaction: it commits if f returns nil,
+// and rolls back if f returns an error or panics.
+//
+// Called in an open transaction of the session, InTx nests: it sets
+// a savepoint, rolls back to it on error, and leaves the commit
+// to the outer transaction. The Tx passed to f is the open transaction then.
+//
+// The sessions of database/sql are reachable with sql.Conn.Raw:
+//
+//	err := conn.Raw(func(driverConn interface{}) error {
+//		return driverConn.(*ora.Con).Ses().InTx(ctx, f)
+//	})
+func (ses *Ses) InTx(ctx context.Context, f func(*Tx) error) (err error) {
+	if err = ctx.Err(); err != nil {
+		return err
+	}
+	if err = ses.checkClosed(); err != nil {
+		return errE(err)
+	}
+	var outer *Tx
+	if txs := ses.openTxs.all(); len(txs) != 0 {
+		outer = txs[len(txs)-1]
+	}
+	if outer == nil {
+		var tx *Tx
+		if tx, err = ses.StartTx(); err != nil {
+			return err
+		}
+		defer func() {
+			if r := recover(); r != nil {
+				tx.Rollback()
+				panic(r)
+			}
+			if err == nil {
+				err = ctx.Err()
+			}
+			if err != nil {
+				tx.Rollback()
+				return
+			}
+			err = tx.Commit()
+		}()
+		return f(tx)
+	}
+
+	outer.Lock()
+	outer.spSeq++
+	name := fmt.Sprintf("ORA_INTX_%d", outer.spSeq)
+	outer.Unlock()
+	if err = outer.Savepoint(name); err != nil {
+		return err
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			outer.RollbackTo(name)
+			outer.Release(name)
+			panic(r)
+		}
+		if err == nil {
+			err = ctx.Err()
+		}
+		if err != nil {
+			if rbErr := outer.RollbackTo(name); rbErr != nil {
+				ses.logF(true, "rollback to %s: %v", name, rbErr)
+			}
+		}
+		outer.Release(name)
+	}()
+	return f(outer)
+}
@@ -7,11 +7,16 @@ package ora
 /*
 #include <oci.h>
 #include <stdlib.h>
+
+#ifndef OCI_ATTR_DBOP
+#define OCI_ATTR_DBOP 485
+#endif
 */
 import "C"
 import (
 	"bytes"
 	"container/list"
+	"context"
 	"errors"
 	"fmt"
 	"strconv"
@@ -27,6 +32,34 @@ type SesCfg struct {
 	Password string
 	Mode     SessionMode
 
+	// StmtCacheSize is the size of the OCI statement cache of the session.
+	// If zero, the Srv's SrvCfg.StmtCacheSize is used.
+	StmtCacheSize uint32
+
+	// Module is set as the MODULE attribute of the session, if not empty.
+	Module string
+
+	// ClientInfo are the default tracing attributes of the session.
+	// Its Module, if empty, is Module.
+	ClientInfo ClientInfo
+
+	// Timezone is the location of the DATE and TIMESTAMP values without time zone.
+	// If nil, it is NLS.TimeZone, or queried from the session's time zone, see Ses.Timezone.
+	Timezone *time.Location
+
+	// NLS are the ALTER SESSION settings applied to each new session.
+	NLS NLS
+	// Init are statements executed on each new session, after the NLS settings.
+	//
+	// Sessions of a DRCP or session pool are tagged with the settings,
+	// so the settings are applied once per session, not on each Srv.OpenSes.
+	Init []string
+
+	// ConnClass and Purity override the PoolCfg.ConnClass and PoolCfg.Purity
+	// of the Srv for the session, if not empty and not PurityDefault.
+	ConnClass string
+	Purity    Purity
+
 	StmtCfg
 }
 
@@ -46,6 +79,14 @@ func (c SesCfg) SetPrefetchMemorySize(prefetchMemorySize uint32) SesCfg {
 	c.StmtCfg = c.StmtCfg.SetPrefetchMemorySize(prefetchMemorySize)
 	return c
 }
+func (c SesCfg) SetTimeout(timeout time.Duration) SesCfg {
+	c.StmtCfg = c.StmtCfg.SetTimeout(timeout)
+	return c
+}
+func (c SesCfg) SetIdempotent(idempotent bool) SesCfg {
+	c.StmtCfg = c.StmtCfg.SetIdempotent(idempotent)
+	return c
+}
 func (c SesCfg) SetLongBufferSize(size uint32) SesCfg {
 	c.StmtCfg = c.StmtCfg.SetLongBufferSize(size)
 	return c
@@ -195,6 +236,11 @@ func NewLogSesCfg() LogSesCfg {
 
 // Ses is an Oracle session associated with a server.
 type Ses struct {
+	// statement cache counters, accessed atomically - keep them 64-bit aligned
+	stmtCacheHits, stmtCacheMisses uint64
+	// set on an ORA-03113 class error, accessed atomically
+	badConn int32
+
 	sync.RWMutex
 
 	cfg atomic.Value
@@ -207,11 +253,25 @@ type Ses struct {
 	ocises    *C.OCISession
 	isLocked  bool
 
+	stmtCacheSize uint32
+	openedAt      time.Time
+
 	openStmts *stmtList
 	openTxs   *txList
 
 	insteadClose func(ses *Ses) error
-	timezone     *time.Location
+	// pool is the Pool or SesPool which handed out the session, until it is put back
+	pool     interface{}
+	timezone *time.Location
+	// initTag is the session pool tag of the initialized session, see SesCfg.initTag
+	initTag string
+	// ctxClientInfo is set when a ClientInfo of a context has been set on the session
+	ctxClientInfo bool
+
+	// retry is the RetryPolicy of the statements, see SetRetryPolicy
+	retry RetryPolicy
+	// reopen opens a new session to replace the lost one, set by Pool
+	reopen func() (*Ses, error)
 
 	sysNamer
 }
@@ -281,7 +341,7 @@ func (ses *Ses) close() (err error) {
 	ses.cmu.Lock()
 	defer ses.cmu.Unlock()
 
-	ses.log(_drv.Cfg().Log.Ses.Close)
+	ses.log(ses.logCfg().Ses.Close)
 	err = ses.checkClosed()
 	if err != nil {
 		return errE(err)
@@ -298,6 +358,15 @@ func (ses *Ses) close() (err error) {
 		ses.srv = nil
 		ses.ocisvcctx = nil
 		ses.ocises = nil
+		ses.stmtCacheSize = 0
+		ses.openedAt = time.Time{}
+		ses.initTag = ""
+		ses.ctxClientInfo = false
+		ses.retry = RetryPolicy{}
+		ses.reopen = nil
+		atomic.StoreUint64(&ses.stmtCacheHits, 0)
+		atomic.StoreUint64(&ses.stmtCacheMisses, 0)
+		atomic.StoreInt32(&ses.badConn, 0)
 		ses.openStmts.clear()
 		ses.openTxs.clear()
 		ses.Unlock()
@@ -320,6 +389,7 @@ func (ses *Ses) close() (err error) {
 	openTxs, openStmts := ses.openTxs, ses.openStmts
 	env, srv := ses.Env(), ses.srv
 	ocises, ocisvcctx := ses.ocises, ses.ocisvcctx
+	initTag := ses.initTag
 	ses.RUnlock()
 	openTxs.closeAll(errs)
 	openStmts.closeAll(errs) // close statements
@@ -333,12 +403,20 @@ func (ses *Ses) close() (err error) {
 			ocises,        //OCISession      *usrhp,
 			C.OCI_DEFAULT) //ub4             mode );
 	} else {
+		var tag *C.OraText
+		mode := C.ub4(C.OCI_DEFAULT)
+		if initTag != "" {
+			// keep the tag of the initialized session for the next OCISessionGet
+			cTag := C.CString(initTag)
+			defer C.free(unsafe.Pointer(cTag))
+			tag, mode = (*C.OraText)(unsafe.Pointer(cTag)), C.OCI_SESSRLS_RETAG
+		}
 		r = C.OCISessionRelease(
-			ocisvcctx,     //OCISvcCtx       *svchp,
-			env.ocierr,    //OCIError        *errhp,
-			nil,           //OraText         *tag,
-			0,             //ub4             tag_len,
-			C.OCI_DEFAULT, //ub4             mode );
+			ocisvcctx,           //OCISvcCtx       *svchp,
+			env.ocierr,          //OCIError        *errhp,
+			tag,                 //OraText         *tag,
+			C.ub4(len(initTag)), //ub4             tag_len,
+			mode,                //ub4             mode );
 		)
 	}
 	if r == C.OCI_ERROR {
@@ -387,7 +465,7 @@ func (ses *Ses) prepAndExe(sql string, isAssocArray bool, params ...interface{})
 			err = errR(value)
 		}
 	}()
-	ses.log(_drv.Cfg().Log.Ses.PrepAndExe)
+	ses.log(ses.logCfg().Ses.PrepAndExe)
 	err = ses.checkClosed()
 	if err != nil {
 		return 0, errE(err)
@@ -423,7 +501,7 @@ func (ses *Ses) prepAndExe(sql string, isAssocArray bool, params ...interface{})
 // The *Stmt internal to this method is automatically closed when the *Rset
 // retrieves all rows or returns an error.
 func (ses *Ses) PrepAndQry(sql string, params ...interface{}) (rset *Rset, err error) {
-	ses.log(_drv.Cfg().Log.Ses.PrepAndQry)
+	ses.log(ses.logCfg().Ses.PrepAndQry)
 	err = ses.checkClosed()
 	if err != nil {
 		return nil, errE(err)
@@ -452,30 +530,16 @@ func (ses *Ses) Prep(sql string, gcts ...GoColumnType) (stmt *Stmt, err error) {
 			err = errR(value)
 		}
 	}()
-	ses.log(_drv.Cfg().Log.Ses.Prep, sql)
+	ses.log(ses.logCfg().Ses.Prep, sql)
 	err = ses.checkClosed()
 	if err != nil {
 		return nil, errE(err)
 	}
-	ocistmt := (*C.OCIStmt)(nil)
-	cSql := C.CString(sql) // prepare sql text with statement handle
-	ses.RLock()
-	env := ses.Env()
-	r := C.OCIStmtPrepare2(
-		ses.ocisvcctx,                      // OCISvcCtx     *svchp,
-		&ocistmt,                           // OCIStmt       *stmtp,
-		env.ocierr,                         // OCIError      *errhp,
-		(*C.OraText)(unsafe.Pointer(cSql)), // const OraText *stmt,
-		C.ub4(len(sql)),                    // ub4           stmt_len,
-		nil,                                // const OraText *key,
-		C.ub4(0),                           // ub4           keylen,
-		C.OCI_NTV_SYNTAX,                   // ub4           language,
-		C.OCI_DEFAULT)                      // ub4           mode );
-	ses.RUnlock()
-	C.free(unsafe.Pointer(cSql))
-	if r == C.OCI_ERROR {
-		return nil, errE(env.ociError())
+	ocistmt, cached, err := ses.prepare(sql)
+	if err != nil {
+		return nil, err
 	}
+	env := ses.Env()
 	// set stmt struct
 	stmt = _drv.stmtPool.Get().(*Stmt)
 	stmtCfg := ses.Cfg().StmtCfg
@@ -492,6 +556,7 @@ func (ses *Ses) Prep(sql string, gcts ...GoColumnType) (stmt *Stmt, err error) {
 	}
 	ses.RUnlock()
 	stmt.sql = sql
+	stmt.cached = cached
 	stmt.gcts = gcts
 	if stmt.id == 0 {
 		stmt.id = _drv.stmtId.nextId()
@@ -513,6 +578,55 @@ func (ses *Ses) Prep(sql string, gcts ...GoColumnType) (stmt *Stmt, err error) {
 	return stmt, nil
 }
 
+// prepare prepares sql on the session, with the statement cache if it has one.
+func (ses *Ses) prepare(sql string) (ocistmt *C.OCIStmt, cached bool, err error) {
+	cSql := C.CString(sql) // prepare sql text with statement handle
+	ses.RLock()
+	env := ses.Env()
+	cached = ses.stmtCacheSize > 0
+	var key *C.OraText
+	var keyLen C.ub4
+	var r C.sword
+	if cached {
+		// the sql text is the key in the statement cache
+		key, keyLen = (*C.OraText)(unsafe.Pointer(cSql)), C.ub4(len(sql))
+		r = C.OCIStmtPrepare2(
+			ses.ocisvcctx,                // OCISvcCtx     *svchp,
+			&ocistmt,                     // OCIStmt       *stmtp,
+			env.ocierr,                   // OCIError      *errhp,
+			nil,                          // const OraText *stmt,
+			C.ub4(0),                     // ub4           stmt_len,
+			key,                          // const OraText *key,
+			keyLen,                       // ub4           keylen,
+			C.OCI_NTV_SYNTAX,             // ub4           language,
+			C.OCI_PREP2_CACHE_SEARCHONLY) // ub4           mode );
+		if r == C.OCI_SUCCESS && ocistmt != nil {
+			atomic.AddUint64(&ses.stmtCacheHits, 1)
+		} else {
+			atomic.AddUint64(&ses.stmtCacheMisses, 1)
+			ocistmt = nil
+		}
+	}
+	if ocistmt == nil {
+		r = C.OCIStmtPrepare2(
+			ses.ocisvcctx,                      // OCISvcCtx     *svchp,
+			&ocistmt,                           // OCIStmt       *stmtp,
+			env.ocierr,                         // OCIError      *errhp,
+			(*C.OraText)(unsafe.Pointer(cSql)), // const OraText *stmt,
+			C.ub4(len(sql)),                    // ub4           stmt_len,
+			key,                                // const OraText *key,
+			keyLen,                             // ub4           keylen,
+			C.OCI_NTV_SYNTAX,                   // ub4           language,
+			C.OCI_DEFAULT)                      // ub4           mode );
+	}
+	ses.RUnlock()
+	C.free(unsafe.Pointer(cSql))
+	if r == C.OCI_ERROR {
+		return nil, false, errE(env.ociError())
+	}
+	return ocistmt, cached, nil
+}
+
 // Ins composes, prepares and executes a sql INSERT statement returning a
 // possible error.
 //
@@ -524,7 +638,7 @@ func (ses *Ses) Prep(sql string, gcts ...GoColumnType) (stmt *Stmt, err error) {
 // to the variadic parameter 'columnPairs' is expected to be a pointer capable
 // of receiving the identity value.
 func (ses *Ses) Ins(tbl string, columnPairs ...interface{}) (err error) {
-	ses.log(_drv.Cfg().Log.Ses.Ins)
+	ses.log(ses.logCfg().Ses.Ins)
 	err = ses.checkClosed()
 	if err != nil {
 		return errE(err)
@@ -589,7 +703,7 @@ func (ses *Ses) Ins(tbl string, columnPairs ...interface{}) (err error) {
 //
 // Upd offers convenience when specifying a long list of sql columns.
 func (ses *Ses) Upd(tbl string, columnPairs ...interface{}) (err error) {
-	ses.log(_drv.Cfg().Log.Ses.Upd)
+	ses.log(ses.logCfg().Ses.Upd)
 	err = ses.checkClosed()
 	if err != nil {
 		return errE(err)
@@ -657,7 +771,7 @@ func (ses *Ses) Upd(tbl string, columnPairs ...interface{}) (err error) {
 // name-GoColumnType pairs. The FROM clause may have additional SQL clauses
 // such as WHERE, HAVING, etc.
 func (ses *Ses) Sel(sqlFrom string, columnPairs ...interface{}) (rset *Rset, err error) {
-	ses.log(_drv.Cfg().Log.Ses.Sel)
+	ses.log(ses.logCfg().Ses.Sel)
 	err = ses.checkClosed()
 	if err != nil {
 		return nil, errE(err)
@@ -714,14 +828,18 @@ type TxOption func(*txOption)
 type txOption struct {
 	flags   uint32
 	timeout time.Duration
+	xid     *XID
 }
 
-func TxFlags(flags uint32) TxOption            { return func(o *txOption) { o.flags = flags } }
+func TxFlags(flags uint32) TxOption { return func(o *txOption) { o.flags = flags } }
+
+// TxTimeout sets the timeout of a global transaction (see TxXID),
+// rounded up to whole seconds: 500ms means one second.
 func TxTimeout(timeout time.Duration) TxOption { return func(o *txOption) { o.timeout = timeout } }
 
 // StartTx starts an Oracle transaction returning a *Tx and possible error.
 func (ses *Ses) StartTx(opts ...TxOption) (tx *Tx, err error) {
-	ses.log(_drv.Cfg().Log.Ses.StartTx)
+	ses.log(ses.logCfg().Ses.StartTx)
 	err = ses.checkClosed()
 	if err != nil {
 		return nil, errE(err)
@@ -731,6 +849,9 @@ func (ses *Ses) StartTx(opts ...TxOption) (tx *Tx, err error) {
 	for _, opt := range opts {
 		opt(&o)
 	}
+	if o.xid != nil {
+		return ses.startGlobalTx(*o.xid, C.OCI_TRANS_NEW|C.ub4(o.flags), o.timeout)
+	}
 	// start transaction
 	// the number of seconds the transaction can be inactive
 	// before it is automatically terminated by the system.
@@ -749,23 +870,29 @@ func (ses *Ses) StartTx(opts ...TxOption) (tx *Tx, err error) {
 	if r == C.OCI_ERROR {
 		return nil, errE(env.ociError())
 	}
-	tx = _drv.txPool.Get().(*Tx) // set *Tx
+	return ses.newTx(nil, nil, nil), nil
+}
+
+// newTx returns a Tx of the session, added to its open transactions.
+// trans, prev and xid are set for a global transaction.
+func (ses *Ses) newTx(trans, prev unsafe.Pointer, xid *XID) *Tx {
+	tx := _drv.txPool.Get().(*Tx) // set *Tx
 	tx.cmu.Lock()
 	tx.Lock()
 	tx.ses = ses
+	tx.ocitrans, tx.prevTrans, tx.xid = trans, prev, xid
 	if tx.id == 0 {
 		tx.id = _drv.txId.nextId()
 	}
 	tx.Unlock()
 	tx.cmu.Unlock()
 	ses.openTxs.add(tx)
-
-	return tx, nil
+	return tx
 }
 
 // Ping returns nil when an Oracle server is contacted; otherwise, an error.
 func (ses *Ses) Ping() (err error) {
-	ses.log(_drv.Cfg().Log.Ses.Ping)
+	ses.log(ses.logCfg().Ses.Ping)
 	defer func() {
 		if r := recover(); r != nil {
 			err = errR(r)
@@ -801,13 +928,14 @@ func (ses *Ses) Break() (err error) {
 	if ses == nil {
 		return nil
 	}
-	ses.log(_drv.Cfg().Log.Ses.Break)
+	ses.log(ses.logCfg().Ses.Break)
 	err = ses.checkClosed()
 	if err != nil {
 		return errE(err)
 	}
-	ses.Lock()
-	defer ses.Unlock()
+	// read lock only: Break runs concurrently with the call it stops
+	ses.RLock()
+	defer ses.RUnlock()
 	env := ses.Env()
 	if ses.ocisvcctx == nil || env == nil || env.ocierr == nil {
 		return nil
@@ -821,6 +949,38 @@ func (ses *Ses) Break() (err error) {
 	return nil
 }
 
+// StmtCacheStats holds the statement cache size and counters of a Ses.
+type StmtCacheStats struct {
+	// Size is the size of the statement cache; zero if caching is disabled.
+	Size uint32
+	// Hits is the number of Prep calls which found the statement in the cache.
+	Hits uint64
+	// Misses is the number of Prep calls which had to prepare the statement.
+	Misses uint64
+}
+
+// StmtCacheStats returns the statement cache size and hit and miss counters.
+func (ses *Ses) StmtCacheStats() StmtCacheStats {
+	if ses == nil {
+		return StmtCacheStats{}
+	}
+	ses.RLock()
+	size := ses.stmtCacheSize
+	ses.RUnlock()
+	return StmtCacheStats{
+		Size:   size,
+		Hits:   atomic.LoadUint64(&ses.stmtCacheHits),
+		Misses: atomic.LoadUint64(&ses.stmtCacheMisses),
+	}
+}
+
+// opened returns the time the session has been opened at.
+func (ses *Ses) opened() time.Time {
+	ses.RLock()
+	defer ses.RUnlock()
+	return ses.openedAt
+}
+
 // NumStmt returns the number of open Oracle statements.
 func (ses *Ses) NumStmt() int {
 	ses.RLock()
@@ -929,34 +1089,111 @@ func (ses *Ses) Timezone() (*time.Location, error) {
 
 // SetAction sets the MODULE and ACTION attribute of the session.
 func (ses *Ses) SetAction(module, action string) error {
-	if len(module) > 48 {
-		module = module[:48]
-	}
-	cModule := C.CString(module)
-	defer C.free(unsafe.Pointer(cModule))
 	env := ses.Env()
-	if err := env.setAttr(unsafe.Pointer(ses.ocises), C.OCI_HTYPE_SESSION,
-		unsafe.Pointer(cModule), C.ub4(len(module)), C.OCI_ATTR_MODULE,
-	); err != nil {
+	if err := setSesAttrString(env, unsafe.Pointer(ses.ocises), module, 48, C.OCI_ATTR_MODULE); err != nil {
 		return errE(err)
 	}
-
-	if len(action) > 32 {
-		action = action[:32]
+	if err := setSesAttrString(env, unsafe.Pointer(ses.ocises), action, 32, C.OCI_ATTR_ACTION); err != nil {
+		return errE(err)
 	}
-	cAction := C.CString(action)
-	defer C.free(unsafe.Pointer(cAction))
-	if err := env.setAttr(unsafe.Pointer(ses.ocises), C.OCI_HTYPE_SESSION,
-		unsafe.Pointer(cAction), C.ub4(len(action)), C.OCI_ATTR_ACTION,
-	); err != nil {
+	return nil
+}
+
+// SetClientInfo sets the non-empty tracing attributes of ci on the session.
+// They are sent to the server piggybacked on the next round trip.
+func (ses *Ses) SetClientInfo(ci ClientInfo) error {
+	if err := ses.checkClosed(); err != nil {
 		return errE(err)
 	}
+	ses.RLock()
+	defer ses.RUnlock()
+	return setClientInfo(ses.Env(), unsafe.Pointer(ses.ocises), ci, false)
+}
+
+// setCtxClientInfo sets the ClientInfo of ctx on the session.
+// Without one, the ClientInfo of a previous context is reset.
+func (ses *Ses) setCtxClientInfo(ctx context.Context) error {
+	ci, ok := ctxClientInfo(ctx)
+	if !ok {
+		return ses.resetClientInfo()
+	}
+	if err := ses.SetClientInfo(ci); err != nil {
+		return err
+	}
+	ses.Lock()
+	ses.ctxClientInfo = true
+	ses.Unlock()
 	return nil
 }
 
+// resetClientInfo resets the tracing attributes of the session to its SesCfg.ClientInfo,
+// if a ClientInfo of a context has been set on it.
+func (ses *Ses) resetClientInfo() error {
+	ses.Lock()
+	set := ses.ctxClientInfo
+	ses.ctxClientInfo = false
+	ses.Unlock()
+	if !set {
+		return nil
+	}
+	cfg := ses.Cfg()
+	ci := cfg.ClientInfo
+	if ci.Module == "" {
+		ci.Module = cfg.Module
+	}
+	ses.RLock()
+	defer ses.RUnlock()
+	return setClientInfo(ses.Env(), unsafe.Pointer(ses.ocises), ci, true)
+}
+
+// setClientInfo sets the tracing attributes on the session handle.
+// Empty attributes are cleared if clear is true, and left as is otherwise.
+func setClientInfo(env *Env, ocises unsafe.Pointer, ci ClientInfo, clear bool) error {
+	for _, a := range []struct {
+		value  string
+		maxLen int
+		attr   C.ub4
+	}{
+		{ci.ClientIdentifier, 64, C.OCI_ATTR_CLIENT_IDENTIFIER},
+		{ci.ClientInfo, 64, C.OCI_ATTR_CLIENT_INFO},
+		{ci.Module, 48, C.OCI_ATTR_MODULE},
+		{ci.Action, 32, C.OCI_ATTR_ACTION},
+		{ci.DBOp, 30, C.OCI_ATTR_DBOP},
+	} {
+		if a.value == "" && !clear {
+			continue
+		}
+		if err := setSesAttrString(env, ocises, a.value, a.maxLen, a.attr); err != nil {
+			return errE(err)
+		}
+	}
+	return nil
+}
+
+// setSesAttrString sets the string attribute of the session handle,
+// truncated to maxLen bytes.
+func setSesAttrString(env *Env, ocises unsafe.Pointer, value string, maxLen int, attr C.ub4) error {
+	if len(value) > maxLen {
+		value = value[:maxLen]
+	}
+	cValue := C.CString(value)
+	defer C.free(unsafe.Pointer(cValue))
+	return env.setAttr(ocises, C.OCI_HTYPE_SESSION,
+		unsafe.Pointer(cValue), C.ub4(len(value)), attr,
+	)
+}
+
+// logCfg returns the LogDrvCfg of the Env of the Ses.
+func (ses *Ses) logCfg() LogDrvCfg {
+	if ses == nil {
+		return _drv.Cfg().Log
+	}
+	return ses.Env().logCfg()
+}
+
 // log writes a message with an Ses system name and caller info.
 func (ses *Ses) log(enabled bool, v ...interface{}) {
-	Log := _drv.Cfg().Log
+	Log := ses.logCfg()
 	if !Log.IsEnabled(enabled) {
 		return
 	}
@@ -969,7 +1206,7 @@ func (ses *Ses) log(enabled bool, v ...interface{}) {
 
 // log writes a formatted message with an Ses system name and caller info.
 func (ses *Ses) logF(enabled bool, format string, v ...interface{}) {
-	Log := _drv.Cfg().Log
+	Log := ses.logCfg()
 	if !Log.IsEnabled(enabled) {
 		return
 	}
@@ -14,6 +14,7 @@ import (
 	"fmt"
 	"sync"
 	"sync/atomic"
+	"time"
 	"unsafe"
 )
 
@@ -25,6 +26,12 @@ type SrvCfg struct {
 
 	Pool PoolCfg
 
+	// StmtCacheSize is the default size of the OCI statement cache of the
+	// sessions opened on this Srv: statements are prepared through the cache,
+	// with the SQL text as key, and released back to it on Stmt.Close.
+	// Zero disables the statement cache.
+	StmtCacheSize uint32
+
 	// StmtCfg configures new Stmts.
 	StmtCfg
 }
@@ -75,6 +82,8 @@ type Srv struct {
 	poolType       PoolType
 
 	openSess *sesList
+	// pool is the SrvPool which handed out the connection, until it is put back
+	pool *SrvPool
 
 	sysNamer
 }
@@ -126,7 +135,7 @@ func (srv *Srv) closeWithRemove() error {
 // close disconnects from an Oracle server.
 // Does not remove Srv from Ses.openSrvs
 func (srv *Srv) close() (err error) {
-	srv.log(_drv.Cfg().Log.Srv.Close)
+	srv.log(srv.logCfg().Srv.Close)
 	err = srv.checkClosed()
 	if err != nil {
 		return errE(err)
@@ -203,6 +212,40 @@ func (srv *Srv) close() (err error) {
 	return nil
 }
 
+// setDRCP sets the DRCP connection class and purity of the SesCfg,
+// or of the PoolCfg of the Srv, on the authInfo handle of OCISessionGet.
+func (srv *Srv) setDRCP(authInfo unsafe.Pointer, cfg SesCfg) error {
+	pc := srv.Cfg().Pool
+	connClass, purity := cfg.ConnClass, cfg.Purity
+	if connClass == "" {
+		connClass = pc.ConnClass
+	}
+	if purity == PurityDefault {
+		purity = pc.Purity
+	}
+	if connClass != "" {
+		cConnClass := C.CString(connClass)
+		defer C.free(unsafe.Pointer(cConnClass))
+		if err := srv.env.setAttr(authInfo, C.OCI_HTYPE_AUTHINFO,
+			unsafe.Pointer(cConnClass), C.ub4(len(connClass)), C.OCI_ATTR_CONNECTION_CLASS,
+		); err != nil {
+			return err
+		}
+	}
+	var ociPurity C.ub4
+	switch purity {
+	case PurityNew:
+		ociPurity = C.OCI_ATTR_PURITY_NEW
+	case PuritySelf:
+		ociPurity = C.OCI_ATTR_PURITY_SELF
+	default:
+		return nil
+	}
+	return srv.env.setAttr(authInfo, C.OCI_HTYPE_AUTHINFO,
+		unsafe.Pointer(&ociPurity), C.ub4(0), C.OCI_ATTR_PURITY,
+	)
+}
+
 // OpenSes opens an Oracle session returning a *Ses and possible error.
 func (srv *Srv) OpenSes(cfg SesCfg) (ses *Ses, err error) {
 	if cfg.IsZero() {
@@ -216,7 +259,10 @@ func (srv *Srv) OpenSes(cfg SesCfg) (ses *Ses, err error) {
 	if srv == nil {
 		return nil, er("srv may not be nil.")
 	}
-	srv.log(_drv.Cfg().Log.Srv.OpenSes)
+	srv.log(srv.logCfg().Srv.OpenSes)
+	if err = cfg.NLS.check(); err != nil {
+		return nil, err
+	}
 
 	credentialType := C.ub4(C.OCI_CRED_EXT)
 
@@ -230,6 +276,9 @@ func (srv *Srv) OpenSes(cfg SesCfg) (ses *Ses, err error) {
 		}
 		credentialType = C.OCI_SESSGET_CREDEXT
 		ocises = authInfo
+		if err = srv.setDRCP(authInfo, cfg); err != nil {
+			return nil, errE(err)
+		}
 	} else {
 		if err = srv.checkClosed(); err != nil {
 			return nil, errE(err)
@@ -294,6 +343,13 @@ func (srv *Srv) OpenSes(cfg SesCfg) (ses *Ses, err error) {
 		return nil, errE(err)
 	}
 
+	// stmt cache size; zero disables the statement cache
+	// https://docs.oracle.com/database/121/LNOCI/oci09adv.htm#LNOCI16655
+	stmtCacheSize := C.ub4(cfg.StmtCacheSize)
+	if stmtCacheSize == 0 {
+		stmtCacheSize = C.ub4(srv.Cfg().StmtCacheSize)
+	}
+
 	mode := C.ub4(C.OCI_DEFAULT)
 
 	var r C.sword
@@ -313,10 +369,26 @@ func (srv *Srv) OpenSes(cfg SesCfg) (ses *Ses, err error) {
 		case SysOper:
 			mode |= C.OCI_SYSOPER
 		}
+		// a session outside of a pool has a statement cache only if it begins with one
+		if stmtCacheSize != 0 {
+			mode |= C.OCI_STMT_CACHE
+		}
 	}
 
+	// the initialized sessions of a pool are tagged, to be initialized only once
+	initTag := cfg.initTag()
+	needInit := initTag != ""
 	switch poolType {
 	case CPool, SPool, DRCPool:
+		var cTag *C.OraText
+		if initTag != "" {
+			cs := C.CString(initTag)
+			defer C.free(unsafe.Pointer(cs))
+			cTag = (*C.OraText)(unsafe.Pointer(cs))
+		}
+		var retTag *C.OraText
+		var retTagLen C.ub4
+		var found C.boolean
 		srv.RLock()
 		r = C.OCISessionGet(
 			srv.env.ocienv,                              //OCIEnv    *envhp,
@@ -325,17 +397,18 @@ func (srv *Srv) OpenSes(cfg SesCfg) (ses *Ses, err error) {
 			(*C.OCIAuthInfo)(authInfo),                  //OCIAuthInfo       *authInfop,
 			srv.ociPoolName,                             //OraText           *dbName,
 			srv.ociPoolNameLen,                          //ub4               dbName_len,
-			nil,                                         //CONST OraText     *tagInfo,
-			0,                                           //ub4               tagInfo_len,
-			nil,                                         //OraText           **retTagInfo,
-			nil,                                         //ub4               *retTagInfo_len,
-			nil,                                         //boolean           *found,
+			cTag,                                        //CONST OraText     *tagInfo,
+			C.ub4(len(initTag)),                         //ub4               tagInfo_len,
+			&retTag,                                     //OraText           **retTagInfo,
+			&retTagLen,                                  //ub4               *retTagInfo_len,
+			&found,                                      //boolean           *found,
 			mode,                                        //ub4           mode );
 		)
 		srv.RUnlock()
 		if r == C.OCI_ERROR {
 			return nil, errE(srv.env.ociError())
 		}
+		needInit = needInit && found == C.FALSE
 		r = C.OCIAttrGet(
 			ocisvcctx,               //const void     *trgthndlp,
 			C.OCI_HTYPE_SVCCTX,      //ub4            trghndltyp,
@@ -366,29 +439,53 @@ func (srv *Srv) OpenSes(cfg SesCfg) (ses *Ses, err error) {
 			return nil, errE(err)
 		}
 	}
-	// set stmt cache size to zero
-	// https://docs.oracle.com/database/121/LNOCI/oci09adv.htm#LNOCI16655
-	stmtCacheSize := C.ub4(0)
+	// set stmt cache size
 	err = srv.env.setAttr(unsafe.Pointer(ocisvcctx), C.OCI_HTYPE_SVCCTX, unsafe.Pointer(&stmtCacheSize), C.ub4(0), C.OCI_ATTR_STMTCACHESIZE)
 	if err != nil {
 		return nil, errE(err)
 	}
+	ci := cfg.ClientInfo
+	if ci.Module == "" {
+		ci.Module = cfg.Module
+	}
+	if err = setClientInfo(srv.env, ocises, ci, false); err != nil {
+		return nil, errE(err)
+	}
 
 	ses = _drv.sesPool.Get().(*Ses) // set *Ses
 	ses.cmu.Lock()
-	defer ses.cmu.Unlock()
 	ses.Lock()
 	ses.env.Store(srv.env)
 	ses.srv = srv
 	ses.ocisvcctx = (*C.OCISvcCtx)(ocisvcctx)
 	ses.ocises = (*C.OCISession)(ocises)
+	ses.stmtCacheSize = uint32(stmtCacheSize)
+	ses.openedAt = time.Now()
+	ses.timezone = cfg.Timezone
+	if ses.timezone == nil {
+		ses.timezone = cfg.NLS.location()
+	}
 	if ses.id == 0 {
 		ses.id = _drv.sesId.nextId()
 	}
 	ses.Unlock()
 	ses.SetCfg(cfg)
 	srv.openSess.add(ses)
-
+	ses.cmu.Unlock()
+
+	if needInit {
+		for _, qry := range cfg.initStmts() {
+			if _, err = ses.PrepAndExe(qry); err != nil {
+				ses.Close()
+				return nil, errF("init session with %q: %v", qry, err)
+			}
+		}
+	}
+	if initTag != "" {
+		ses.Lock()
+		ses.initTag = initTag
+		ses.Unlock()
+	}
 	return ses, nil
 }
 
@@ -396,7 +493,7 @@ func (srv *Srv) OpenSes(cfg SesCfg) (ses *Ses, err error) {
 //
 // Version requires the server have at least one open session.
 func (srv *Srv) Version() (ver string, err error) {
-	srv.log(_drv.Cfg().Log.Srv.Version)
+	srv.log(srv.logCfg().Srv.Version)
 	err = srv.checkClosed()
 	if err != nil {
 		return "", errE(err)
@@ -466,9 +563,17 @@ func (srv *Srv) sysName() string {
 	return srv.sysNamer.Name(func() string { return fmt.Sprintf("%sS%v", srv.env.sysName(), srv.id) })
 }
 
+// logCfg returns the LogDrvCfg of the Env of the Srv.
+func (srv *Srv) logCfg() LogDrvCfg {
+	if srv == nil {
+		return _drv.Cfg().Log
+	}
+	return srv.env.logCfg()
+}
+
 // log writes a message with an Srv system name and caller info.
 func (srv *Srv) log(enabled bool, v ...interface{}) {
-	Log := _drv.Cfg().Log
+	Log := srv.logCfg()
 	if !Log.IsEnabled(enabled) {
 		return
 	}
@@ -481,7 +586,7 @@ func (srv *Srv) log(enabled bool, v ...interface{}) {
 
 // log writes a formatted message with an Srv system name and caller info.
 func (srv *Srv) logF(enabled bool, format string, v ...interface{}) {
-	Log := _drv.Cfg().Log
+	Log := srv.logCfg()
 	if !Log.IsEnabled(enabled) {
 		return
 	}
@@ -69,6 +69,8 @@ type Stmt struct {
 	ocistmt             *C.OCIStmt
 	stmtType            C.ub2
 	sql                 string
+	cached              bool // prepared with the statement cache, sql is the key
+	isDrv               bool // prepared for database/sql, as a DrvStmt
 	gcts                []GoColumnType
 	bnds                []bnd
 	hasPtrBind          bool
@@ -140,7 +142,7 @@ func (stmt *Stmt) closeWithRemove() error {
 // does not remove Stmt from Ses.openStmts
 func (stmt *Stmt) close() (err error) {
 	//fmt.Println("close " + stmt.sysName())
-	stmt.log(_drv.Cfg().Log.Stmt.Close)
+	stmt.log(stmt.logCfg().Stmt.Close)
 	err = stmt.checkClosed()
 	if err != nil {
 		return errE(err)
@@ -159,6 +161,7 @@ func (stmt *Stmt) close() (err error) {
 		stmt.Lock()
 		env := stmt.Env()
 		ocistmt := stmt.ocistmt
+		sql, cached := stmt.sql, stmt.cached
 
 		stmt.stringPtrBufferSize = 0
 		stmt.env.Store((*Env)(nil))
@@ -166,6 +169,8 @@ func (stmt *Stmt) close() (err error) {
 		stmt.ocistmt = nil
 		stmt.stmtType = 0
 		stmt.sql = ""
+		stmt.cached = false
+		stmt.isDrv = false
 		stmt.gcts = nil
 		stmt.bnds = nil
 		stmt.hasPtrBind = false
@@ -177,20 +182,8 @@ func (stmt *Stmt) close() (err error) {
 
 		if ocistmt != nil {
 			// free ocistmt to release cursor on server
-			// OCIStmtRelease must be called with OCIStmtPrepare2
-			// See https://docs.oracle.com/database/121/LNOCI/oci09adv.htm#LNOCI16655
-			r := C.OCIStmtRelease(
-				ocistmt,       // OCIStmt        *stmthp
-				env.ocierr,    // OCIError       *errhp,
-				nil,           // const OraText  *key
-				C.ub4(0),      // ub4 keylen
-				C.OCI_DEFAULT, // ub4 mode
-			)
-			if r == C.OCI_ERROR {
-				errs.PushBack(errE(env.ociError()))
-				// Sometimes panics if free unconditionally - see #222.
-				// https://github.com/rana/ora/issues/222
-				C.OCIHandleFree(unsafe.Pointer(ocistmt), C.OCI_HTYPE_STMT)
+			if err := releaseOCIStmt(env, ocistmt, sql, cached); err != nil {
+				errs.PushBack(err)
 			}
 		}
 
@@ -229,6 +222,13 @@ func (stmt *Stmt) Exe(params ...interface{}) (rowsAffected uint64, err error) {
 	return rowsAffected, err
 }
 
+// ExeContext executes a SQL statement as Exe does, observing the cancellation
+// and the deadline of ctx.
+func (stmt *Stmt) ExeContext(ctx context.Context, params ...interface{}) (rowsAffected uint64, err error) {
+	rowsAffected, _, err = stmt.exeC(ctx, params, false)
+	return rowsAffected, err
+}
+
 // ExeP executes an (PL/)SQL statement on an Oracle server returning the number of
 // rows affected and a possible error.
 //
@@ -249,7 +249,7 @@ func (stmt *Stmt) Parse() (err error) {
 			err = errR(value)
 		}
 	}()
-	stmt.log(_drv.Cfg().Log.Stmt.Exe)
+	stmt.log(stmt.logCfg().Stmt.Exe)
 	err = stmt.checkClosed()
 	if err != nil {
 		return errE(err)
@@ -273,7 +273,38 @@ func (stmt *Stmt) Parse() (err error) {
 	stmt.ses.RUnlock()
 	stmt.RUnlock()
 	if r == C.OCI_ERROR {
-		return errE(env.ociError())
+		return errE(stmt.execError(env))
+	}
+	return nil
+}
+
+// releaseOCIStmt releases the statement handle prepared with OCIStmtPrepare2,
+// to release its cursor on the server.
+func releaseOCIStmt(env *Env, ocistmt *C.OCIStmt, sql string, cached bool) error {
+	// OCIStmtRelease must be called with OCIStmtPrepare2
+	// See https://docs.oracle.com/database/121/LNOCI/oci09adv.htm#LNOCI16655
+	//
+	// A cached statement is released back to the statement cache, with its key.
+	var key *C.OraText
+	var keyLen C.ub4
+	if cached {
+		cKey := C.CString(sql)
+		defer C.free(unsafe.Pointer(cKey))
+		key, keyLen = (*C.OraText)(unsafe.Pointer(cKey)), C.ub4(len(sql))
+	}
+	r := C.OCIStmtRelease(
+		ocistmt,       // OCIStmt        *stmthp
+		env.ocierr,    // OCIError       *errhp,
+		key,           // const OraText  *key
+		keyLen,        // ub4 keylen
+		C.OCI_DEFAULT, // ub4 mode
+	)
+	if r == C.OCI_ERROR {
+		err := errE(env.ociError())
+		// Sometimes panics if free unconditionally - see #222.
+		// https://github.com/rana/ora/issues/222
+		C.OCIHandleFree(unsafe.Pointer(ocistmt), C.OCI_HTYPE_STMT)
+		return err
 	}
 	return nil
 }
@@ -285,6 +316,19 @@ func (stmt *Stmt) exe(params []interface{}, isAssocArray bool) (rowsAffected uin
 	return stmt.exeC(context.Background(), params, isAssocArray)
 }
 func (stmt *Stmt) exeC(ctx context.Context, params []interface{}, isAssocArray bool) (rowsAffected uint64, lastInsertId int64, err error) {
+	if stmt == nil {
+		return 0, 0, er("stmt may not be nil.")
+	}
+	// only an idempotent statement may be executed again
+	err = stmt.withRetry(ctx, stmt.Cfg().Idempotent(), func() error {
+		rowsAffected, lastInsertId, err = stmt.exeOnce(ctx, params, isAssocArray)
+		return err
+	})
+	return rowsAffected, lastInsertId, err
+}
+
+// exeOnce executes the statement once, see exeC.
+func (stmt *Stmt) exeOnce(ctx context.Context, params []interface{}, isAssocArray bool) (rowsAffected uint64, lastInsertId int64, err error) {
 	if stmt == nil {
 		return 0, 0, er("stmt may not be nil.")
 	}
@@ -296,7 +340,7 @@ func (stmt *Stmt) exeC(ctx context.Context, params []interface{}, isAssocArray b
 			err = errR(value)
 		}
 	}()
-	stmt.log(_drv.Cfg().Log.Stmt.Exe)
+	stmt.log(stmt.logCfg().Stmt.Exe)
 	err = stmt.checkClosed()
 	if err != nil {
 		return 0, 0, errE(err)
@@ -304,6 +348,9 @@ func (stmt *Stmt) exeC(ctx context.Context, params []interface{}, isAssocArray b
 	if cfg, ok := ctxStmtCfg(ctx); ok {
 		stmt.SetCfg(cfg)
 	}
+	if err = stmt.ses.setCtxClientInfo(ctx); err != nil {
+		return 0, 0, err
+	}
 	// for case of inserting and returning identity for database/sql package
 	stmt.RLock()
 	pkgEnvInsert := stmt.Env().isPkgEnv && stmt.stmtType == C.OCI_STMT_INSERT
@@ -338,7 +385,8 @@ func (stmt *Stmt) exeC(ctx context.Context, params []interface{}, isAssocArray b
 			autoCommit = true
 		}
 	}
-	stmt.logF(_drv.Cfg().Log.Stmt.Exe, "iterations=%d autoCommit=%t", iterations, autoCommit)
+	stmt.logF(stmt.logCfg().Stmt.Exe, "iterations=%d autoCommit=%t", iterations, autoCommit)
+	done := stmt.ses.watchCall(ctx, stmt.Cfg().Timeout())
 	// Execute statement on Oracle server
 	stmt.RLock()
 	env := stmt.Env()
@@ -355,10 +403,11 @@ func (stmt *Stmt) exeC(ctx context.Context, params []interface{}, isAssocArray b
 	stmt.ses.RUnlock()
 	stmtType, hasPtrBind := stmt.stmtType, stmt.hasPtrBind
 	stmt.RUnlock()
-	stmt.logF(_drv.Cfg().Log.Stmt.Exe, "returned %d, hasPtrBind=%t", r, hasPtrBind)
+	stmt.logF(stmt.logCfg().Stmt.Exe, "returned %d, hasPtrBind=%t", r, hasPtrBind)
 	if r == C.OCI_ERROR {
-		return 0, 0, errE(env.ociError())
+		return 0, 0, done(errE(stmt.execError(env)))
 	}
+	done(nil)
 	// Get rowsAffected based on statement type
 	switch stmtType {
 	case C.OCI_STMT_SELECT, C.OCI_STMT_UPDATE, C.OCI_STMT_DELETE, C.OCI_STMT_INSERT:
@@ -389,17 +438,39 @@ func (stmt *Stmt) Qry(params ...interface{}) (*Rset, error) {
 	return stmt.qry(params)
 }
 
+// QryContext runs a SQL query as Qry does, observing the cancellation
+// and the deadline of ctx.
+func (stmt *Stmt) QryContext(ctx context.Context, params ...interface{}) (*Rset, error) {
+	return stmt.qryC(ctx, params)
+}
+
 // qry runs a SQL query on an Oracle server returning a *Rset and possible error.
 func (stmt *Stmt) qry(params []interface{}) (rset *Rset, err error) {
 	return stmt.qryC(context.Background(), params)
 }
 func (stmt *Stmt) qryC(ctx context.Context, params []interface{}) (rset *Rset, err error) {
+	if stmt == nil {
+		return nil, er("stmt may not be nil.")
+	}
+	// a query may be run again before its Rset is returned
+	stmt.RLock()
+	isSelect := stmt.stmtType == C.OCI_STMT_SELECT
+	stmt.RUnlock()
+	err = stmt.withRetry(ctx, isSelect || stmt.Cfg().Idempotent(), func() error {
+		rset, err = stmt.qryOnce(ctx, params)
+		return err
+	})
+	return rset, err
+}
+
+// qryOnce runs the query once, see qryC.
+func (stmt *Stmt) qryOnce(ctx context.Context, params []interface{}) (rset *Rset, err error) {
 	defer func() {
 		if value := recover(); value != nil {
 			err = errR(value)
 		}
 	}()
-	stmt.log(_drv.Cfg().Log.Stmt.Qry)
+	stmt.log(stmt.logCfg().Stmt.Qry)
 	if err := ctx.Err(); err != nil {
 		return nil, err
 	}
@@ -411,6 +482,9 @@ func (stmt *Stmt) qryC(ctx context.Context, params []interface{}) (rset *Rset, e
 	if err != nil {
 		return nil, errE(err)
 	}
+	if err = stmt.ses.setCtxClientInfo(ctx); err != nil {
+		return nil, err
+	}
 	_, err = stmt.bind(params, false) // bind parameters
 	if err != nil {
 		return nil, errE(err)
@@ -420,6 +494,7 @@ func (stmt *Stmt) qryC(ctx context.Context, params []interface{}) (rset *Rset, e
 	if err != nil {
 		return nil, errE(err)
 	}
+	done := stmt.ses.watchCall(ctx, stmt.Cfg().Timeout())
 	// Query statement on Oracle server
 	stmt.RLock()
 	env := stmt.Env()
@@ -438,8 +513,9 @@ func (stmt *Stmt) qryC(ctx context.Context, params []interface{}) (rset *Rset, e
 	hasPtrBind := stmt.hasPtrBind
 	stmt.RUnlock()
 	if r == C.OCI_ERROR {
-		return nil, errE(env.ociError())
+		return nil, done(errE(stmt.execError(env)))
 	}
+	done(nil)
 	if hasPtrBind { // set any bind pointers
 		err = stmt.setBindPtrs()
 		if err != nil {
@@ -503,7 +579,7 @@ func (stmt *Stmt) putBnd(idx int, bnd bnd) {
 //
 // No locking occurs.
 func (stmt *Stmt) bind(params []interface{}, isAssocArray bool) (iterations uint32, err error) {
-	stmt.logF(_drv.Cfg().Log.Stmt.Bind, "Params %d", len(params))
+	stmt.logF(stmt.logCfg().Stmt.Bind, "Params %d", len(params))
 	// Create binds for each parameter; bind position is 1-based
 	if len(params) == 0 {
 		return 1, nil
@@ -529,7 +605,7 @@ func (stmt *Stmt) bind(params []interface{}, isAssocArray bool) (iterations uint
 	for n = range params {
 		name, v := nameAndValue(params[n])
 		pos := namedPos{Ordinal: n + 1, Name: name}
-		//stmt.logF(_drv.Cfg().Log.Stmt.Bind, "params[%d]=(%v %T)", n, params[n], params[n])
+		//stmt.logF(stmt.logCfg().Stmt.Bind, "params[%d]=(%v %T)", n, params[n], params[n])
 		switch value := v.(type) {
 		case int64:
 			bnd := stmt.getBnd(bndIdxInt64).(*bndInt64)
@@ -1402,6 +1478,17 @@ func (stmt *Stmt) bind(params []interface{}, isAssocArray bool) (iterations uint
 			}
 			stmt.hasPtrBind = true
 
+		case *LobReadWriter:
+			if value == nil {
+				stmt.setNilBind(n, C.SQLT_BLOB)
+			} else {
+				bnd := stmt.getBnd(bndIdxLob).(*bndLob)
+				bnds[n] = bnd
+				if err = bnd.bindLocator(value, pos, stmt); err != nil {
+					return iterations, err
+				}
+			}
+
 		case [][]byte:
 			bnd := stmt.getBnd(bndIdxBinSlice).(*bndBinSlice)
 			bnds[n] = bnd
@@ -1638,21 +1725,30 @@ func (stmt *Stmt) sysName() string {
 	return stmt.sysNamer.Name(func() string { return fmt.Sprintf("%sS%v", stmt.ses.sysName(), stmt.id) })
 }
 
+// logCfg returns the LogDrvCfg of the Env of the Stmt.
+func (stmt *Stmt) logCfg() LogDrvCfg {
+	if stmt == nil {
+		return _drv.Cfg().Log
+	}
+	return stmt.Env().logCfg()
+}
+
 // log writes a message with an Stmt system name and caller info.
 func (stmt *Stmt) log(enabled bool, v ...interface{}) {
-	if !_drv.Cfg().Log.IsEnabled(enabled) {
+	Log := stmt.logCfg()
+	if !Log.IsEnabled(enabled) {
 		return
 	}
 	if len(v) == 0 {
-		_drv.Cfg().Log.Logger.Infof("%v %v", stmt.sysName(), callInfo(1))
+		Log.Logger.Infof("%v %v", stmt.sysName(), callInfo(1))
 	} else {
-		_drv.Cfg().Log.Logger.Infof("%v %v %v", stmt.sysName(), callInfo(1), fmt.Sprint(v...))
+		Log.Logger.Infof("%v %v %v", stmt.sysName(), callInfo(1), fmt.Sprint(v...))
 	}
 }
 
 // log writes a formatted message with an Stmt system name and caller info.
 func (stmt *Stmt) logF(enabled bool, format string, v ...interface{}) {
-	Log := _drv.Cfg().Log
+	Log := stmt.logCfg()
 	if !Log.IsEnabled(enabled) {
 		return
 	}
@@ -1703,6 +1799,27 @@ func (stmt *Stmt) attr(attrSize C.ub4, attrType C.ub4) (unsafe.Pointer, error) {
 	return attrup, nil
 }
 
+// execError returns the error of a failed execution of the statement:
+// an *ORAError of a parse error has the SQL text and the parse error offset.
+func (stmt *Stmt) execError(env *Env) error {
+	err := env.ociError()
+	oe, ok := AsORAError(err)
+	if !ok {
+		return err
+	}
+	p, attrErr := stmt.attr(2, C.OCI_ATTR_PARSE_ERROR_OFFSET)
+	if attrErr != nil {
+		return err
+	}
+	offset := int(*((*C.ub2)(p)))
+	C.free(p)
+	stmt.RLock()
+	sql := stmt.sql
+	stmt.RUnlock()
+	oe.setParseError(sql, offset)
+	return err
+}
+
 // setAttr sets an attribute on the statement handle. No locking occurs.
 func (stmt *Stmt) setAttr(attrValue uint32, attrType C.ub4) error {
 	stmt.RLock()
@@ -4,6 +4,8 @@
 
 package ora
 
+import "time"
+
 // StmtCfg affects various aspects of a SQL statement.
 //
 // Assign values to StmtCfg prior to calling Stmt.Exe
@@ -20,6 +22,8 @@ type StmtCfg struct {
 	stringPtrBufferSize   int
 	fetchLen, lobFetchLen int
 	byteSlice             GoColumnType
+	timeout               time.Duration
+	idempotent            bool
 
 	// IsAutoCommitting determines whether DML statements are automatically
 	// committed.
@@ -93,6 +97,45 @@ func (c StmtCfg) PrefetchRowCount() uint32 {
 	return c.prefetchRowCount
 }
 
+// SetTimeout sets the maximum duration of each call of a statement on the server,
+// as executing it and fetching its rows; zero for no limit.
+func (c StmtCfg) SetTimeout(timeout time.Duration) StmtCfg {
+	if timeout < 0 {
+		if c.Err == nil {
+			c.Err = errNew("SetTimeout parameter 'timeout' may not be negative")
+		}
+		return c
+	}
+	c.timeout = timeout
+	return c
+}
+
+// Timeout returns the maximum duration of each call of a statement on the server.
+//
+// The default is 0, no limit.
+//
+// The timeout is set as the OCI call timeout of the round trips with an Oracle
+// client 18.1 or later, the call is broken with Ses.Break with older clients.
+// The deadline of the context of ExeContext, QryContext and NextContext (and their
+// database/sql counterparts) is observed too, the sooner one wins.
+// A timed out call returns a *TimeoutError.
+func (c StmtCfg) Timeout() time.Duration {
+	return c.timeout
+}
+
+// SetIdempotent marks the statement as idempotent: executing it more than once
+// has the same effect as executing it once, so Exe may retry it as the RetryPolicy
+// of the session says.
+func (c StmtCfg) SetIdempotent(idempotent bool) StmtCfg {
+	c.idempotent = idempotent
+	return c
+}
+
+// Idempotent reports whether the statement may be retried by Exe.
+func (c StmtCfg) Idempotent() bool {
+	return c.idempotent
+}
+
 // SetPrefetchMemorySize sets the prefetch memory size in bytes used during a SQL
 // select command.
 func (c StmtCfg) SetPrefetchMemorySize(prefetchMemorySize uint32) StmtCfg {
@@ -114,6 +157,10 @@ func (c StmtCfg) PrefetchMemorySize() uint32 {
 
 // SetLongBufferSize sets the long buffer size in bytes.
 //
+// LONG columns are fetched piecewise, so values of any length are fetched:
+// this is the maximum size of one piece. The pieces start at 64 KiB,
+// and grow up to this for long values.
+//
 // The maximum is 2,147,483,642 bytes.
 //
 // Returns an error if the specified size is less than 1 or greater than 2,147,483,642.
@@ -136,19 +183,20 @@ func (c StmtCfg) SetLongBufferSize(size uint32) StmtCfg {
 	return c
 }
 
-// LongBufferSize returns the long buffer size in bytes used to define the sql select-column
-// buffer size of an Oracle LONG type.
+// LongBufferSize returns the long buffer size in bytes: the maximum size of
+// one piece of the piecewise fetch of an Oracle LONG type.
 //
 // The default is 16,777,216 bytes.
-//
-// The default is considered a moderate buffer where the 2GB max buffer may not
-// be feasible on all clients.
 func (c StmtCfg) LongBufferSize() uint32 {
 	return c.longBufferSize
 }
 
 // SetLongRawBufferSize sets the LONG RAW buffer size in bytes.
 //
+// LONG RAW columns are fetched piecewise, so values of any length are fetched:
+// this is the maximum size of one piece. The pieces start at 64 KiB,
+// and grow up to this for long values.
+//
 // The maximum is 2,147,483,642 bytes.
 //
 // Returns an error if the specified size is greater than 2,147,483,642.
@@ -165,13 +213,10 @@ func (c StmtCfg) SetLongRawBufferSize(size uint32) StmtCfg {
 	return c
 }
 
-// LongRawBufferSize returns the LONG RAW buffer size in bytes used to define the sql select-column
-// buffer size of an Oracle LONG RAW type.
+// LongRawBufferSize returns the LONG RAW buffer size in bytes: the maximum size of
+// one piece of the piecewise fetch of an Oracle LONG RAW type.
 //
 // The default is 16,777,216 bytes.
-//
-// The default is considered a moderate buffer where the 2GB max buffer may not
-// be feasible on all clients.
 func (c StmtCfg) LongRawBufferSize() uint32 {
 	return c.longRawBufferSize
 }
@@ -0,0 +1,198 @@
+// Copyright 2014 Rana Ian. All rights reserved.
+// Use of this source code is governed by The MIT License
+// found in the accompanying LICENSE file.
+
+package ora
+
+/*
+#include <oci.h>
+#include "version.h"
+
+#ifndef OCI_ATTR_CALL_TIMEOUT
+#define OCI_ATTR_CALL_TIMEOUT 531
+#endif
+*/
+import "C"
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unsafe"
+)
+
+// TimeoutError is returned by a call interrupted by the StmtCfg.Timeout,
+// or by the deadline of its context.
+type TimeoutError struct {
+	// Err is the error of the interrupted call:
+	// ORA-03156 of the OCI call timeout, or ORA-01013 of a Break.
+	Err error
+}
+
+func (e *TimeoutError) Error() string { return "ora: timeout: " + e.Err.Error() }
+
+// Timeout reports true, as net.Error does.
+func (e *TimeoutError) Timeout() bool { return true }
+
+// Temporary reports true, as the session is usable after the timeout.
+func (e *TimeoutError) Temporary() bool { return true }
+
+// Code returns the Oracle error code of Err.
+func (e *TimeoutError) Code() int {
+	if cd, ok := e.Err.(interface {
+		Code() int
+	}); ok {
+		return cd.Code()
+	}
+	return 0
+}
+
+// Is reports whether target is ErrTimeout or context.DeadlineExceeded, for errors.Is.
+func (e *TimeoutError) Is(target error) bool {
+	return target == ErrTimeout || target == context.DeadlineExceeded
+}
+
+// IsTimeout reports whether err is a TimeoutError, context.DeadlineExceeded,
+// or another error of the ErrTimeout class.
+func IsTimeout(err error) bool {
+	if to, ok := err.(interface {
+		Timeout() bool
+	}); ok && to.Timeout() {
+		return true
+	}
+	return Classify(err) == ErrTimeout
+}
+
+var clientVersion struct {
+	once         sync.Once
+	major, minor int
+}
+
+// hasCallTimeout reports whether the OCI client supports OCI_ATTR_CALL_TIMEOUT (18.1+).
+func hasCallTimeout() bool {
+	clientVersion.once.Do(func() {
+		var major, minor, update, patch, port C.sword
+		C.OCIClientVersion(&major, &minor, &update, &patch, &port)
+		clientVersion.major, clientVersion.minor = int(major), int(minor)
+	})
+	return clientVersion.major >= 18
+}
+
+// callTimeout returns the timeout of a call: the minimum of timeout and
+// the time left till the deadline of ctx, zero for none.
+func callTimeout(ctx context.Context, timeout time.Duration) time.Duration {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return timeout
+	}
+	left := deadline.Sub(time.Now())
+	if left < time.Millisecond {
+		left = time.Millisecond
+	}
+	if timeout <= 0 || left < timeout {
+		return left
+	}
+	return timeout
+}
+
+// setCallTimeout sets the OCI call timeout of the session: the maximum duration
+// of each round trip to the server, in milliseconds; zero for none.
+func (ses *Ses) setCallTimeout(timeout time.Duration) error {
+	ms := C.ub4(timeout / time.Millisecond)
+	if timeout > 0 && ms == 0 {
+		ms = 1
+	}
+	ses.RLock()
+	defer ses.RUnlock()
+	env := ses.Env()
+	if ses.ocisvcctx == nil || env == nil {
+		return errF("session is closed")
+	}
+	return env.setAttr(unsafe.Pointer(ses.ocisvcctx), C.OCI_HTYPE_SVCCTX,
+		unsafe.Pointer(&ms), 4, C.OCI_ATTR_CALL_TIMEOUT)
+}
+
+// watchCall prepares a call on the server to be interrupted after the timeout
+// or the deadline of ctx, with the OCI call timeout where the client supports it,
+// with Break otherwise, or as ctx is cancelled.
+//
+// The returned done must be called with the error of the call: it returns
+// a TimeoutError for a timed out call, and the context error for a cancelled one.
+func (ses *Ses) watchCall(ctx context.Context, timeout time.Duration) (done func(error) error) {
+	timeout = callTimeout(ctx, timeout)
+	useAttr := timeout > 0 && hasCallTimeout() && ses.setCallTimeout(timeout) == nil
+	if ctx.Done() == nil && (timeout <= 0 || useAttr) {
+		if !useAttr {
+			return func(err error) error { return err }
+		}
+		return func(err error) error {
+			ses.setCallTimeout(0)
+			return timeoutErr(ctx, err, false)
+		}
+	}
+
+	var timer <-chan time.Time
+	var t *time.Timer
+	if timeout > 0 && !useAttr {
+		t = time.NewTimer(timeout)
+		timer = t.C
+	}
+	stop := make(chan struct{})
+	var broken int32
+	go func() {
+		select {
+		case <-stop:
+			return
+		case <-timer:
+		case <-ctx.Done():
+			if useAttr && ctx.Err() == context.DeadlineExceeded {
+				// the OCI call timeout interrupts the call
+				return
+			}
+		}
+		// select again to avoid race condition if both are done
+		select {
+		case <-stop:
+		default:
+			atomic.StoreInt32(&broken, 1)
+			ses.Break()
+		}
+	}()
+	return func(err error) error {
+		close(stop)
+		if t != nil {
+			t.Stop()
+		}
+		if useAttr {
+			ses.setCallTimeout(0)
+		}
+		return timeoutErr(ctx, err, atomic.LoadInt32(&broken) == 1)
+	}
+}
+
+// timeoutErr returns the error of an interrupted call: the context error
+// if ctx is cancelled, a TimeoutError for a timeout, else err.
+func timeoutErr(ctx context.Context, err error, broken bool) error {
+	if err == nil {
+		return nil
+	}
+	cd, ok := err.(interface {
+		Code() int
+	})
+	if !ok {
+		return err
+	}
+	switch cd.Code() {
+	case 3156: // ORA-03156: OCI call timed out
+	case 1013: // ORA-01013: user requested cancel of current operation
+		if !broken {
+			return err
+		}
+		if ctx.Err() == context.Canceled {
+			return ctx.Err()
+		}
+	default:
+		return err
+	}
+	return &TimeoutError{Err: err}
+}
@@ -0,0 +1,171 @@
+// Copyright 2014 Rana Ian. All rights reserved.
+// Use of this source code is governed by The MIT License
+// found in the accompanying LICENSE file.
+
+package tns
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Names maps the upper case net service names (aliases) of a tnsnames.ora file
+// to their connect descriptors.
+//
+// The entries which are not connect descriptors, as the listener addresses
+// (LISTENER_ORCL = (ADDRESS=...)), are skipped. Several DESCRIPTIONs of an entry,
+// without a DESCRIPTION_LIST, are returned as a DESCRIPTION_LIST.
+type Names map[string]ConnectDescriptor
+
+// Lookup returns the connect descriptor of alias, case insensitively.
+func (n Names) Lookup(alias string) (ConnectDescriptor, bool) {
+	cd, ok := n[strings.ToUpper(alias)]
+	return cd, ok
+}
+
+// Parse parses a tnsnames.ora file.
+// The IFILE includes are read relative to the current directory.
+func Parse(r io.Reader) (Names, error) {
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	names := make(Names)
+	return names, names.parse(string(b), "", make(map[string]bool))
+}
+
+// ParseFile parses the tnsnames.ora file at path, with its IFILE includes,
+// which are read relative to the directory of the including file.
+// A later definition of an alias overrides the former.
+func ParseFile(path string) (Names, error) {
+	names := make(Names)
+	return names, names.parseFile(path, make(map[string]bool))
+}
+
+func (n Names) parseFile(path string, seen map[string]bool) error {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+	if seen[abs] {
+		return fmt.Errorf("%s: IFILE cycle", path)
+	}
+	seen[abs] = true
+	defer delete(seen, abs)
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if err = n.parse(string(b), filepath.Dir(path), seen); err != nil {
+		return fmt.Errorf("%s: %v", path, err)
+	}
+	return nil
+}
+
+func (n Names) parse(s, dir string, seen map[string]bool) error {
+	p := &parser{s: stripComments(s)}
+	entries, err := p.entries()
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if strings.EqualFold(e.Key, "IFILE") {
+			path := strings.Trim(e.Value, `"'`)
+			if !filepath.IsAbs(path) && dir != "" {
+				path = filepath.Join(dir, path)
+			}
+			if err = n.parseFile(path, seen); err != nil {
+				return err
+			}
+			continue
+		}
+		desc, ok := entryDescriptor(e)
+		if !ok {
+			continue
+		}
+		cd, err := descriptor(desc)
+		if err != nil {
+			return fmt.Errorf("%s: %v", e.Key, err)
+		}
+		for _, name := range e.names {
+			n[strings.ToUpper(name)] = cd
+		}
+	}
+	return nil
+}
+
+// entryDescriptor returns the DESCRIPTION or DESCRIPTION_LIST of e,
+// and whether e is a connect descriptor at all.
+func entryDescriptor(e entry) (Param, bool) {
+	if len(e.Children) == 1 {
+		return e.Children[0], isDescriptor(e.Children[0])
+	}
+	if len(e.Children) == 0 {
+		return Param{}, false
+	}
+	for _, c := range e.Children {
+		if !strings.EqualFold(c.Key, "DESCRIPTION") {
+			return Param{}, false
+		}
+	}
+	return Param{Key: "DESCRIPTION_LIST", Children: e.Children}, true
+}
+
+// Dir returns the directory of the tnsnames.ora and sqlnet.ora files:
+// $TNS_ADMIN, or $ORACLE_HOME/network/admin.
+func Dir() string {
+	if dir := os.Getenv("TNS_ADMIN"); dir != "" {
+		return dir
+	}
+	if home := os.Getenv("ORACLE_HOME"); home != "" {
+		return filepath.Join(home, "network", "admin")
+	}
+	return ""
+}
+
+// DefaultDomain returns the NAMES.DEFAULT_DOMAIN of the sqlnet.ora file in dir.
+// Returns the empty string if the file or the parameter is missing.
+func DefaultDomain(dir string) string {
+	b, err := ioutil.ReadFile(filepath.Join(dir, "sqlnet.ora"))
+	if err != nil {
+		return ""
+	}
+	p := &parser{s: stripComments(string(b))}
+	entries, _ := p.entries()
+	for _, e := range entries {
+		if strings.EqualFold(e.Key, "NAMES.DEFAULT_DOMAIN") {
+			return e.Value
+		}
+	}
+	return ""
+}
+
+// Resolve returns the connect descriptor of alias from the tnsnames.ora file in Dir.
+//
+// An alias without a domain is looked up with the NAMES.DEFAULT_DOMAIN of sqlnet.ora, too.
+// The files are read at each call.
+func Resolve(alias string) (ConnectDescriptor, error) {
+	dir := Dir()
+	if dir == "" {
+		return ConnectDescriptor{}, fmt.Errorf("neither TNS_ADMIN nor ORACLE_HOME is set")
+	}
+	names, err := ParseFile(filepath.Join(dir, "tnsnames.ora"))
+	if err != nil {
+		return ConnectDescriptor{}, err
+	}
+	if cd, ok := names.Lookup(alias); ok {
+		return cd, nil
+	}
+	if !strings.Contains(alias, ".") {
+		if domain := DefaultDomain(dir); domain != "" {
+			if cd, ok := names.Lookup(alias + "." + domain); ok {
+				return cd, nil
+			}
+		}
+	}
+	return ConnectDescriptor{}, fmt.Errorf("%s: alias not found in %s", alias, dir)
+}
@@ -0,0 +1,616 @@
+// Copyright 2014 Rana Ian. All rights reserved.
+// Use of this source code is governed by The MIT License
+// found in the accompanying LICENSE file.
+
+// Package tns parses and builds Oracle Net connect descriptors,
+// as (DESCRIPTION=(ADDRESS=(PROTOCOL=TCP)(HOST=h)(PORT=1521))(CONNECT_DATA=(SERVICE_NAME=s))),
+// and parses the tnsnames.ora and sqlnet.ora files to resolve net service names (aliases).
+//
+// The package is pure Go, the result of ConnectDescriptor.String
+// can be used as ora.SrvCfg.Dblink.
+package tns
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// OnOff is a parameter which is either unset, ON or OFF, as FAILOVER.
+type OnOff int8
+
+const (
+	// Unset leaves the parameter to its default.
+	Unset OnOff = iota
+	// On is ON (or YES, TRUE).
+	On
+	// Off is OFF (or NO, FALSE).
+	Off
+)
+
+func (o OnOff) String() string {
+	switch o {
+	case On:
+		return "ON"
+	case Off:
+		return "OFF"
+	}
+	return ""
+}
+
+func parseOnOff(key, value string) (OnOff, error) {
+	switch strings.ToUpper(value) {
+	case "ON", "YES", "TRUE":
+		return On, nil
+	case "OFF", "NO", "FALSE":
+		return Off, nil
+	}
+	return Unset, fmt.Errorf("%s: %q is not ON or OFF", key, value)
+}
+
+// Param is a keyword-value pair, as (KEY=value), or a list, as (KEY=(K1=v1)(K2=v2)).
+type Param struct {
+	Key      string
+	Value    string
+	Children []Param
+}
+
+// String returns the parameter as (KEY=value).
+func (p Param) String() string {
+	var buf bytes.Buffer
+	p.write(&buf)
+	return buf.String()
+}
+
+func (p Param) write(buf *bytes.Buffer) {
+	buf.WriteByte('(')
+	buf.WriteString(strings.ToUpper(p.Key))
+	buf.WriteByte('=')
+	if len(p.Children) == 0 {
+		buf.WriteString(p.Value)
+	}
+	for _, c := range p.Children {
+		c.write(buf)
+	}
+	buf.WriteByte(')')
+}
+
+// Address is a protocol address, as (ADDRESS=(PROTOCOL=TCP)(HOST=h)(PORT=1521)).
+type Address struct {
+	Protocol string
+	Host     string
+	Port     int
+	// Params are the other parameters of the address, as SEND_BUF_SIZE.
+	Params []Param
+}
+
+func (a Address) param() Param {
+	p := Param{Key: "ADDRESS"}
+	p.Children = append(p.Children, Param{Key: "PROTOCOL", Value: strings.ToUpper(a.Protocol)})
+	if a.Host != "" {
+		p.Children = append(p.Children, Param{Key: "HOST", Value: a.Host})
+	}
+	if a.Port != 0 {
+		p.Children = append(p.Children, Param{Key: "PORT", Value: strconv.Itoa(a.Port)})
+	}
+	p.Children = append(p.Children, a.Params...)
+	return p
+}
+
+// AddressList is an ADDRESS_LIST, with its FAILOVER and LOAD_BALANCE options.
+type AddressList struct {
+	Addresses   []Address
+	Failover    OnOff
+	LoadBalance OnOff
+}
+
+func (l AddressList) param() Param {
+	p := Param{Key: "ADDRESS_LIST", Children: onOffParams(l.Failover, l.LoadBalance)}
+	for _, a := range l.Addresses {
+		p.Children = append(p.Children, a.param())
+	}
+	return p
+}
+
+// ConnectData is the CONNECT_DATA of a connect descriptor.
+type ConnectData struct {
+	ServiceName  string
+	SID          string
+	InstanceName string
+	// Server is DEDICATED, SHARED or POOLED (DRCP).
+	Server string
+	// Params are the other parameters, as FAILOVER_MODE.
+	Params []Param
+}
+
+func (d ConnectData) param() Param {
+	p := Param{Key: "CONNECT_DATA"}
+	for _, kv := range [][2]string{
+		{"SERVICE_NAME", d.ServiceName},
+		{"SID", d.SID},
+		{"INSTANCE_NAME", d.InstanceName},
+		{"SERVER", strings.ToUpper(d.Server)},
+	} {
+		if kv[1] != "" {
+			p.Children = append(p.Children, Param{Key: kv[0], Value: kv[1]})
+		}
+	}
+	p.Children = append(p.Children, d.Params...)
+	return p
+}
+
+// ConnectDescriptor is a (DESCRIPTION=...) connect descriptor,
+// or a (DESCRIPTION_LIST=...) of them, as for the failover between sites.
+type ConnectDescriptor struct {
+	// Descriptions are the DESCRIPTIONs of a DESCRIPTION_LIST.
+	// A DESCRIPTION_LIST has only Descriptions, Failover, LoadBalance and Params.
+	Descriptions []ConnectDescriptor
+
+	// Addresses are the ADDRESS entries directly under the DESCRIPTION.
+	Addresses    []Address
+	AddressLists []AddressList
+	Failover     OnOff
+	LoadBalance  OnOff
+	ConnectData  ConnectData
+	// Params are the other parameters of the DESCRIPTION, as CONNECT_TIMEOUT or RETRY_COUNT.
+	Params []Param
+}
+
+// IsList reports whether the descriptor is a DESCRIPTION_LIST.
+func (cd ConnectDescriptor) IsList() bool { return len(cd.Descriptions) != 0 }
+
+// Pooled reports whether the descriptor connects to a DRCP pooled server:
+// for a DESCRIPTION_LIST, whether each of its DESCRIPTIONs does.
+func (cd ConnectDescriptor) Pooled() bool {
+	if !cd.IsList() {
+		return strings.EqualFold(cd.ConnectData.Server, "POOLED")
+	}
+	for _, d := range cd.Descriptions {
+		if !d.Pooled() {
+			return false
+		}
+	}
+	return true
+}
+
+// SetServer sets the CONNECT_DATA SERVER of the descriptor,
+// of each DESCRIPTION of a DESCRIPTION_LIST, as POOLED for DRCP.
+func (cd *ConnectDescriptor) SetServer(server string) {
+	if !cd.IsList() {
+		cd.ConnectData.Server = strings.ToUpper(server)
+		return
+	}
+	descs := make([]ConnectDescriptor, len(cd.Descriptions))
+	for i, d := range cd.Descriptions {
+		d.SetServer(server)
+		descs[i] = d
+	}
+	cd.Descriptions = descs
+}
+
+// String returns the canonical form of the descriptor: upper case keywords, without whitespace.
+func (cd ConnectDescriptor) String() string {
+	return cd.param().String()
+}
+
+func (cd ConnectDescriptor) param() Param {
+	if cd.IsList() {
+		p := Param{Key: "DESCRIPTION_LIST", Children: onOffParams(cd.Failover, cd.LoadBalance)}
+		p.Children = append(p.Children, cd.Params...)
+		for _, d := range cd.Descriptions {
+			p.Children = append(p.Children, d.param())
+		}
+		return p
+	}
+	p := Param{Key: "DESCRIPTION", Children: onOffParams(cd.Failover, cd.LoadBalance)}
+	p.Children = append(p.Children, cd.Params...)
+	for _, a := range cd.Addresses {
+		p.Children = append(p.Children, a.param())
+	}
+	for _, l := range cd.AddressLists {
+		p.Children = append(p.Children, l.param())
+	}
+	p.Children = append(p.Children, cd.ConnectData.param())
+	return p
+}
+
+func onOffParams(failover, loadBalance OnOff) []Param {
+	var params []Param
+	if failover != Unset {
+		params = append(params, Param{Key: "FAILOVER", Value: failover.String()})
+	}
+	if loadBalance != Unset {
+		params = append(params, Param{Key: "LOAD_BALANCE", Value: loadBalance.String()})
+	}
+	return params
+}
+
+// ParseDescriptor parses a (DESCRIPTION=...) or a (DESCRIPTION_LIST=...) connect descriptor.
+func ParseDescriptor(s string) (ConnectDescriptor, error) {
+	p := &parser{s: stripComments(s)}
+	p.skipSpace()
+	param, err := p.group()
+	if err != nil {
+		return ConnectDescriptor{}, err
+	}
+	if p.skipSpace(); p.pos < len(p.s) {
+		return ConnectDescriptor{}, p.errorf("unexpected %q after the descriptor", p.s[p.pos:])
+	}
+	return descriptor(param)
+}
+
+// isDescriptor reports whether p is a DESCRIPTION or a DESCRIPTION_LIST.
+func isDescriptor(p Param) bool {
+	return strings.EqualFold(p.Key, "DESCRIPTION") || strings.EqualFold(p.Key, "DESCRIPTION_LIST")
+}
+
+// descriptor returns the ConnectDescriptor of the DESCRIPTION or DESCRIPTION_LIST parameter.
+func descriptor(p Param) (ConnectDescriptor, error) {
+	var cd ConnectDescriptor
+	if strings.EqualFold(p.Key, "DESCRIPTION_LIST") {
+		return descriptionList(p)
+	}
+	if !strings.EqualFold(p.Key, "DESCRIPTION") {
+		return cd, fmt.Errorf("got %s, wanted DESCRIPTION or DESCRIPTION_LIST", p.Key)
+	}
+	var hasConnectData bool
+	for _, c := range p.Children {
+		var err error
+		switch strings.ToUpper(c.Key) {
+		case "ADDRESS":
+			var a Address
+			if a, err = address(c); err == nil {
+				cd.Addresses = append(cd.Addresses, a)
+			}
+		case "ADDRESS_LIST":
+			var l AddressList
+			if l, err = addressList(c); err == nil {
+				cd.AddressLists = append(cd.AddressLists, l)
+			}
+		case "FAILOVER":
+			cd.Failover, err = parseOnOff(c.Key, c.Value)
+		case "LOAD_BALANCE":
+			cd.LoadBalance, err = parseOnOff(c.Key, c.Value)
+		case "CONNECT_DATA":
+			hasConnectData = true
+			cd.ConnectData, err = connectData(c)
+		default:
+			cd.Params = append(cd.Params, c)
+		}
+		if err != nil {
+			return cd, err
+		}
+	}
+	if len(cd.Addresses) == 0 && len(cd.AddressLists) == 0 {
+		return cd, fmt.Errorf("DESCRIPTION: no ADDRESS")
+	}
+	if !hasConnectData {
+		return cd, fmt.Errorf("DESCRIPTION: no CONNECT_DATA")
+	}
+	return cd, nil
+}
+
+func descriptionList(p Param) (ConnectDescriptor, error) {
+	var cd ConnectDescriptor
+	for _, c := range p.Children {
+		var err error
+		switch strings.ToUpper(c.Key) {
+		case "DESCRIPTION":
+			var d ConnectDescriptor
+			if d, err = descriptor(c); err == nil {
+				cd.Descriptions = append(cd.Descriptions, d)
+			}
+		case "FAILOVER":
+			cd.Failover, err = parseOnOff(c.Key, c.Value)
+		case "LOAD_BALANCE":
+			cd.LoadBalance, err = parseOnOff(c.Key, c.Value)
+		default:
+			cd.Params = append(cd.Params, c)
+		}
+		if err != nil {
+			return cd, err
+		}
+	}
+	if len(cd.Descriptions) == 0 {
+		return cd, fmt.Errorf("DESCRIPTION_LIST: no DESCRIPTION")
+	}
+	return cd, nil
+}
+
+func addressList(p Param) (AddressList, error) {
+	var l AddressList
+	for _, c := range p.Children {
+		var err error
+		switch strings.ToUpper(c.Key) {
+		case "ADDRESS":
+			var a Address
+			if a, err = address(c); err == nil {
+				l.Addresses = append(l.Addresses, a)
+			}
+		case "FAILOVER":
+			l.Failover, err = parseOnOff(c.Key, c.Value)
+		case "LOAD_BALANCE":
+			l.LoadBalance, err = parseOnOff(c.Key, c.Value)
+		default:
+			err = fmt.Errorf("ADDRESS_LIST: unknown parameter %s", c.Key)
+		}
+		if err != nil {
+			return l, err
+		}
+	}
+	if len(l.Addresses) == 0 {
+		return l, fmt.Errorf("ADDRESS_LIST: no ADDRESS")
+	}
+	return l, nil
+}
+
+func address(p Param) (Address, error) {
+	var a Address
+	for _, c := range p.Children {
+		switch strings.ToUpper(c.Key) {
+		case "PROTOCOL":
+			a.Protocol = strings.ToUpper(c.Value)
+		case "HOST":
+			a.Host = c.Value
+		case "PORT":
+			port, err := strconv.ParseUint(c.Value, 10, 16)
+			if err != nil {
+				return a, fmt.Errorf("ADDRESS: PORT %q: %v", c.Value, err)
+			}
+			a.Port = int(port)
+		default:
+			a.Params = append(a.Params, c)
+		}
+	}
+	if a.Protocol == "" {
+		return a, fmt.Errorf("ADDRESS %s: no PROTOCOL", p)
+	}
+	return a, nil
+}
+
+func connectData(p Param) (ConnectData, error) {
+	var d ConnectData
+	for _, c := range p.Children {
+		switch strings.ToUpper(c.Key) {
+		case "SERVICE_NAME":
+			d.ServiceName = c.Value
+		case "SID":
+			d.SID = c.Value
+		case "INSTANCE_NAME":
+			d.InstanceName = c.Value
+		case "SERVER":
+			d.Server = strings.ToUpper(c.Value)
+			switch d.Server {
+			case "DEDICATED", "SHARED", "POOLED":
+			default:
+				return d, fmt.Errorf("CONNECT_DATA: unknown SERVER %q", c.Value)
+			}
+		default:
+			d.Params = append(d.Params, c)
+		}
+	}
+	if d.ServiceName == "" && d.SID == "" {
+		return d, fmt.Errorf("CONNECT_DATA: no SERVICE_NAME or SID")
+	}
+	return d, nil
+}
+
+// parser is a recursive descent parser of the keyword-value syntax
+// of the tnsnames.ora and sqlnet.ora files.
+type parser struct {
+	s   string
+	pos int
+}
+
+// entry is a top level "NAME1, NAME2 = value" entry.
+type entry struct {
+	names []string
+	Param
+}
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+	line := 1 + strings.Count(p.s[:p.pos], "\n")
+	return fmt.Errorf("line %d: %s", line, fmt.Sprintf(format, args...))
+}
+
+func (p *parser) skipSpace() {
+	for p.pos < len(p.s) && strings.IndexByte(" \t\r\n", p.s[p.pos]) >= 0 {
+		p.pos++
+	}
+}
+
+func (p *parser) expect(c byte) error {
+	if p.skipSpace(); p.pos >= len(p.s) || p.s[p.pos] != c {
+		return p.errorf("expected %q", c)
+	}
+	p.pos++
+	return nil
+}
+
+// word returns the next keyword or name.
+func (p *parser) word() (string, error) {
+	p.skipSpace()
+	start := p.pos
+	for p.pos < len(p.s) && strings.IndexByte("()=,\"' \t\r\n", p.s[p.pos]) < 0 {
+		p.pos++
+	}
+	if p.pos == start {
+		return "", p.errorf("expected a name")
+	}
+	return p.s[start:p.pos], nil
+}
+
+// value returns the value of a (KEY=value) pair, quoted, a list in parens,
+// or up to the closing paren.
+func (p *parser) value() (string, error) {
+	p.skipSpace()
+	if p.pos < len(p.s) && p.s[p.pos] == '(' {
+		return p.list()
+	}
+	if p.pos < len(p.s) && (p.s[p.pos] == '"' || p.s[p.pos] == '\'') {
+		q := p.s[p.pos]
+		end := strings.IndexByte(p.s[p.pos+1:], q)
+		if end < 0 {
+			return "", p.errorf("unterminated quote")
+		}
+		v := p.s[p.pos : p.pos+end+2]
+		p.pos += end + 2
+		return v, nil
+	}
+	end := strings.IndexAny(p.s[p.pos:], "()")
+	if end < 0 || p.s[p.pos+end] == '(' {
+		return "", p.errorf("expected ')'")
+	}
+	v := strings.TrimSpace(p.s[p.pos : p.pos+end])
+	p.pos += end
+	return v, nil
+}
+
+// list returns a parenthesised value which is not a group, as "(TNSNAMES, EZCONNECT)",
+// with the parens.
+func (p *parser) list() (string, error) {
+	start, depth := p.pos, 0
+	for ; p.pos < len(p.s); p.pos++ {
+		switch p.s[p.pos] {
+		case '(':
+			depth++
+		case ')':
+			if depth--; depth == 0 {
+				p.pos++
+				return p.s[start:p.pos], nil
+			}
+		}
+	}
+	p.pos = start
+	return "", p.errorf("expected ')'")
+}
+
+// isGroup reports whether a (KEY=...) group follows.
+func (p *parser) isGroup() bool {
+	start := p.pos
+	defer func() { p.pos = start }()
+	if p.expect('(') != nil {
+		return false
+	}
+	if _, err := p.word(); err != nil {
+		return false
+	}
+	return p.expect('=') == nil
+}
+
+// group parses a (KEY=value) or (KEY=(...)(...)) group.
+func (p *parser) group() (Param, error) {
+	var param Param
+	if err := p.expect('('); err != nil {
+		return param, err
+	}
+	var err error
+	if param.Key, err = p.word(); err != nil {
+		return param, err
+	}
+	param.Key = strings.ToUpper(param.Key)
+	if err = p.expect('='); err != nil {
+		return param, err
+	}
+	if param.Children, err = p.groups(); err != nil {
+		return param, err
+	}
+	if len(param.Children) == 0 {
+		if param.Value, err = p.value(); err != nil {
+			return param, err
+		}
+	}
+	return param, p.expect(')')
+}
+
+// groups parses the (...)(...) groups, if any.
+func (p *parser) groups() ([]Param, error) {
+	var params []Param
+	for {
+		if !p.isGroup() {
+			return params, nil
+		}
+		param, err := p.group()
+		if err != nil {
+			return params, err
+		}
+		params = append(params, param)
+	}
+}
+
+// entries parses the "NAME1, NAME2 = value" entries of a file.
+func (p *parser) entries() ([]entry, error) {
+	var entries []entry
+	for {
+		if p.skipSpace(); p.pos >= len(p.s) {
+			return entries, nil
+		}
+		var e entry
+		for {
+			name, err := p.word()
+			if err != nil {
+				return entries, err
+			}
+			e.names = append(e.names, name)
+			if p.skipSpace(); p.pos >= len(p.s) || p.s[p.pos] != ',' {
+				break
+			}
+			p.pos++
+		}
+		e.Key = e.names[0]
+		if err := p.expect('='); err != nil {
+			return entries, err
+		}
+		var err error
+		if e.Children, err = p.groups(); err != nil {
+			return entries, err
+		}
+		switch p.skipSpace(); {
+		case len(e.Children) != 0:
+		case p.pos < len(p.s) && p.s[p.pos] == '(':
+			// a list, as (TNSNAMES, EZCONNECT)
+			if e.Value, err = p.list(); err != nil {
+				return entries, err
+			}
+		default:
+			// a value up to the end of the line
+			end := strings.IndexByte(p.s[p.pos:], '\n')
+			if end < 0 {
+				end = len(p.s) - p.pos
+			}
+			e.Value = strings.TrimSpace(p.s[p.pos : p.pos+end])
+			p.pos += end
+		}
+		entries = append(entries, e)
+	}
+}
+
+// stripComments removes the # comments, up to the end of the line, outside of quotes.
+func stripComments(s string) string {
+	if strings.IndexByte(s, '#') < 0 {
+		return s
+	}
+	var buf bytes.Buffer
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '#':
+			end := strings.IndexByte(s[i:], '\n')
+			if end < 0 {
+				return buf.String()
+			}
+			i += end
+			c = '\n'
+		}
+		buf.WriteByte(c)
+	}
+	return buf.String()
+}
@@ -0,0 +1,219 @@
+// Copyright 2014 Rana Ian. All rights reserved.
+// Use of this source code is governed by The MIT License
+// found in the accompanying LICENSE file.
+
+package tns
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseDescriptor(t *testing.T) {
+	for i, tc := range []struct {
+		in, want string
+		pooled   bool
+	}{
+		{"(DESCRIPTION=(ADDRESS=(PROTOCOL=TCP)(HOST=h)(PORT=1521))(CONNECT_DATA=(SERVICE_NAME=s)))",
+			"(DESCRIPTION=(ADDRESS=(PROTOCOL=TCP)(HOST=h)(PORT=1521))(CONNECT_DATA=(SERVICE_NAME=s)))", false},
+		{`(description =
+			(failover = yes) # comment
+			(address_list = (load_balance=on)
+				(address = (protocol = tcp)(host = h1)(port = 1521))
+				(address = (protocol = tcp)(host = h2)(port = 1522)))
+			(connect_timeout = 5)
+			(connect_data = (service_name = orcl.example.com)(server = pooled)))`,
+			"(DESCRIPTION=(FAILOVER=ON)(CONNECT_TIMEOUT=5)(ADDRESS_LIST=(LOAD_BALANCE=ON)" +
+				"(ADDRESS=(PROTOCOL=TCP)(HOST=h1)(PORT=1521))(ADDRESS=(PROTOCOL=TCP)(HOST=h2)(PORT=1522)))" +
+				"(CONNECT_DATA=(SERVICE_NAME=orcl.example.com)(SERVER=POOLED)))", true},
+		{`(DESCRIPTION=(ADDRESS=(PROTOCOL=IPC)(KEY="a#b"))(CONNECT_DATA=(SID=x)(FAILOVER_MODE=(TYPE=select))))`,
+			`(DESCRIPTION=(ADDRESS=(PROTOCOL=IPC)(KEY="a#b"))(CONNECT_DATA=(SID=x)(FAILOVER_MODE=(TYPE=select))))`, false},
+		{`(DESCRIPTION_LIST = (FAILOVER = on)(LOAD_BALANCE = off)
+			(DESCRIPTION = (ADDRESS = (PROTOCOL = TCP)(HOST = site1)(PORT = 1521))
+				(CONNECT_DATA = (SERVICE_NAME = s)(SERVER = POOLED)))
+			(DESCRIPTION = (ADDRESS = (PROTOCOL = TCP)(HOST = site2)(PORT = 1521))
+				(CONNECT_DATA = (SERVICE_NAME = s)(SERVER = POOLED))))`,
+			"(DESCRIPTION_LIST=(FAILOVER=ON)(LOAD_BALANCE=OFF)" +
+				"(DESCRIPTION=(ADDRESS=(PROTOCOL=TCP)(HOST=site1)(PORT=1521))(CONNECT_DATA=(SERVICE_NAME=s)(SERVER=POOLED)))" +
+				"(DESCRIPTION=(ADDRESS=(PROTOCOL=TCP)(HOST=site2)(PORT=1521))(CONNECT_DATA=(SERVICE_NAME=s)(SERVER=POOLED))))", true},
+	} {
+		cd, err := ParseDescriptor(tc.in)
+		if err != nil {
+			t.Errorf("%d. %v", i, err)
+			continue
+		}
+		if got := cd.String(); got != tc.want {
+			t.Errorf("%d. got\n%s\nwanted\n%s", i, got, tc.want)
+		}
+		if cd.Pooled() != tc.pooled {
+			t.Errorf("%d. got pooled %t, wanted %t", i, cd.Pooled(), tc.pooled)
+		}
+		back, err := ParseDescriptor(cd.String())
+		if err != nil {
+			t.Errorf("%d. %v", i, err)
+		} else if !reflect.DeepEqual(back, cd) {
+			t.Errorf("%d. round trip: got %#v, wanted %#v", i, back, cd)
+		}
+	}
+
+	for i, in := range []string{
+		"(DESCRIPTION=(ADDRESS=(PROTOCOL=TCP)(HOST=h)(PORT=1521))(CONNECT_DATA=(SERVICE_NAME=s))",
+		"(DESCRIPTION=(ADDRESS=(PROTOCOL=TCP)(HOST=h)(PORT=x))(CONNECT_DATA=(SERVICE_NAME=s)))",
+		"(DESCRIPTION=(ADDRESS=(HOST=h)(PORT=1521))(CONNECT_DATA=(SERVICE_NAME=s)))",
+		"(DESCRIPTION=(ADDRESS=(PROTOCOL=TCP)(HOST=h)(PORT=1521))(CONNECT_DATA=(SERVICE_NAM=s)))",
+		"(DESCRIPTION=(ADDRESS=(PROTOCOL=TCP)(HOST=h)(PORT=1521)))",
+		"(DESCRIPTION=(FAILOVER=maybe)(ADDRESS=(PROTOCOL=TCP))(CONNECT_DATA=(SID=s)))",
+		"(DESCRIPTION=(ADDRESS=(PROTOCOL=TCP))(CONNECT_DATA=(SID=s)(SERVER=shard)))",
+		"(DESCRIPTION=(ADDRESS=(PROTOCOL=TCP))(CONNECT_DATA=(SID=s))) x",
+		"(ADDRESS=(PROTOCOL=TCP))",
+		"(DESCRIPTION_LIST=(FAILOVER=on))",
+		"(DESCRIPTION_LIST=(DESCRIPTION=(ADDRESS=(PROTOCOL=TCP))))",
+	} {
+		if _, err := ParseDescriptor(in); err == nil {
+			t.Errorf("%d. %q: wanted error", i, in)
+		} else {
+			t.Logf("%d. %v", i, err)
+		}
+	}
+}
+
+func TestBuildDescriptor(t *testing.T) {
+	cd := ConnectDescriptor{
+		AddressLists: []AddressList{{
+			Failover: On,
+			Addresses: []Address{
+				{Protocol: "tcp", Host: "primary", Port: 1521},
+				{Protocol: "tcp", Host: "standby", Port: 1521},
+			},
+		}},
+		ConnectData: ConnectData{ServiceName: "svc", Server: "pooled"},
+	}
+	want := "(DESCRIPTION=(ADDRESS_LIST=(FAILOVER=ON)(ADDRESS=(PROTOCOL=TCP)(HOST=primary)(PORT=1521))" +
+		"(ADDRESS=(PROTOCOL=TCP)(HOST=standby)(PORT=1521)))(CONNECT_DATA=(SERVICE_NAME=svc)(SERVER=POOLED)))"
+	if got := cd.String(); got != want {
+		t.Errorf("got\n%s\nwanted\n%s", got, want)
+	}
+	if !cd.Pooled() {
+		t.Errorf("not pooled")
+	}
+
+	list := ConnectDescriptor{Failover: On, Descriptions: []ConnectDescriptor{
+		{Addresses: []Address{{Protocol: "TCP", Host: "site1"}}, ConnectData: ConnectData{ServiceName: "svc"}},
+		{Addresses: []Address{{Protocol: "TCP", Host: "site2"}}, ConnectData: ConnectData{ServiceName: "svc", Server: "POOLED"}},
+	}}
+	if list.Pooled() {
+		t.Errorf("a list with a DESCRIPTION not pooled is pooled")
+	}
+	pooled := list
+	pooled.SetServer("pooled")
+	if !pooled.Pooled() || list.Descriptions[0].ConnectData.Server != "" {
+		t.Errorf("SetServer: got %s from %s", pooled, list)
+	}
+}
+
+func TestResolve(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tns-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	for name, content := range map[string]string{
+		"tnsnames.ora": `# the main file
+ORCL, ORCL.WORLD =
+  (DESCRIPTION =
+    (ADDRESS = (PROTOCOL = TCP)(HOST = db)(PORT = 1521))
+    (CONNECT_DATA = (SERVICE_NAME = orcl)))
+
+LISTENER_ORCL = (ADDRESS = (PROTOCOL = TCP)(HOST = db)(PORT = 1521))
+
+DG =
+  (DESCRIPTION_LIST =
+    (FAILOVER = on)
+    (DESCRIPTION = (ADDRESS = (PROTOCOL = TCP)(HOST = site1)(PORT = 1521))
+      (CONNECT_DATA = (SERVICE_NAME = dg)))
+    (DESCRIPTION = (ADDRESS = (PROTOCOL = TCP)(HOST = site2)(PORT = 1521))
+      (CONNECT_DATA = (SERVICE_NAME = dg))))
+
+MULTI = (DESCRIPTION=(ADDRESS=(PROTOCOL=TCP)(HOST=m1))(CONNECT_DATA=(SID=m)))
+        (DESCRIPTION=(ADDRESS=(PROTOCOL=TCP)(HOST=m2))(CONNECT_DATA=(SID=m)))
+
+IFILE = sub/other.ora
+`,
+		"sub/other.ora": `other.example.com=(DESCRIPTION=(ADDRESS=(PROTOCOL=TCP)(HOST=o)(PORT=1521))(CONNECT_DATA=(SID=o)))
+ifile="../cycle.ora"`,
+		"cycle.ora": "IFILE = tnsnames.ora\n",
+		"sqlnet.ora": `# a typical sqlnet.ora
+NAMES.DIRECTORY_PATH= (TNSNAMES, EZCONNECT)
+SQLNET.AUTHENTICATION_SERVICES = (NTS)
+SQLNET.EXPIRE_TIME = 10
+ADR_BASE = /u01/app/oracle
+NAMES.DEFAULT_DOMAIN = example.com
+`,
+	} {
+		path := filepath.Join(dir, name)
+		if err = os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err = ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// cycle.ora includes tnsnames.ora again
+	if _, err = ParseFile(filepath.Join(dir, "tnsnames.ora")); err == nil || !strings.Contains(err.Error(), "cycle") {
+		t.Fatalf("got %v, wanted IFILE cycle error", err)
+	}
+	if err = ioutil.WriteFile(filepath.Join(dir, "cycle.ora"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	names, err := ParseFile(filepath.Join(dir, "tnsnames.ora"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(names) != 5 {
+		t.Errorf("got %d names, wanted 5: %v", len(names), names)
+	}
+	if cd, ok := names.Lookup("orcl.world"); !ok || cd.ConnectData.ServiceName != "orcl" {
+		t.Errorf("orcl.world: got %v, %t", cd, ok)
+	}
+	if cd, ok := names.Lookup("dg"); !ok || len(cd.Descriptions) != 2 || cd.Failover != On {
+		t.Errorf("dg: got %v, %t", cd, ok)
+	}
+	if cd, ok := names.Lookup("multi"); !ok || len(cd.Descriptions) != 2 {
+		t.Errorf("multi: got %v, %t", cd, ok)
+	}
+	if _, ok := names.Lookup("listener_orcl"); ok {
+		t.Errorf("the listener address is not skipped")
+	}
+
+	if domain := DefaultDomain(dir); domain != "example.com" {
+		t.Errorf("got default domain %q, wanted example.com", domain)
+	}
+	p := &parser{s: stripComments(`NAMES.DIRECTORY_PATH= (TNSNAMES, EZCONNECT)
+X = (A=1)(B=(C=2))`)}
+	entries, err := p.entries()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 || entries[0].Value != "(TNSNAMES, EZCONNECT)" || len(entries[1].Children) != 2 {
+		t.Errorf("got %+v", entries)
+	}
+
+	os.Setenv("TNS_ADMIN", dir)
+	defer os.Unsetenv("TNS_ADMIN")
+	cd, err := Resolve("other")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "(DESCRIPTION=(ADDRESS=(PROTOCOL=TCP)(HOST=o)(PORT=1521))(CONNECT_DATA=(SID=o)))"; cd.String() != want {
+		t.Errorf("got %s, wanted %s", cd, want)
+	}
+	if _, err = Resolve("missing"); err == nil {
+		t.Errorf("wanted error for a missing alias")
+	}
+}
@@ -12,6 +12,7 @@ import "C"
 import (
 	"fmt"
 	"sync"
+	"unsafe"
 )
 
 // LogTxCfg represents Tx logging configuration values.
@@ -44,6 +45,16 @@ type Tx struct {
 	cmu sync.Mutex
 	id  uint64
 	ses *Ses
+
+	// the transaction handle of a global transaction, and the previous
+	// transaction handle of the session, restored at close
+	ocitrans, prevTrans unsafe.Pointer
+	xid                 *XID
+
+	// savepoints are the names of the savepoints set, oldest first;
+	// spSeq numbers the savepoints of Ses.InTx.
+	savepoints []string
+	spSeq      uint32
 }
 
 // checkIsOpen validates that the session is open.
@@ -75,34 +86,46 @@ func (tx *Tx) close() (err error) {
 	defer tx.cmu.Unlock()
 	var ok bool
 	tx.Lock()
+	ses, trans, prev := tx.ses, tx.ocitrans, tx.prevTrans
 	if tx.ses != nil {
 		tx.ses = nil
 		ok = true
 	}
+	tx.ocitrans, tx.prevTrans, tx.xid = nil, nil, nil
+	tx.savepoints, tx.spSeq = tx.savepoints[:0], 0
 	tx.Unlock()
+	if ses != nil && trans != nil {
+		err = ses.restoreTrans(trans, prev)
+	}
 	if ok {
 		_drv.txPool.Put(tx)
 	}
-	return nil
+	return err
 }
 
 // Commit commits the transaction.
+// A global transaction is committed in one phase.
 //
 // Commit is a member of the driver.Tx interface.
 func (tx *Tx) Commit() (err error) {
 	if tx == nil {
 		return nil
 	}
-	tx.log(_drv.Cfg().Log.Tx.Commit)
+	tx.log(tx.logCfg().Tx.Commit)
 	if err = tx.checkIsOpen(); err != nil {
 		return err
 	}
+	return tx.commit(C.OCI_DEFAULT)
+}
+
+// commit commits the transaction with the flags, and closes the Tx.
+func (tx *Tx) commit(flags C.ub4) error {
 	defer tx.closeWithRemove()
 	tx.RLock()
 	r := C.OCITransCommit(
 		tx.ses.ocisvcctx,      //OCISvcCtx    *svchp,
 		tx.ses.srv.env.ocierr, //OCIError     *errhp,
-		C.OCI_DEFAULT)         //ub4          flags );
+		flags)                 //ub4          flags );
 	tx.RUnlock()
 	if r == C.OCI_ERROR {
 		return tx.ses.srv.env.ociError()
@@ -117,7 +140,7 @@ func (tx *Tx) Rollback() (err error) {
 	if tx == nil {
 		return nil
 	}
-	tx.log(_drv.Cfg().Log.Tx.Rollback)
+	tx.log(tx.logCfg().Tx.Rollback)
 	if err = tx.checkIsOpen(); err != nil {
 		return err
 	}
@@ -148,13 +171,22 @@ func (tx *Tx) sysName() string {
 	return tx.ses.sysName() + fmt.Sprintf("T%v", tx.id)
 }
 
+// logCfg returns the LogDrvCfg of the Env of the Tx.
+func (tx *Tx) logCfg() LogDrvCfg {
+	if tx == nil {
+		return _drv.Cfg().Log
+	}
+	return tx.ses.logCfg()
+}
+
 // log writes a message with an Tx system name and caller info.
 func (tx *Tx) log(enabled bool, v ...interface{}) {
 	if enabled {
+		Log := tx.logCfg()
 		if len(v) == 0 {
-			_drv.Cfg().Log.Logger.Infof("%v %v", tx.sysName(), callInfo(1))
+			Log.Logger.Infof("%v %v", tx.sysName(), callInfo(1))
 		} else {
-			_drv.Cfg().Log.Logger.Infof("%v %v %v", tx.sysName(), callInfo(1), fmt.Sprint(v...))
+			Log.Logger.Infof("%v %v %v", tx.sysName(), callInfo(1), fmt.Sprint(v...))
 		}
 	}
 }
@@ -162,10 +194,11 @@ func (tx *Tx) log(enabled bool, v ...interface{}) {
 // log writes a formatted message with an Tx system name and caller info.
 func (tx *Tx) logF(enabled bool, format string, v ...interface{}) {
 	if enabled {
+		Log := tx.logCfg()
 		if len(v) == 0 {
-			_drv.Cfg().Log.Logger.Infof("%v %v", tx.sysName(), callInfo(1))
+			Log.Logger.Infof("%v %v", tx.sysName(), callInfo(1))
 		} else {
-			_drv.Cfg().Log.Logger.Infof("%v %v %v", tx.sysName(), callInfo(1), fmt.Sprintf(format, v...))
+			Log.Logger.Infof("%v %v %v", tx.sysName(), callInfo(1), fmt.Sprintf(format, v...))
 		}
 	}
 }
@@ -7,6 +7,7 @@ package ora
 import (
 	"bytes"
 	"container/list"
+	"context"
 	"database/sql/driver"
 	"encoding/json"
 	"fmt"
@@ -639,6 +640,52 @@ func (this *Lob) Read(p []byte) (int, error) {
 	return this.Reader.Read(p)
 }
 
+// ReadContext reads from the LOB as Read does, and interrupts the read as ctx is done.
+// The reader of a LOB fetched with L reads with the context of Rset.NextContext
+// (or of QueryContext) otherwise.
+func (this *Lob) ReadContext(ctx context.Context, p []byte) (int, error) {
+	if this == nil || this.Reader == nil {
+		return 0, io.EOF
+	}
+	if rc, ok := this.Reader.(interface {
+		ReadContext(context.Context, []byte) (int, error)
+	}); ok {
+		return rc.ReadContext(ctx, p)
+	}
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	return this.Reader.Read(p)
+}
+
+// ReadAt reads from the LOB at off, if the underlying Reader is an io.ReaderAt.
+// The reader of a LOB fetched with L is an io.ReaderAt, with off in bytes,
+// for CLOBs in bytes of the UTF-8 text.
+func (this *Lob) ReadAt(p []byte, off int64) (int, error) {
+	if this == nil || this.Reader == nil {
+		return 0, io.EOF
+	}
+	ra, ok := this.Reader.(io.ReaderAt)
+	if !ok {
+		return 0, fmt.Errorf("%T is not an io.ReaderAt", this.Reader)
+	}
+	return ra.ReadAt(p, off)
+}
+
+// Seek seeks in the LOB, if the underlying Reader is an io.Seeker.
+// The reader of a LOB fetched with L is an io.Seeker, with offset in bytes,
+// for CLOBs in bytes of the UTF-8 text: io.SeekEnd reads all of a CLOB.
+func (this *Lob) Seek(offset int64, whence int) (int64, error) {
+	if this == nil || this.Reader == nil {
+		return 0, io.EOF
+	}
+	sk, ok := this.Reader.(io.Seeker)
+	if !ok {
+		return 0, fmt.Errorf("%T is not an io.Seeker", this.Reader)
+	}
+	return sk.Seek(offset, whence)
+}
+
 // Equals returns true when the receiver and specified Lob are both null,
 // or when they both not null and share the same Reader.
 func (this *Lob) Equals(other Lob) bool {
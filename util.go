@@ -8,6 +8,7 @@ import (
 	"runtime"
 	"strings"
 	"sync"
+	"unicode/utf8"
 )
 
 // checkNumericColumn returns nil when the column type is numeric; otherwise, an error.
@@ -242,10 +243,13 @@ func (e oraErr) Code() int {
 		return 0
 	}
 	var code int
-	fmt.Scanf(errS[i+4:], "%d", &code)
+	fmt.Sscanf(errS[i+4:], "%d", &code)
 	return code
 }
 
+// Unwrap returns the underlying error, for errors.Is and errors.As.
+func (e *oraErr) Unwrap() error { return e.Underlying }
+
 // DescribedColumn type for describing a column (see DescribeQuery).
 type DescribedColumn struct {
 	Column
@@ -453,3 +457,21 @@ func boundingPower(n int) int {
 	}
 	return i
 }
+
+// utf8Boundary returns the length of the longest prefix of p
+// which does not end with an incomplete or invalid UTF-8 sequence,
+// such as a character split at a chunk boundary, or half of a surrogate pair.
+func utf8Boundary(p []byte) int {
+	n := len(p)
+	// a UTF-8 sequence is at most utf8.UTFMax bytes long
+	for i := n - 1; i >= 0 && i >= n-utf8.UTFMax; i-- {
+		if !utf8.RuneStart(p[i]) {
+			continue
+		}
+		if r, size := utf8.DecodeRune(p[i:]); r == utf8.RuneError && size <= 1 {
+			return i
+		}
+		return n
+	}
+	return n
+}
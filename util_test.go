@@ -1,6 +1,10 @@
 package ora
 
-import "testing"
+import (
+	"context"
+	"testing"
+	"time"
+)
 
 func TestBoundingPower(t *testing.T) {
 	for i, inOut := range [][2]int{
@@ -21,3 +25,155 @@ func TestBoundingPower(t *testing.T) {
 		}
 	}
 }
+
+func TestUTF8Boundary(t *testing.T) {
+	smiley := "\U0001F600" // 4 bytes
+	for i, tc := range []struct {
+		in   string
+		want int
+	}{
+		{"", 0},
+		{"abc", 3},
+		{"árvíz", 7},
+		{"rá"[:2], 1},
+		{"a" + smiley, 5},
+		{"a" + smiley[:1], 1},
+		{"a" + smiley[:2], 1},
+		{"a" + smiley[:3], 1},
+		{"日本" + "語"[:2], 6},
+		{"a�", 4},
+		{"a\xed\xa0\x80", 1}, // half of a surrogate pair, as CESU-8
+	} {
+		if got := utf8Boundary([]byte(tc.in)); got != tc.want {
+			t.Errorf("%d. %q: got %d, wanted %d.", i, tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestDSNParams(t *testing.T) {
+	for i, tc := range []struct {
+		in               string
+		user, passw, sid string
+		mode             SessionMode
+		stmtCacheSize    uint32
+	}{
+		{"user/passw@sid", "user", "passw", "sid", SysDefault, 0},
+		{"user/passw@sid?stmt_cache=20", "user", "passw", "sid", SysDefault, 20},
+		{"user/passw@sid AS SYSDBA?stmt_cache=5", "user", "passw", "sid", SysDba, 5},
+		{"user/pa?ss@sid", "user", "pa?ss", "sid", SysDefault, 0},
+		{"user/pa?ss@sid?stmt_cache=x", "user", "pa?ss", "sid", SysDefault, 0},
+		{"/@sid?stmt_cache=1", "", "", "sid", SysDefault, 1},
+	} {
+		user, passw, sid := SplitDSN(tc.in)
+		if user != tc.user || passw != tc.passw || sid != tc.sid {
+			t.Errorf("%d. %q: got %q/%q@%q, wanted %q/%q@%q.", i, tc.in, user, passw, sid, tc.user, tc.passw, tc.sid)
+		}
+		if mode := DSNMode(tc.in); mode != tc.mode {
+			t.Errorf("%d. %q: got mode %v, wanted %v.", i, tc.in, mode, tc.mode)
+		}
+		if size := DSNStmtCacheSize(tc.in); size != tc.stmtCacheSize {
+			t.Errorf("%d. %q: got stmt cache size %d, wanted %d.", i, tc.in, size, tc.stmtCacheSize)
+		}
+	}
+}
+
+func TestWithClientInfo(t *testing.T) {
+	ctx := WithClientInfo(context.Background(), ClientInfo{ClientIdentifier: "alice", Module: "m"})
+	ctx = WithModuleAction(ctx, "", "list")
+	ctx = WithDBOp(ctx, "op")
+	ci, ok := ctxClientInfo(ctx)
+	if want := (ClientInfo{ClientIdentifier: "alice", Module: "m", Action: "list", DBOp: "op"}); !ok || ci != want {
+		t.Errorf("got %+v, wanted %+v", ci, want)
+	}
+	if _, ok = ctxClientInfo(context.Background()); ok {
+		t.Errorf("got ClientInfo from an empty context")
+	}
+}
+
+func TestCallTimeout(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Hour)
+	defer cancel()
+	for i, tc := range []struct {
+		ctx      context.Context
+		timeout  time.Duration
+		min, max time.Duration
+	}{
+		{context.Background(), 0, 0, 0},
+		{context.Background(), time.Second, time.Second, time.Second},
+		{ctx, time.Second, time.Second, time.Second},
+		{ctx, 0, time.Hour - time.Minute, time.Hour},
+		{ctx, 2 * time.Hour, time.Hour - time.Minute, time.Hour},
+	} {
+		if got := callTimeout(tc.ctx, tc.timeout); got < tc.min || got > tc.max {
+			t.Errorf("%d. got %s, wanted between %s and %s", i, got, tc.min, tc.max)
+		}
+	}
+}
+
+func TestTimeoutErr(t *testing.T) {
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	other := &ORAError{code: 942}
+	for i, tc := range []struct {
+		ctx     context.Context
+		err     error
+		broken  bool
+		timeout bool
+		want    error
+	}{
+		{context.Background(), nil, false, false, nil},
+		{context.Background(), other, true, false, other},
+		{context.Background(), &ORAError{code: 3156}, false, true, nil},
+		{context.Background(), &ORAError{code: 1013}, false, false, nil},
+		{context.Background(), &ORAError{code: 1013}, true, true, nil},
+		{canceled, &ORAError{code: 1013}, true, false, context.Canceled},
+	} {
+		err := timeoutErr(tc.ctx, tc.err, tc.broken)
+		if IsTimeout(err) != tc.timeout {
+			t.Errorf("%d. got %v, wanted timeout %t", i, err, tc.timeout)
+		}
+		if !tc.timeout && tc.want != nil && err != tc.want {
+			t.Errorf("%d. got %v, wanted %v", i, err, tc.want)
+		}
+		if te, ok := err.(*TimeoutError); ok && te.Code() != tc.err.(*ORAError).Code() {
+			t.Errorf("%d. got code %d", i, te.Code())
+		}
+	}
+}
+
+func TestCheckSavepointName(t *testing.T) {
+	for _, name := range []string{"a", "sp_1", "ORA_INTX_12", "x$#"} {
+		if err := checkSavepointName(name); err != nil {
+			t.Errorf("%q: %v", name, err)
+		}
+	}
+	for _, name := range []string{"", "1a", "_a", "a b", "a;DROP", `"a"`} {
+		if err := checkSavepointName(name); err == nil {
+			t.Errorf("%q: wanted error", name)
+		}
+	}
+}
+
+func TestEnvLogCfg(t *testing.T) {
+	global := _drv.Cfg().Log
+	cfg := NewLogDrvCfg()
+	cfg.Logger = global.Logger
+	cfg.Stmt.Exe, cfg.Ses.Break = !global.Stmt.Exe, !global.Ses.Break
+	env := &Env{}
+	env.logc.Store(cfg)
+	ses := &Ses{}
+	ses.env.Store(env)
+	stmt := &Stmt{ses: ses}
+	stmt.env.Store(env)
+	tx := &Tx{ses: ses}
+	if got := stmt.logCfg().Stmt.Exe; got != cfg.Stmt.Exe {
+		t.Errorf("Stmt: got Exe %t, wanted the Env's %t", got, cfg.Stmt.Exe)
+	}
+	if got := tx.logCfg().Ses.Break; got != cfg.Ses.Break {
+		t.Errorf("Tx: got Break %t, wanted the Env's %t", got, cfg.Ses.Break)
+	}
+	var nilSes *Ses
+	if got := nilSes.logCfg().Ses.Break; got != global.Ses.Break {
+		t.Errorf("nil Ses: got Break %t, wanted the driver's %t", got, global.Ses.Break)
+	}
+}
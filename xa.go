@@ -0,0 +1,286 @@
+// Copyright 2014 Rana Ian. All rights reserved.
+// Use of this source code is governed by The MIT License
+// found in the accompanying LICENSE file.
+
+package ora
+
+/*
+#include <string.h>
+#include <oci.h>
+
+static void setXID(XID *xid, long formatID, const char *gtrid, long gtridLen, const char *bqual, long bqualLen) {
+	memset(xid, 0, sizeof(XID));
+	xid->formatID = formatID;
+	xid->gtrid_length = gtridLen;
+	xid->bqual_length = bqualLen;
+	memcpy(xid->data, gtrid, gtridLen);
+	memcpy(xid->data + gtridLen, bqual, bqualLen);
+}
+*/
+import "C"
+import (
+	"fmt"
+	"time"
+	"unsafe"
+)
+
+// XID is the global transaction identifier of a transaction branch, as in XA.
+type XID struct {
+	// FormatID identifies the format of the other fields; -1 is the null XID.
+	FormatID int32
+	// GlobalTransactionID is the global transaction ID, of 1 to 64 bytes.
+	GlobalTransactionID []byte
+	// BranchQualifier identifies the branch of the global transaction, of at most 64 bytes.
+	BranchQualifier []byte
+}
+
+func (x XID) String() string {
+	return fmt.Sprintf("%d:%x:%x", x.FormatID, x.GlobalTransactionID, x.BranchQualifier)
+}
+
+// validate returns an error if x is not a usable XID.
+func (x XID) validate() error {
+	if x.FormatID == -1 {
+		return errNew("XID: the null XID (FormatID -1) is not usable")
+	}
+	if n := len(x.GlobalTransactionID); n == 0 || n > 64 {
+		return errF("XID: GlobalTransactionID must be 1 to 64 bytes, not %d", n)
+	}
+	if n := len(x.BranchQualifier); n > 64 {
+		return errF("XID: BranchQualifier must be at most 64 bytes, not %d", n)
+	}
+	return nil
+}
+
+// TxXID makes StartTx start a branch of the global transaction xid,
+// to be prepared and committed in two phases (Tx.Prepare, Tx.CommitPrepared).
+//
+// The TxTimeout of a global transaction is the number of seconds a detached
+// branch may be inactive before it is rolled back; 60 by default.
+func TxXID(xid XID) TxOption { return func(o *txOption) { o.xid = &xid } }
+
+// attachTrans allocates a transaction handle with xid, and sets it as the
+// transaction of the session, returning it and the previous one.
+func (ses *Ses) attachTrans(xid XID) (trans, prev unsafe.Pointer, err error) {
+	if err = xid.validate(); err != nil {
+		return nil, nil, err
+	}
+	env := ses.Env()
+	if trans, err = env.allocOciHandle(C.OCI_HTYPE_TRANS); err != nil {
+		return nil, nil, errE(err)
+	}
+	var cxid C.XID
+	gtrid, bqual := xid.GlobalTransactionID, xid.BranchQualifier
+	var bqualp *C.char
+	if len(bqual) != 0 {
+		bqualp = (*C.char)(unsafe.Pointer(&bqual[0]))
+	}
+	C.setXID(&cxid, C.long(xid.FormatID),
+		(*C.char)(unsafe.Pointer(&gtrid[0])), C.long(len(gtrid)),
+		bqualp, C.long(len(bqual)))
+	if err = env.setAttr(trans, C.OCI_HTYPE_TRANS, unsafe.Pointer(&cxid), C.ub4(C.sizeof_XID), C.OCI_ATTR_XID); err != nil {
+		env.freeOciHandle(trans, C.OCI_HTYPE_TRANS)
+		return nil, nil, err
+	}
+	ses.RLock()
+	defer ses.RUnlock()
+	if r := C.OCIAttrGet(
+		unsafe.Pointer(ses.ocisvcctx), //const void     *trgthndlp,
+		C.OCI_HTYPE_SVCCTX,            //ub4            trghndltyp,
+		unsafe.Pointer(&prev),         //void           *attributep,
+		nil,                           //ub4            *sizep,
+		C.OCI_ATTR_TRANS,              //ub4            attrtype,
+		env.ocierr,                    //OCIError       *errhp );
+	); r == C.OCI_ERROR {
+		env.freeOciHandle(trans, C.OCI_HTYPE_TRANS)
+		return nil, nil, errE(env.ociError())
+	}
+	if err = env.setAttr(unsafe.Pointer(ses.ocisvcctx), C.OCI_HTYPE_SVCCTX, trans, 0, C.OCI_ATTR_TRANS); err != nil {
+		env.freeOciHandle(trans, C.OCI_HTYPE_TRANS)
+		return nil, nil, err
+	}
+	return trans, prev, nil
+}
+
+// restoreTrans sets prev as the transaction of the session, and frees trans.
+func (ses *Ses) restoreTrans(trans, prev unsafe.Pointer) error {
+	if trans == nil {
+		return nil
+	}
+	env := ses.Env()
+	ses.RLock()
+	var err error
+	if ses.ocisvcctx != nil {
+		err = env.setAttr(unsafe.Pointer(ses.ocisvcctx), C.OCI_HTYPE_SVCCTX, prev, 0, C.OCI_ATTR_TRANS)
+	}
+	ses.RUnlock()
+	if freeErr := env.freeOciHandle(trans, C.OCI_HTYPE_TRANS); err == nil {
+		err = freeErr
+	}
+	return err
+}
+
+// startGlobalTx starts (flags OCI_TRANS_NEW) or resumes (OCI_TRANS_RESUME)
+// the branch xid on the session.
+func (ses *Ses) startGlobalTx(xid XID, flags C.ub4, timeout time.Duration) (*Tx, error) {
+	trans, prev, err := ses.attachTrans(xid)
+	if err != nil {
+		return nil, err
+	}
+	seconds := C.uword(60)
+	if timeout > 0 {
+		seconds = C.uword((timeout + time.Second - 1) / time.Second)
+	}
+	ses.RLock()
+	env := ses.Env()
+	r := C.OCITransStart(
+		ses.ocisvcctx, //OCISvcCtx    *svchp,
+		env.ocierr,    //OCIError     *errhp,
+		seconds,       //uword        timeout,
+		flags)         //ub4          flags );
+	ses.RUnlock()
+	if r == C.OCI_ERROR {
+		err = errE(env.ociError())
+		ses.restoreTrans(trans, prev)
+		return nil, err
+	}
+	return ses.newTx(trans, prev, &xid), nil
+}
+
+// ResumeTx resumes the branch xid, detached by Tx.Detach, on the session.
+func (ses *Ses) ResumeTx(xid XID, opts ...TxOption) (*Tx, error) {
+	ses.log(ses.logCfg().Ses.StartTx)
+	if err := ses.checkClosed(); err != nil {
+		return nil, errE(err)
+	}
+	var o txOption
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return ses.startGlobalTx(xid, C.OCI_TRANS_RESUME|C.ub4(o.flags), o.timeout)
+}
+
+// PreparedTx returns a Tx for the prepared or heuristically completed branch xid,
+// to be finished on the session with CommitPrepared, Rollback or Forget,
+// as a transaction manager does in recovery.
+func (ses *Ses) PreparedTx(xid XID) (*Tx, error) {
+	if err := ses.checkClosed(); err != nil {
+		return nil, errE(err)
+	}
+	trans, prev, err := ses.attachTrans(xid)
+	if err != nil {
+		return nil, err
+	}
+	return ses.newTx(trans, prev, &xid), nil
+}
+
+// XID returns the XID of a global transaction, and false for a local one.
+func (tx *Tx) XID() (XID, bool) {
+	tx.RLock()
+	defer tx.RUnlock()
+	if tx.xid == nil {
+		return XID{}, false
+	}
+	return *tx.xid, true
+}
+
+// Prepare prepares the global transaction for commit, the first phase
+// of the two-phase commit, to be completed by CommitPrepared or Rollback.
+//
+// Prepare returns readOnly true if the branch has not changed anything:
+// then it is complete, and Tx is closed.
+func (tx *Tx) Prepare() (readOnly bool, err error) {
+	tx.log(tx.logCfg().Tx.Commit)
+	if err = tx.checkGlobal(); err != nil {
+		return false, err
+	}
+	tx.RLock()
+	ses := tx.ses
+	env := ses.Env()
+	r := C.OCITransPrepare(
+		ses.ocisvcctx, //OCISvcCtx    *svchp,
+		env.ocierr,    //OCIError     *errhp,
+		C.OCI_DEFAULT) //ub4          flags );
+	tx.RUnlock()
+	switch r {
+	case C.OCI_ERROR:
+		return false, errE(env.ociError())
+	case C.OCI_SUCCESS_WITH_INFO:
+		// ORA-24767: transaction branch prepare returns read-only
+		if cd, ok := env.ociError().(interface {
+			Code() int
+		}); ok && cd.Code() == 24767 {
+			tx.closeWithRemove()
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// CommitPrepared commits the prepared global transaction,
+// the second phase of the two-phase commit.
+func (tx *Tx) CommitPrepared() error {
+	tx.log(tx.logCfg().Tx.Commit)
+	if err := tx.checkGlobal(); err != nil {
+		return err
+	}
+	return tx.commit(C.OCI_TRANS_TWOPHASE)
+}
+
+// Forget makes the server forget the heuristically completed global transaction.
+func (tx *Tx) Forget() error {
+	tx.log(tx.logCfg().Tx.Rollback)
+	if err := tx.checkGlobal(); err != nil {
+		return err
+	}
+	defer tx.closeWithRemove()
+	tx.RLock()
+	ses := tx.ses
+	env := ses.Env()
+	r := C.OCITransForget(
+		ses.ocisvcctx, //OCISvcCtx    *svchp,
+		env.ocierr,    //OCIError     *errhp,
+		C.OCI_DEFAULT) //ub4          flags );
+	tx.RUnlock()
+	if r == C.OCI_ERROR {
+		return errE(env.ociError())
+	}
+	return nil
+}
+
+// Detach detaches the global transaction from the session, and closes the Tx.
+// The branch may be resumed with Ses.ResumeTx on any session, before the
+// TxTimeout of StartTx passes.
+func (tx *Tx) Detach() error {
+	tx.log(tx.logCfg().Tx.Commit)
+	if err := tx.checkGlobal(); err != nil {
+		return err
+	}
+	defer tx.closeWithRemove()
+	tx.RLock()
+	ses := tx.ses
+	env := ses.Env()
+	r := C.OCITransDetach(
+		ses.ocisvcctx, //OCISvcCtx    *svchp,
+		env.ocierr,    //OCIError     *errhp,
+		C.OCI_DEFAULT) //ub4          flags );
+	tx.RUnlock()
+	if r == C.OCI_ERROR {
+		return errE(env.ociError())
+	}
+	return nil
+}
+
+// checkGlobal validates that the Tx is an open global transaction.
+func (tx *Tx) checkGlobal() error {
+	if err := tx.checkIsOpen(); err != nil {
+		return err
+	}
+	tx.RLock()
+	global := tx.xid != nil
+	tx.RUnlock()
+	if !global {
+		return er("Tx is not a global transaction.")
+	}
+	return nil
+}
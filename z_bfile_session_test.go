@@ -6,6 +6,8 @@ package ora_test
 
 import (
 	"fmt"
+	"io/ioutil"
+	"strings"
 	"testing"
 
 	"gopkg.in/rana/ora.v4"
@@ -33,3 +35,79 @@ func TestBindDefine_bfile(t *testing.T) {
 		})
 	}
 }
+
+func TestBfileReader(t *testing.T) {
+	testSes := getSes(t)
+	defer testSes.Close()
+
+	// write a file with UTL_FILE, to read it back as a BFILE
+	const dir, fn = "TEMP_DIR", "ora_bfile_test.txt"
+	want := strings.Repeat("0123456789abcdef", 1<<10)
+	if _, err := testSes.PrepAndExe(`DECLARE
+  v_fh UTL_FILE.file_type;
+BEGIN
+  v_fh := UTL_FILE.fopen(:1, :2, 'wb', 32767);
+  UTL_FILE.put_raw(v_fh, UTL_RAW.cast_to_raw(:3), TRUE);
+  UTL_FILE.fclose(v_fh);
+END;`, dir, fn, want); err != nil {
+		t.Skipf("cannot write %s/%s: %v", dir, fn, err)
+	}
+	defer testSes.PrepAndExe("BEGIN UTL_FILE.fremove(:1, :2); END;", dir, fn)
+
+	// fetched with L
+	stmt, err := testSes.Prep("SELECT BFILENAME(:1, :2), BFILENAME(:1, 'not-exists') FROM DUAL", ora.L, ora.L)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stmt.Close()
+	rset, err := stmt.Qry(dir, fn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !rset.Next() {
+		t.Fatal("no rows", rset.Err())
+	}
+	br := rset.Row[0].(*ora.BfileReader)
+	defer br.Close()
+	missing := rset.Row[1].(*ora.BfileReader)
+	defer missing.Close()
+	if br.DirectoryAlias != dir || br.Filename != fn {
+		t.Errorf("got %s/%s, wanted %s/%s", br.DirectoryAlias, br.Filename, dir, fn)
+	}
+	if ok, err := missing.Exists(); err != nil {
+		t.Fatal(err)
+	} else if ok {
+		t.Errorf("%s exists", missing.Filename)
+	}
+	if ok, err := br.Exists(); err != nil {
+		t.Fatal(err)
+	} else if !ok {
+		t.Fatalf("%s does not exist", br.Filename)
+	}
+	if size, err := br.Size(); err != nil {
+		t.Fatal(err)
+	} else if size != int64(len(want)) {
+		t.Errorf("size: got %d, wanted %d", size, len(want))
+	}
+	got, err := ioutil.ReadAll(br)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != want {
+		t.Errorf("got %q, wanted %q", got, want)
+	}
+
+	// opened by name
+	br2, err := testSes.OpenBfile(ora.Bfile{DirectoryAlias: dir, Filename: fn})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer br2.Close()
+	p := make([]byte, 10)
+	if _, err = br2.ReadAt(p, 16*3+5); err != nil {
+		t.Fatal(err)
+	}
+	if string(p) != want[16*3+5:16*3+15] {
+		t.Errorf("ReadAt: got %q, wanted %q", p, want[16*3+5:16*3+15])
+	}
+}
@@ -0,0 +1,214 @@
+// +build go1.10
+
+// Copyright 2014 Rana Ian. All rights reserved.
+// Use of this source code is governed by The MIT License
+// found in the accompanying LICENSE file.
+
+package ora_test
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"gopkg.in/rana/ora.v4"
+)
+
+// countLgr is a Logger counting its messages.
+type countLgr struct {
+	mu sync.Mutex
+	n  int
+}
+
+func (l *countLgr) Infof(format string, args ...interface{})  { l.inc() }
+func (l *countLgr) Infoln(args ...interface{})                { l.inc() }
+func (l *countLgr) Errorf(format string, args ...interface{}) { l.inc() }
+func (l *countLgr) Errorln(args ...interface{})               { l.inc() }
+func (l *countLgr) inc()                                      { l.mu.Lock(); l.n++; l.mu.Unlock() }
+func (l *countLgr) count() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.n
+}
+
+func TestConnector(t *testing.T) {
+	t.Parallel()
+	var lgrs [2]countLgr
+	var dbs [2]*sql.DB
+	for i := range dbs {
+		cfg := ora.NewDrvCfg().SetPrefetchRowCount(uint32(10 * (i + 1)))
+		module := fmt.Sprintf("connector%d", i)
+		c := ora.NewConnector(ora.SrvCfg{Dblink: testSrvCfg.Dblink}, testSesCfg,
+			ora.WithDrvCfg(cfg), ora.WithLogger(&lgrs[i]),
+			ora.WithSesInit(func(ses *ora.Ses) error {
+				_, err := ses.PrepAndExe("BEGIN DBMS_APPLICATION_INFO.SET_MODULE(:1, NULL); END;", module)
+				return err
+			}))
+		dbs[i] = sql.OpenDB(c)
+		defer c.(*ora.Connector).Close()
+		defer dbs[i].Close()
+	}
+
+	for i, db := range dbs {
+		var module string
+		if err := db.QueryRow("SELECT SYS_CONTEXT('USERENV', 'MODULE') FROM DUAL").Scan(&module); err != nil {
+			t.Fatal(i, err)
+		}
+		if want := fmt.Sprintf("connector%d", i); module != want {
+			t.Errorf("%d. module: got %q, wanted %q", i, module, want)
+		}
+	}
+	for i := range lgrs {
+		if lgrs[i].count() == 0 {
+			t.Errorf("%d. the logger of the connector is not used", i)
+		}
+	}
+	if _, ok := ora.Cfg().Log.Logger.(*countLgr); ok {
+		t.Errorf("the driver's logger is changed")
+	}
+
+	// DriverContext
+	c, err := (&ora.Drv{}).OpenConnector(testConStr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	db := sql.OpenDB(c)
+	defer db.Close()
+	if err = db.Ping(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = (&ora.Drv{}).OpenConnector("oracle://u:p@h/s?unknown=1"); err == nil {
+		t.Errorf("wanted error for a bad DSN")
+	}
+}
+
+func TestConResetSession(t *testing.T) {
+	t.Parallel()
+	var ses *ora.Ses
+	var resets int
+	c := ora.NewConnector(ora.SrvCfg{Dblink: testSrvCfg.Dblink}, testSesCfg,
+		ora.WithSesInit(func(s *ora.Ses) error {
+			ses = s
+			// leak a statement and a transaction
+			if _, err := s.Prep("SELECT 1 FROM DUAL"); err != nil {
+				return err
+			}
+			_, err := s.StartTx()
+			return err
+		}),
+		ora.WithSesReset(func(s *ora.Ses) error {
+			resets++
+			return nil
+		}))
+	defer c.(*ora.Connector).Close()
+	db := sql.OpenDB(c)
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+
+	stmt, err := db.Prepare("SELECT 2 FROM DUAL")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stmt.Close()
+	if ses.NumStmt() != 2 || ses.NumTx() != 1 {
+		t.Fatalf("got %d stmts and %d txs, wanted 2 and 1", ses.NumStmt(), ses.NumTx())
+	}
+
+	// the reuse of the connection resets the session
+	var n int
+	if err = stmt.QueryRow().Scan(&n); err != nil {
+		t.Fatal(err)
+	}
+	if ses.NumStmt() != 1 || ses.NumTx() != 0 {
+		t.Errorf("got %d stmts and %d txs, wanted 1 (prepared by database/sql) and 0", ses.NumStmt(), ses.NumTx())
+	}
+	if resets == 0 {
+		t.Errorf("the session reset hook is not called")
+	}
+	if n != 2 {
+		t.Errorf("got %d, wanted 2", n)
+	}
+}
+
+func TestRouterReadOnlyTx(t *testing.T) {
+	t.Parallel()
+	env, err := ora.OpenEnv()
+	testErr(err, t)
+	defer env.Close()
+	primary := env.NewPool(testSrvCfg, testSesCfg, 2)
+	defer primary.Close()
+	replica := env.NewPool(testSrvCfg, testSesCfg, 2)
+	defer replica.Close()
+	// the test database is no standby: no staleness guard
+	router := ora.NewRouter(primary, []*ora.Pool{replica}, ora.RouterCfg{CheckInterval: 100 * time.Millisecond})
+	defer router.Close()
+	for i := 0; i < 50 && !router.Replicas()[0].Healthy; i++ {
+		time.Sleep(100 * time.Millisecond)
+	}
+	if st := router.Replicas()[0]; !st.Healthy {
+		t.Fatalf("got %+v, wanted a healthy replica", st)
+	}
+
+	db := sql.OpenDB(router)
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+	const qry = "SELECT SYS_CONTEXT('USERENV', 'SID') FROM DUAL"
+	var primarySID, replicaSID string
+	stmt, err := db.Prepare(qry)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stmt.Close()
+	if err = stmt.QueryRow().Scan(&primarySID); err != nil {
+		t.Fatal(err)
+	}
+	tx, err := db.BeginTx(context.Background(), &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the statement of the primary session must not run outside the transaction
+	var sid string
+	if err = tx.Stmt(stmt).QueryRow().Scan(&sid); err == nil {
+		t.Errorf("the statement of the primary ran in the read-only transaction (SID %s)", sid)
+	}
+	if err = tx.QueryRow(qry).Scan(&replicaSID); err != nil {
+		t.Fatal(err)
+	}
+	if err = tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	if replicaSID == primarySID {
+		t.Errorf("the read-only transaction is not routed to the replica (SID %s)", replicaSID)
+	}
+
+	// back to the primary after the transaction
+	if err = stmt.QueryRow().Scan(&sid); err != nil {
+		t.Fatal(err)
+	}
+	if sid != primarySID {
+		t.Errorf("got SID %s of the statement, wanted the primary %s", sid, primarySID)
+	}
+	if err = db.QueryRow(qry).Scan(&sid); err != nil {
+		t.Fatal(err)
+	}
+	if sid != primarySID {
+		t.Errorf("got SID %s, wanted the primary %s", sid, primarySID)
+	}
+
+	// the native read-only work
+	ses, err := router.GetContext(ora.WithReadOnly(context.Background()))
+	testErr(err, t)
+	defer ses.Close()
+	rset, err := ses.PrepAndQry(qry)
+	testErr(err, t)
+	for rset.Next() {
+		sid = rset.Row[0].(string)
+	}
+	testErr(rset.Err(), t)
+	if sid == primarySID {
+		t.Errorf("the read-only session is not of the replica (SID %s)", sid)
+	}
+}
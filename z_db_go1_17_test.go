@@ -0,0 +1,46 @@
+// +build go1.17
+
+// Copyright 2014 Rana Ian. All rights reserved.
+// Use of this source code is governed by The MIT License
+// found in the accompanying LICENSE file.
+
+package ora_test
+
+import (
+	"context"
+	"testing"
+
+	"gopkg.in/rana/ora.v4"
+)
+
+func TestConnRawInTx(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	conn, err := testDb.Conn(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx.Rollback()
+
+	// the InTx in the database/sql transaction nests with a savepoint
+	err = conn.Raw(func(driverConn interface{}) error {
+		ses := driverConn.(*ora.Con).Ses()
+		return ses.InTx(ctx, func(inTx *ora.Tx) error {
+			if ses.NumTx() != 1 {
+				t.Errorf("got %d transactions, wanted 1", ses.NumTx())
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -17,6 +17,7 @@ import (
 	"golang.org/x/sync/errgroup"
 
 	"github.com/pkg/errors"
+	"gopkg.in/rana/ora.v4"
 )
 
 func TestNamedArgs(t *testing.T) {
@@ -48,7 +49,7 @@ func TestRapidCancelIssue192(t *testing.T) {
 		w = atomic.LoadUint64(&wait)
 		if err != nil {
 			t.Log(w, err)
-			if err == context.DeadlineExceeded && !strings.Contains(err.Error(), "ORA-01013") {
+			if err == context.DeadlineExceeded && !strings.Contains(err.Error(), "ORA-01013") && !ora.IsTimeout(err) {
 				atomic.StoreUint64(&wait, w+1)
 			}
 			return err
@@ -58,7 +59,7 @@ func TestRapidCancelIssue192(t *testing.T) {
 
 	breakStuff := func(ctx context.Context, db *sql.DB) error {
 		for ctx.Err() == nil {
-			if err := dbQuery(db); err != nil && err != context.DeadlineExceeded && !strings.Contains(err.Error(), "ORA-01013") {
+			if err := dbQuery(db); err != nil && !ora.IsTimeout(err) && !strings.Contains(err.Error(), "ORA-01013") {
 				return err
 			}
 			time.Sleep(100 * time.Millisecond)
@@ -77,3 +78,172 @@ func TestRapidCancelIssue192(t *testing.T) {
 		t.Error(err)
 	}
 }
+
+func TestClientInfo(t *testing.T) {
+	t.Parallel()
+	ctx := ora.WithClientInfo(context.Background(), ora.ClientInfo{
+		ClientIdentifier: "test_user", ClientInfo: "info", Module: "test_module", Action: "test_action"})
+	conn, err := testDb.Conn(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	var id, info, module, action string
+	if err = conn.QueryRowContext(ctx, `SELECT SYS_CONTEXT('USERENV', 'CLIENT_IDENTIFIER'), SYS_CONTEXT('USERENV', 'CLIENT_INFO'),
+		SYS_CONTEXT('USERENV', 'MODULE'), SYS_CONTEXT('USERENV', 'ACTION') FROM DUAL`,
+	).Scan(&id, &info, &module, &action); err != nil {
+		t.Fatal(err)
+	}
+	if id != "test_user" || info != "info" || module != "test_module" || action != "test_action" {
+		t.Errorf("got %q, %q, %q, %q", id, info, module, action)
+	}
+}
+
+func TestPoolClientInfoReset(t *testing.T) {
+	t.Parallel()
+	env, err := ora.OpenEnv()
+	testErr(err, t)
+	defer env.Close()
+	sesCfg := testSesCfg
+	sesCfg.ClientInfo = ora.ClientInfo{ClientIdentifier: "default_user", Module: "default_module"}
+	pool := env.NewPool(testSrvCfg, sesCfg, 1)
+	defer pool.Close()
+
+	const qry = `SELECT SYS_CONTEXT('USERENV', 'CLIENT_IDENTIFIER'), SYS_CONTEXT('USERENV', 'CLIENT_INFO'),
+		SYS_CONTEXT('USERENV', 'MODULE'), SYS_CONTEXT('USERENV', 'ACTION') FROM DUAL`
+	query := func(ctx context.Context, ses *ora.Ses) []string {
+		stmt, err := ses.Prep(qry)
+		testErr(err, t)
+		defer stmt.Close()
+		rset, err := stmt.QryContext(ctx)
+		testErr(err, t)
+		var got []string
+		for rset.Next() {
+			for _, v := range rset.Row {
+				s, _ := v.(string)
+				got = append(got, s)
+			}
+		}
+		testErr(rset.Err(), t)
+		return got
+	}
+
+	ses, err := pool.Get()
+	testErr(err, t)
+	ctx := ora.WithClientInfo(context.Background(), ora.ClientInfo{
+		ClientIdentifier: "test_user", ClientInfo: "info", Module: "test_module", Action: "test_action"})
+	if got := query(ctx, ses); strings.Join(got, ",") != "test_user,info,test_module,test_action" {
+		t.Errorf("got %q with the ClientInfo of the context", got)
+	}
+	pool.Put(ses)
+
+	ses, err = pool.Get()
+	testErr(err, t)
+	defer ses.Close()
+	if got := query(context.Background(), ses); strings.Join(got, ",") != "default_user,,default_module," {
+		t.Errorf("got %q after Put, wanted the SesCfg defaults", got)
+	}
+}
+
+func TestStmtTimeout(t *testing.T) {
+	t.Parallel()
+	ses := getSes(t)
+	defer ses.Close()
+
+	// the fetch of the count is slow
+	stmt, err := ses.Prep("SELECT COUNT(*) FROM all_objects A, all_objects B, all_objects C")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stmt.Close()
+	stmt.SetCfg(stmt.Cfg().SetTimeout(200 * time.Millisecond))
+	start := time.Now()
+	rset, err := stmt.Qry()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rset.Next() {
+		t.Fatalf("got %v, wanted timeout", rset.Row)
+	}
+	if !ora.IsTimeout(rset.Err()) {
+		t.Errorf("got %v, wanted timeout", rset.Err())
+	}
+	if d := time.Since(start); d > 10*time.Second {
+		t.Errorf("timeout took %s", d)
+	}
+
+	// the session is usable after the timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	if _, err = ses.PrepAndExe("SELECT 1 FROM DUAL"); err != nil {
+		t.Fatal(err)
+	}
+	loop, err := ses.Prep("BEGIN LOOP NULL; END LOOP; END;")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer loop.Close()
+	if _, err = loop.ExeContext(ctx); !ora.IsTimeout(err) {
+		t.Errorf("got %v, wanted timeout", err)
+	}
+
+	// cancellation is not a timeout
+	ctx, cancel = context.WithCancel(context.Background())
+	time.AfterFunc(200*time.Millisecond, cancel)
+	if _, err = loop.ExeContext(ctx); err != context.Canceled {
+		t.Errorf("got %v, wanted %v", err, context.Canceled)
+	}
+}
+
+func TestQueryContextFetch(t *testing.T) {
+	t.Parallel()
+	// each row is slow to fetch
+	qry := "SELECT (SELECT COUNT(*) FROM all_objects A, all_objects B WHERE A.object_id = B.object_id + LEVEL) FROM DUAL CONNECT BY LEVEL <= 1000"
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	start := time.Now()
+	rows, err := testDb.QueryContext(ctx, qry)
+	if err != nil {
+		if ora.IsTimeout(err) {
+			t.Skip(err)
+		}
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+	}
+	if err = rows.Err(); err == nil {
+		t.Errorf("wanted timeout error")
+	}
+	t.Log(err)
+	if d := time.Since(start); d > 10*time.Second {
+		t.Errorf("the fetch stopped after %s", d)
+	}
+
+	// LOB reads observe the context
+	ses := getSes(t)
+	defer ses.Close()
+	stmt, err := ses.Prep("SELECT TO_CLOB(RPAD('x', 4000, 'x')) FROM DUAL", ora.L)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stmt.Close()
+	rset, err := stmt.QryContext(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !rset.Next() {
+		t.Fatal(rset.Err())
+	}
+	lob := rset.Row[0].(*ora.Lob)
+	defer lob.Close()
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	var p [100]byte
+	if _, err = lob.ReadContext(canceled, p[:]); err != context.Canceled {
+		t.Errorf("got %v, wanted %v", err, context.Canceled)
+	}
+	if n, err := lob.ReadContext(context.Background(), p[:]); err != nil || n == 0 {
+		t.Errorf("got %d, %v", n, err)
+	}
+}
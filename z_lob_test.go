@@ -18,6 +18,7 @@ import (
 	"sync"
 	"testing"
 	"time"
+	"unicode/utf8"
 
 	ora "gopkg.in/rana/ora.v4"
 )
@@ -288,6 +289,339 @@ END test_get_json;`,
 	}
 }
 
+func TestLobReadWriter(t *testing.T) {
+	tbl := tableName()
+	testDb.Exec("DROP TABLE " + tbl)
+	qry := "CREATE TABLE " + tbl + " (id NUMBER(3), b BLOB, c CLOB)"
+	if _, err := testDb.Exec(qry); err != nil {
+		t.Fatalf("%s: %v", qry, err)
+	}
+	defer testDb.Exec("DROP TABLE " + tbl)
+
+	testSes := getSes(t)
+	defer testSes.Close()
+	tx, err := testSes.StartTx()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx.Rollback()
+
+	// temporary LOBs
+	tb, err := testSes.NewTempLob(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tb.Close()
+	tc, err := testSes.NewTempLob(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tc.Close()
+	wantB := bytes.Repeat([]byte{0, 1, 2, 3}, 1<<19)
+	wantC := strings.Repeat("árvíztűrő tükörfúrógép\U0001F600", 1<<14)
+	for _, tup := range []struct {
+		lrw  *ora.LobReadWriter
+		data []byte
+	}{{tb, wantB}, {tc, []byte(wantC)}} {
+		w, err := tup.lrw.NewWriter(0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// odd sized writes, to split runes
+		for p := tup.data; len(p) > 0; {
+			n := 12345
+			if n > len(p) {
+				n = len(p)
+			}
+			if _, err = w.Write(p[:n]); err != nil {
+				t.Fatal(err)
+			}
+			p = p[n:]
+		}
+		if err = w.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err = testSes.PrepAndExe("INSERT INTO "+tbl+" (id, b, c) VALUES (1, :1, :2)", tb, tc); err != nil {
+		t.Fatal(err)
+	}
+
+	// persistent LOBs
+	stmt, err := testSes.Prep("SELECT b, c FROM "+tbl+" WHERE id = 1 FOR UPDATE", ora.L, ora.L)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stmt.Close()
+	rset, err := stmt.Qry()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !rset.Next() {
+		t.Fatal("no rows", rset.Err())
+	}
+	b, err := rset.Row[0].(*ora.Lob).ReadWriter()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer b.Close()
+	c, err := rset.Row[1].(*ora.Lob).ReadWriter()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	if !c.IsClob() {
+		t.Errorf("c is not a CLOB")
+	}
+
+	if size, err := b.Size(); err != nil {
+		t.Fatal(err)
+	} else if size != int64(len(wantB)) {
+		t.Errorf("BLOB size: got %d, wanted %d", size, len(wantB))
+	}
+	if size, err := c.Size(); err != nil {
+		t.Fatal(err)
+	} else if want := int64(len([]rune(wantC))); size != want {
+		// Oracle counts the supplementary characters as two UCS-2 characters.
+		t.Logf("CLOB size: got %d, wanted %d", size, want)
+	}
+
+	p := make([]byte, 8)
+	if _, err = b.ReadAt(p, 4); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(p, wantB[4:12]) {
+		t.Errorf("ReadAt: got %v, wanted %v", p, wantB[4:12])
+	}
+	if _, err = b.WriteAt([]byte{9, 9}, 1); err != nil {
+		t.Fatal(err)
+	}
+	if err = b.Truncate(3); err != nil {
+		t.Fatal(err)
+	}
+	if err = b.Truncate(5); err != nil {
+		t.Fatal(err)
+	}
+	p = p[:6]
+	n, err := b.ReadAt(p, 0)
+	if err != io.EOF {
+		t.Errorf("ReadAt over the end: got %v, wanted EOF", err)
+	}
+	if want := []byte{0, 9, 9, 0, 0}; !bytes.Equal(p[:n], want) {
+		t.Errorf("got %v, wanted %v", p[:n], want)
+	}
+
+	if err = c.Trim(0); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = c.WriteAt([]byte("árvíz"), 0); err != nil {
+		t.Fatal(err)
+	}
+	p = p[:7]
+	if n, err = c.ReadAt(p, 0); err != nil && err != io.EOF {
+		t.Fatal(err)
+	}
+	if string(p[:n]) != "árvíz" {
+		t.Errorf("CLOB: got %q, wanted %q", p[:n], "árvíz")
+	}
+}
+
+func TestLobReaderAtSeek(t *testing.T) {
+	tbl := tableName()
+	testDb.Exec("DROP TABLE " + tbl)
+	qry := "CREATE TABLE " + tbl + " (b BLOB, c CLOB)"
+	if _, err := testDb.Exec(qry); err != nil {
+		t.Fatalf("%s: %v", qry, err)
+	}
+	defer testDb.Exec("DROP TABLE " + tbl)
+
+	wantB := make([]byte, 3<<20)
+	for i := range wantB {
+		wantB[i] = byte(i % 251)
+	}
+	wantC := strings.Repeat("0123456789áéíóöőúüű", 100000) // more than lobChunkSize bytes
+	if _, err := testDb.Exec("INSERT INTO "+tbl+" (b, c) VALUES (:1, :2)",
+		wantB, wantC,
+	); err != nil {
+		t.Fatal(err)
+	}
+
+	testSes := getSes(t)
+	defer testSes.Close()
+	stmt, err := testSes.Prep("SELECT b, c FROM "+tbl, ora.L, ora.L)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stmt.Close()
+	rset, err := stmt.Qry()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !rset.Next() {
+		t.Fatal("no rows", rset.Err())
+	}
+	b := rset.Row[0].(*ora.Lob)
+	defer b.Close()
+
+	// read the end, then the middle, as a zip reader would
+	size, err := b.Seek(0, io.SeekEnd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if size != int64(len(wantB)) {
+		t.Errorf("size: got %d, wanted %d", size, len(wantB))
+	}
+	p := make([]byte, 100)
+	if _, err = b.ReadAt(p, size-int64(len(p))); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(p, wantB[len(wantB)-len(p):]) {
+		t.Errorf("ReadAt end: got %v", p)
+	}
+	if _, err = b.Seek(1<<20+3, io.SeekStart); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = io.ReadFull(b, p); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(p, wantB[1<<20+3:1<<20+3+len(p)]) {
+		t.Errorf("Seek+Read: got %v", p)
+	}
+	got, err := ioutil.ReadAll(io.NewSectionReader(b, 10, 1<<20))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, wantB[10:10+1<<20]) {
+		t.Errorf("SectionReader: got %d bytes", len(got))
+	}
+
+	b.Close() // must be closed before reading the other LOB
+	c := rset.Row[1].(*ora.Lob)
+	defer c.Close()
+	// offsets are in bytes of the UTF-8 text for CLOBs, too
+	size, err = c.Seek(0, io.SeekEnd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if size != int64(len(wantC)) {
+		t.Errorf("CLOB size: got %d, wanted %d", size, len(wantC))
+	}
+	off := len(wantC) - 10*28 + 11 // in the middle of an "á"
+	p = make([]byte, 100)
+	if _, err = c.ReadAt(p, int64(off)); err != nil {
+		t.Fatal(err)
+	}
+	if want := wantC[off : off+len(p)]; string(p) != want {
+		t.Errorf("CLOB ReadAt: got %q, wanted %q", p, want)
+	}
+	if _, err = c.ReadAt(p, 13); err != nil {
+		t.Fatal(err)
+	}
+	if want := wantC[13 : 13+len(p)]; string(p) != want {
+		t.Errorf("CLOB ReadAt: got %q, wanted %q", p, want)
+	}
+	if _, err = c.Seek(int64(off), io.SeekStart); err != nil {
+		t.Fatal(err)
+	}
+	got, err = ioutil.ReadAll(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := wantC[off:]; string(got) != want {
+		t.Errorf("CLOB Seek+Read: got %q, wanted %q", got, want)
+	}
+	got, err = ioutil.ReadAll(io.NewSectionReader(c, 20, 5000))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := wantC[20 : 20+5000]; string(got) != want {
+		t.Errorf("CLOB SectionReader: got %d bytes", len(got))
+	}
+}
+
+func TestLobReadSupplementaryChars(t *testing.T) {
+	tbl := tableName()
+	testDb.Exec("DROP TABLE " + tbl)
+	qry := "CREATE TABLE " + tbl + " (c CLOB, nc NCLOB)"
+	if _, err := testDb.Exec(qry); err != nil {
+		t.Fatalf("%s: %v", qry, err)
+	}
+	defer testDb.Exec("DROP TABLE " + tbl)
+
+	// 'a' and a 4-byte character, in different alignments to the read buffer,
+	// crossing the lobChunkSize boundary.
+	const piece = "a\U0001F600é\U0001F4A9日"
+	const n = 100000
+	qry = `DECLARE
+  v_c CLOB;
+  v_nc NCLOB;
+  v_piece VARCHAR2(100) := 'a'||UNISTR('\D83D\DE00\00E9\D83D\DCA9\65E5');
+BEGIN
+  DBMS_LOB.createtemporary(v_c, TRUE);
+  DBMS_LOB.createtemporary(v_nc, TRUE);
+  FOR i IN 1..:1 LOOP
+    DBMS_LOB.writeappend(v_c, LENGTH(v_piece), v_piece);
+    DBMS_LOB.writeappend(v_nc, LENGTH(v_piece), TO_NCHAR(v_piece));
+  END LOOP;
+  INSERT INTO ` + tbl + ` (c, nc) VALUES (v_c, v_nc);
+END;`
+	if _, err := testDb.Exec(qry, n); err != nil {
+		t.Fatalf("%s: %v", qry, err)
+	}
+	want := strings.Repeat(piece, n)
+
+	testSes := getSes(t)
+	defer testSes.Close()
+	for _, gct := range []ora.GoColumnType{ora.S, ora.L} {
+		for _, col := range []string{"c", "nc"} {
+			stmt, err := testSes.Prep("SELECT "+col+" FROM "+tbl, gct)
+			if err != nil {
+				t.Fatal(err)
+			}
+			rset, err := stmt.Qry()
+			if err != nil {
+				stmt.Close()
+				t.Fatal(err)
+			}
+			if !rset.Next() {
+				stmt.Close()
+				t.Fatal("no rows", rset.Err())
+			}
+			var got string
+			switch x := rset.Row[0].(type) {
+			case string:
+				got = x
+			case *ora.Lob:
+				// small, odd sized reads, first shorter than a character
+				var buf bytes.Buffer
+				p := make([]byte, 7)
+				for i := 0; ; i++ {
+					size := len(p)
+					if i < 100 {
+						size = 1 + i%3
+					}
+					k, err := x.Read(p[:size])
+					buf.Write(p[:k])
+					if err == io.EOF {
+						break
+					}
+					if err != nil {
+						t.Fatalf("%s/%v: %v", col, gct, err)
+					}
+				}
+				x.Close()
+				got = buf.String()
+			}
+			stmt.Close()
+			if !utf8.ValidString(got) {
+				t.Errorf("%s/%v: invalid UTF-8", col, gct)
+			}
+			if got != want {
+				t.Errorf("%s/%v: got %d bytes, wanted %d.", col, gct, len(got), len(want))
+			}
+		}
+	}
+}
+
 func TestLobIssue156(t *testing.T) {
 	tbl := tableName()
 	qry := `CREATE TABLE ` + tbl + `
@@ -5,6 +5,8 @@
 package ora_test
 
 import (
+	"context"
+	"fmt"
 	"math/rand"
 	"sync"
 	"testing"
@@ -151,3 +153,224 @@ func TestPool(t *testing.T) {
 	pool.Close()
 	T("Pool close", p2, s2)
 }
+
+func TestBoundedPool(t *testing.T) {
+	t.Parallel()
+	env, err := ora.OpenEnv()
+	testErr(err, t)
+	defer env.Close()
+	const maxActive = 3
+	pool, err := env.NewBoundedPool(testSrvCfg, testSesCfg, ora.BoundedPoolCfg{MaxActive: maxActive, MinIdle: 1})
+	testErr(err, t)
+	defer pool.Close()
+	if st := pool.Stats(); st.Idle != 1 {
+		t.Errorf("prefill: got %+v, wanted 1 idle", st)
+	}
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var inUse, maxInUse int
+	for i := 0; i < 3*maxActive; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ses, err := pool.Get(context.Background())
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			mu.Lock()
+			if inUse++; inUse > maxInUse {
+				maxInUse = inUse
+			}
+			mu.Unlock()
+			if err = ses.Ping(); err != nil {
+				t.Error(err)
+			}
+			time.Sleep(time.Duration(rand.Intn(100)) * time.Millisecond)
+			mu.Lock()
+			inUse--
+			mu.Unlock()
+			ses.Close()
+		}()
+	}
+	wg.Wait()
+	st := pool.Stats()
+	t.Logf("stats: %+v", st)
+	if maxInUse > maxActive || st.Active > maxActive {
+		t.Errorf("got %d sessions in use, %d active, wanted at most %d", maxInUse, st.Active, maxActive)
+	}
+	if st.InUse != 0 {
+		t.Errorf("got %d in use, wanted 0", st.InUse)
+	}
+
+	ses, err := pool.Get(context.Background())
+	testErr(err, t)
+	defer ses.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	var sess []*ora.Ses
+	for i := 1; i < maxActive; i++ {
+		s, err := pool.Get(ctx)
+		testErr(err, t)
+		sess = append(sess, s)
+	}
+	if _, err = pool.Get(ctx); err != context.DeadlineExceeded {
+		t.Errorf("got %v, wanted %v", err, context.DeadlineExceeded)
+	}
+	for _, s := range sess {
+		s.Close()
+	}
+}
+
+func TestPoolPolicy(t *testing.T) {
+	t.Parallel()
+	env, err := ora.OpenEnv()
+	testErr(err, t)
+	defer env.Close()
+	pool := env.NewPool(testSrvCfg, testSesCfg, 2)
+	defer pool.Close()
+	pool.SetPolicy(ora.PoolPolicy{ValidateAfter: time.Nanosecond, MaxLifetime: 100 * time.Millisecond})
+
+	ses, err := pool.Get()
+	testErr(err, t)
+	ses.Close()
+	ses2, err := pool.Get()
+	testErr(err, t)
+	if ses2 != ses {
+		t.Errorf("got %p, wanted the idle %p", ses2, ses)
+	}
+	time.Sleep(200 * time.Millisecond)
+	ses2.Close() // over its lifetime: closed
+	ses3, err := pool.Get()
+	testErr(err, t)
+	defer ses3.Close()
+	if !ses3.IsOpen() {
+		t.Error("got a closed session")
+	}
+	if err = ses3.Ping(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestPoolMetrics(t *testing.T) {
+	t.Parallel()
+	env, err := ora.OpenEnv()
+	testErr(err, t)
+	defer env.Close()
+	pool := env.NewPool(testSrvCfg, testSesCfg, 2)
+	defer pool.Close()
+	ora.RegisterPool("TestPoolMetrics", pool)
+	defer ora.UnregisterPool("TestPoolMetrics")
+
+	ses, err := pool.Get()
+	testErr(err, t)
+	stmt, err := ses.Prep("SELECT 1 FROM DUAL")
+	testErr(err, t)
+	defer stmt.Close()
+	m := ora.CollectMetrics()
+	t.Logf("metrics: %+v", m)
+	if m.NumEnv < 1 || m.NumSrv < 1 || m.NumSes < 1 || m.NumStmt < 1 {
+		t.Errorf("got %+v, wanted at least one of each", m)
+	}
+	st, ok := m.Pools["TestPoolMetrics"]
+	if !ok {
+		t.Fatal("pool is not reported")
+	}
+	if st.InUse != 1 || st.Misses != 1 || st.Creations != 1 {
+		t.Errorf("got %+v, wanted 1 in use, 1 miss and 1 creation", st)
+	}
+
+	stmt.Close()
+	ses.Close()
+	ses, err = pool.Get()
+	testErr(err, t)
+	defer ses.Close()
+	if st = pool.Stats(); st.Hits != 1 || st.InUse != 1 {
+		t.Errorf("got %+v, wanted 1 hit, 1 in use", st)
+	}
+}
+
+func TestPoolRetry(t *testing.T) {
+	t.Parallel()
+	env, err := ora.OpenEnv()
+	testErr(err, t)
+	defer env.Close()
+	pool := env.NewPool(testSrvCfg, testSesCfg, 2)
+	defer pool.Close()
+	pool.SetRetryPolicy(ora.RetryPolicy{MaxAttempts: 3})
+
+	ses, err := pool.Get()
+	testErr(err, t)
+	defer ses.Close()
+	stmt, err := ses.Prep("SELECT sys_context('USERENV', 'SID') FROM DUAL", ora.S)
+	testErr(err, t)
+	defer stmt.Close()
+	sid := func() string {
+		rset, err := stmt.Qry()
+		testErr(err, t)
+		if !rset.Next() {
+			t.Fatal(rset.Err())
+		}
+		return rset.Row[0].(string)
+	}
+	oldSid := sid()
+
+	// kill the session from another one
+	other, err := testSesPool.Get()
+	testErr(err, t)
+	defer other.Close()
+	rset, err := other.PrepAndQry("SELECT serial# FROM v$session WHERE sid = :1", oldSid)
+	if err != nil {
+		t.Skip(err)
+	}
+	if !rset.Next() {
+		t.Fatal(rset.Err())
+	}
+	if _, err = other.PrepAndExe(fmt.Sprintf("ALTER SYSTEM KILL SESSION '%s,%v' IMMEDIATE", oldSid, rset.Row[0])); err != nil {
+		t.Skip(err)
+	}
+
+	// the query is retried on a new session, with the same Ses and Stmt
+	if newSid := sid(); newSid == oldSid {
+		t.Logf("got the same SID %s on the new session", newSid)
+	}
+	if err = ses.Ping(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestPoolFailover(t *testing.T) {
+	t.Parallel()
+	env, err := ora.OpenEnv()
+	testErr(err, t)
+	defer env.Close()
+	pool := env.NewPool(testSrvCfg, testSesCfg, 2)
+	defer pool.Close()
+	const down = "127.0.0.1:1/nonexistent" // no listener
+	testErr(pool.SetFailover(ora.FailoverCfg{
+		Targets: []ora.PoolTarget{{Dblink: down}, {Dblink: testSrvCfg.Dblink}},
+	}), t)
+
+	ses, err := pool.Get()
+	testErr(err, t)
+	defer ses.Close()
+	if err = ses.Ping(); err != nil {
+		t.Error(err)
+	}
+	if st := pool.Stats(); st.Target != testSrvCfg.Dblink {
+		t.Errorf("got target %q, wanted %q", st.Target, testSrvCfg.Dblink)
+	}
+	targets := pool.Targets()
+	if len(targets) != 2 || targets[0].Circuit != ora.CircuitOpen || targets[1].Circuit != ora.CircuitClosed {
+		t.Errorf("got %+v, wanted the first circuit open", targets)
+	}
+
+	// the open circuit is skipped
+	ses2, err := pool.Get()
+	testErr(err, t)
+	defer ses2.Close()
+	if targets = pool.Targets(); targets[0].Failures != 1 {
+		t.Errorf("got %d failures, wanted the first target skipped", targets[0].Failures)
+	}
+}
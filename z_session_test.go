@@ -5,11 +5,13 @@
 package ora_test
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/pkg/errors"
 	"gopkg.in/rana/ora.v4"
@@ -183,6 +185,108 @@ func TestSession_PrepCloseStmt(t *testing.T) {
 	testErr(err, t)
 }
 
+func TestSession_StmtCache(t *testing.T) {
+	t.Parallel()
+
+	env, err := ora.OpenEnv()
+	defer env.Close()
+	testErr(err, t)
+	srv, err := env.OpenSrv(testSrvCfg)
+	defer srv.Close()
+	testErr(err, t)
+	sesCfg := testSesCfg
+	sesCfg.StmtCacheSize = 10
+	ses, err := srv.OpenSes(sesCfg)
+	defer ses.Close()
+	testErr(err, t)
+
+	const qry = "select 'go' from dual"
+	for i := 0; i < 3; i++ {
+		stmt, err := ses.Prep(qry)
+		testErr(err, t)
+		rset, err := stmt.Qry()
+		testErr(err, t)
+		for rset.Next() {
+		}
+		testErr(rset.Err(), t)
+		testErr(stmt.Close(), t)
+	}
+	stats := ses.StmtCacheStats()
+	t.Logf("stmt cache: %+v", stats)
+	if stats.Size != 10 {
+		t.Errorf("size: got %d, wanted 10", stats.Size)
+	}
+	if stats.Misses != 1 || stats.Hits != 2 {
+		t.Errorf("got %d hits and %d misses, wanted 2 and 1", stats.Hits, stats.Misses)
+	}
+}
+
+func TestSession_CfgModuleTimezone(t *testing.T) {
+	t.Parallel()
+
+	env, err := ora.OpenEnv()
+	defer env.Close()
+	testErr(err, t)
+	srv, err := env.OpenSrv(testSrvCfg)
+	defer srv.Close()
+	testErr(err, t)
+	sesCfg := testSesCfg
+	sesCfg.Module = "ora_test"
+	sesCfg.Timezone = time.UTC
+	ses, err := srv.OpenSes(sesCfg)
+	defer ses.Close()
+	testErr(err, t)
+
+	tz, err := ses.Timezone()
+	testErr(err, t)
+	if tz != time.UTC {
+		t.Errorf("timezone: got %v, wanted UTC", tz)
+	}
+	rset, err := ses.PrepAndQry("SELECT SYS_CONTEXT('USERENV', 'MODULE') FROM DUAL")
+	testErr(err, t)
+	var module string
+	for rset.Next() {
+		module = rset.Row[0].(string)
+	}
+	testErr(rset.Err(), t)
+	if module != "ora_test" {
+		t.Errorf("module: got %q, wanted ora_test", module)
+	}
+}
+
+func TestSession_CfgNLS(t *testing.T) {
+	t.Parallel()
+
+	env, err := ora.OpenEnv()
+	defer env.Close()
+	testErr(err, t)
+	srv, err := env.OpenSrv(testSrvCfg)
+	defer srv.Close()
+	testErr(err, t)
+	sesCfg := testSesCfg
+	sesCfg.NLS = ora.NLS{DateFormat: "YYYY-MM-DD", NumericCharacters: ",.", TimeZone: "+02:00"}
+	sesCfg.Init = []string{"BEGIN DBMS_APPLICATION_INFO.SET_ACTION('nls_test'); END;"}
+	ses, err := srv.OpenSes(sesCfg)
+	defer ses.Close()
+	testErr(err, t)
+
+	tz, err := ses.Timezone()
+	testErr(err, t)
+	if _, off := time.Now().In(tz).Zone(); off != 7200 {
+		t.Errorf("timezone: got %v (%d), wanted +02:00", tz, off)
+	}
+	rset, err := ses.PrepAndQry(`SELECT SYS_CONTEXT('USERENV', 'NLS_DATE_FORMAT'), TO_CHAR(1.5), SESSIONTIMEZONE, SYS_CONTEXT('USERENV', 'ACTION') FROM DUAL`)
+	testErr(err, t)
+	var row []interface{}
+	for rset.Next() {
+		row = rset.Row
+	}
+	testErr(rset.Err(), t)
+	if len(row) != 4 || row[0] != "YYYY-MM-DD" || row[1] != "1,5" || row[2] != "+02:00" || row[3] != "nls_test" {
+		t.Errorf("got %q", row)
+	}
+}
+
 func TestSession_Tx_StartCommit(t *testing.T) {
 	t.Parallel()
 	ses, err := testSesPool.Get()
@@ -221,6 +325,159 @@ func TestSession_Tx_StartCommit(t *testing.T) {
 	}
 }
 
+func TestSession_Tx_TwoPhase(t *testing.T) {
+	t.Parallel()
+	ses, err := testSesPool.Get()
+	testErr(err, t)
+	defer ses.Close()
+	ses2, err := testSesPool.Get()
+	testErr(err, t)
+	defer ses2.Close()
+
+	tableName, err := createTable(1, numberP38S0, ses)
+	testErr(err, t)
+	defer dropTable(tableName, ses, t)
+
+	xid := ora.XID{
+		FormatID:            0x4f5241,
+		GlobalTransactionID: []byte(fmt.Sprintf("gtrid-%d", time.Now().UnixNano())),
+		BranchQualifier:     []byte("b1"),
+	}
+	tx, err := ses.StartTx(ora.TxXID(xid), ora.TxTimeout(30*time.Second))
+	testErr(err, t)
+	if got, ok := tx.XID(); !ok || got.String() != xid.String() {
+		t.Errorf("got XID %v, %t, wanted %v", got, ok, xid)
+	}
+	_, err = ses.PrepAndExe(fmt.Sprintf("insert into %v (c1) values (:1)", tableName), int64(9))
+	testErr(err, t)
+	// continue the branch on the other session
+	testErr(tx.Detach(), t)
+	tx, err = ses2.ResumeTx(xid)
+	testErr(err, t)
+	_, err = ses2.PrepAndExe(fmt.Sprintf("insert into %v (c1) values (:1)", tableName), int64(11))
+	testErr(err, t)
+
+	readOnly, err := tx.Prepare()
+	testErr(err, t)
+	if readOnly {
+		t.Fatalf("prepare returned read-only")
+	}
+	testErr(tx.CommitPrepared(), t)
+
+	rset, err := ses.PrepAndQry(fmt.Sprintf("select count(0) from %v", tableName))
+	testErr(err, t)
+	if !rset.Next() {
+		t.Fatal(rset.Err())
+	}
+	if n := fmt.Sprint(rset.Row[0]); n != "2" {
+		t.Errorf("row count: expected(%v), actual(%v)", 2, n)
+	}
+
+	// nothing to commit
+	xid.BranchQualifier = []byte("b2")
+	tx, err = ses.StartTx(ora.TxXID(xid))
+	testErr(err, t)
+	if readOnly, err = tx.Prepare(); err != nil || !readOnly {
+		t.Errorf("got %t, %v, wanted read-only", readOnly, err)
+	}
+
+	if _, err = ses.StartTx(ora.TxXID(ora.XID{FormatID: 1})); err == nil {
+		t.Errorf("wanted error for an empty GlobalTransactionID")
+	}
+}
+
+func TestSession_Tx_Savepoint(t *testing.T) {
+	t.Parallel()
+	ses, err := testSesPool.Get()
+	testErr(err, t)
+	defer ses.Close()
+
+	tableName, err := createTable(1, numberP38S0, ses)
+	testErr(err, t)
+	defer dropTable(tableName, ses, t)
+	insert := fmt.Sprintf("insert into %v (c1) values (:1)", tableName)
+	count := func() string {
+		rset, err := ses.PrepAndQry(fmt.Sprintf("select count(0) from %v", tableName))
+		testErr(err, t)
+		if !rset.Next() {
+			t.Fatal(rset.Err())
+		}
+		return fmt.Sprint(rset.Row[0])
+	}
+
+	tx, err := ses.StartTx()
+	testErr(err, t)
+	_, err = ses.PrepAndExe(insert, int64(1))
+	testErr(err, t)
+	testErr(tx.Savepoint("sp1"), t)
+	_, err = ses.PrepAndExe(insert, int64(2))
+	testErr(err, t)
+	testErr(tx.Savepoint("sp2"), t)
+	testErr(tx.RollbackTo("sp1"), t)
+	if n := count(); n != "1" {
+		t.Errorf("row count after rollback to sp1: expected(1), actual(%v)", n)
+	}
+	if err = tx.RollbackTo("sp2"); err == nil {
+		t.Errorf("sp2 is not released by the rollback to sp1")
+	}
+	testErr(tx.Release("sp1"), t)
+	if err = tx.RollbackTo("sp1"); err == nil {
+		t.Errorf("sp1 is not released")
+	}
+	if err = tx.Savepoint("bad name"); err == nil {
+		t.Errorf("wanted error for a bad savepoint name")
+	}
+
+	// the inner InTx rolls back to its savepoint, the outer one commits
+	ctx := context.Background()
+	testErr(tx.Rollback(), t)
+	err = ses.InTx(ctx, func(outer *ora.Tx) error {
+		if _, err := ses.PrepAndExe(insert, int64(3)); err != nil {
+			return err
+		}
+		innerErr := ses.InTx(ctx, func(inner *ora.Tx) error {
+			if inner != outer {
+				t.Errorf("the inner InTx got another Tx")
+			}
+			if _, err := ses.PrepAndExe(insert, int64(4)); err != nil {
+				return err
+			}
+			return errors.New("inner failure")
+		})
+		if innerErr == nil {
+			t.Errorf("wanted the inner error")
+		}
+		return ses.InTx(ctx, func(*ora.Tx) error {
+			_, err := ses.PrepAndExe(insert, int64(5))
+			return err
+		})
+	})
+	testErr(err, t)
+	if ses.NumTx() != 0 {
+		t.Errorf("InTx left %d open transactions", ses.NumTx())
+	}
+	if n := count(); n != "2" {
+		t.Errorf("row count after InTx: expected(2), actual(%v)", n)
+	}
+
+	// the failed top-level InTx rolls back
+	err = ses.InTx(ctx, func(*ora.Tx) error {
+		if _, err := ses.PrepAndExe(insert, int64(6)); err != nil {
+			return err
+		}
+		return errors.New("outer failure")
+	})
+	if err == nil || err.Error() != "outer failure" {
+		t.Errorf("wanted the outer error, got %v", err)
+	}
+	if ses.NumTx() != 0 {
+		t.Errorf("InTx left %d open transactions", ses.NumTx())
+	}
+	if n := count(); n != "2" {
+		t.Errorf("row count after the failed InTx: expected(2), actual(%v)", n)
+	}
+}
+
 func TestSession_Tx_StartRollback(t *testing.T) {
 	t.Parallel()
 	ses, err := testSesPool.Get()
@@ -307,6 +564,36 @@ func TestSession_PrepAndExe_Insert(t *testing.T) {
 	}
 }
 
+func TestSession_ParseError(t *testing.T) {
+	t.Parallel()
+	ses, err := testSesPool.Get()
+	testErr(err, t)
+	defer ses.Close()
+
+	_, err = ses.PrepAndQry("SELECT 1\nFORM DUAL")
+	oe, ok := ora.AsORAError(err)
+	if !ok {
+		t.Fatalf("expected ORAError, got %v", err)
+	}
+	if off, ok := oe.Offset(); !ok || off != 9 {
+		t.Errorf("expected offset 9, got %d, %t (%v)", off, ok, err)
+	}
+	if excerpt := oe.Excerpt(); excerpt != "2: FORM DUAL\n   ^" {
+		t.Errorf("got excerpt\n%s", excerpt)
+	}
+
+	_, err = ses.PrepAndExe("BEGIN\n  RAISE_APPLICATION_ERROR(-20001, 'boom');\nEND;")
+	if oe, ok = ora.AsORAError(err); !ok {
+		t.Fatalf("expected ORAError, got %v", err)
+	}
+	if _, ok := oe.Offset(); ok {
+		t.Errorf("runtime error %v has a parse error offset", err)
+	}
+	if frames := oe.Stack(); len(frames) != 1 || frames[0] != (ora.PLSQLFrame{Line: 2}) {
+		t.Errorf("got stack %v of %v", frames, err)
+	}
+}
+
 func TestSession_PrepAndQry(t *testing.T) {
 	t.Parallel()
 	ses, err := testSesPool.Get()
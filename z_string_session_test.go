@@ -251,3 +251,78 @@ func TestStringSlice(t *testing.T) {
 	t.Log(rset.Row[0])
 
 }
+
+func TestLongPiecewise(t *testing.T) {
+	tbl := tableName()
+	testDb.Exec("DROP TABLE " + tbl)
+	qry := "CREATE TABLE " + tbl + " (id NUMBER(3), l LONG)"
+	if _, err := testDb.Exec(qry); err != nil {
+		t.Fatalf("%s: %v", qry, err)
+	}
+	defer testDb.Exec("DROP TABLE " + tbl)
+
+	testSes := getSes(t)
+	defer testSes.Close()
+	// bigger than the piece size, and than the former default buffer size
+	want := strings.Repeat("0123456789abcdef", 17<<16)
+	if _, err := testSes.PrepAndExe("INSERT INTO "+tbl+" (id, l) VALUES (1, :1)", want); err != nil {
+		t.Skipf("insert %d bytes into LONG: %v", len(want), err)
+	}
+	if _, err := testSes.PrepAndExe("INSERT INTO "+tbl+" (id, l) VALUES (2, NULL)"); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, gct := range []ora.GoColumnType{ora.S, ora.OraS, ora.L} {
+		stmt, err := testSes.Prep("SELECT l FROM "+tbl+" ORDER BY id", gct)
+		if err != nil {
+			t.Fatal(err)
+		}
+		stmt.SetCfg(stmt.Cfg().SetLongBufferSize(100 << 10))
+		rset, err := stmt.Qry()
+		if err != nil {
+			stmt.Close()
+			t.Fatal(err)
+		}
+		var got []interface{}
+		for rset.Next() {
+			got = append(got, rset.Row[0])
+		}
+		stmt.Close()
+		if rset.Err() != nil {
+			t.Fatalf("%v: %v", gct, rset.Err())
+		}
+		if len(got) != 2 {
+			t.Fatalf("%v: got %d rows, wanted 2", gct, len(got))
+		}
+		var s string
+		switch x := got[0].(type) {
+		case string:
+			s = x
+		case ora.String:
+			s = x.Value
+		case *ora.Lob:
+			b, err := x.Bytes()
+			if err != nil {
+				t.Fatal(err)
+			}
+			s = string(b)
+		}
+		if s != want {
+			t.Errorf("%v: got %d bytes, wanted %d", gct, len(s), len(want))
+		}
+		switch x := got[1].(type) {
+		case string:
+			if x != "" {
+				t.Errorf("%v: NULL got %q", gct, x)
+			}
+		case ora.String:
+			if !x.IsNull {
+				t.Errorf("%v: NULL got %v", gct, x)
+			}
+		case *ora.Lob:
+			if x != nil {
+				t.Errorf("%v: NULL got %v", gct, x)
+			}
+		}
+	}
+}